@@ -14,3 +14,13 @@ func Adapt(ctx context.Context, opt options.Options) (*state.State, error) {
 	err := aws.Adapt(ctx, cloudState, opt)
 	return cloudState, err
 }
+
+// ResourceTags returns the tags of the resources in the region, keyed by ARN
+func ResourceTags(ctx context.Context, opt options.Options) (map[string]map[string]string, error) {
+	return aws.ResourceTags(ctx, opt)
+}
+
+// CheckPermissions evaluates the IAM actions required to adapt the services against the policies of the caller
+func CheckPermissions(ctx context.Context, opt options.Options) ([]aws.PermissionCheck, error) {
+	return aws.CheckPermissions(ctx, opt)
+}
@@ -1,6 +1,7 @@
 package api_gateway
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/aws/aws-sdk-go-v2/aws/arn"
@@ -31,6 +32,13 @@ func (a *adapter) Name() string {
 	return "accessanalyzer"
 }
 
+func (a *adapter) Permissions() []string {
+	return []string{
+		"access-analyzer:ListAnalyzers",
+		"access-analyzer:ListFindings",
+	}
+}
+
 func (a *adapter) Adapt(root *aws.RootAdapter, state *state.State) error {
 
 	a.RootAdapter = root
@@ -67,7 +75,7 @@ func (a *adapter) adaptAnalyzers() ([]accessanalyzer.Analyzer, error) {
 	return concurrency.Adapt(apiAnalyzers, a.RootAdapter, a.adaptAnalyzer), nil
 }
 
-func (a *adapter) adaptAnalyzer(apiAnalyzer aatypes.AnalyzerSummary) (*accessanalyzer.Analyzer, error) {
+func (a *adapter) adaptAnalyzer(ctx context.Context, apiAnalyzer aatypes.AnalyzerSummary) (*accessanalyzer.Analyzer, error) {
 
 	if apiAnalyzer.Arn == nil {
 		return nil, fmt.Errorf("missing arn")
@@ -87,7 +95,7 @@ func (a *adapter) adaptAnalyzer(apiAnalyzer aatypes.AnalyzerSummary) (*accessana
 	}
 
 	var findings []accessanalyzer.Findings
-	output, err := a.api.ListFindings(a.Context(), &api.ListFindingsInput{
+	output, err := a.api.ListFindings(ctx, &api.ListFindingsInput{
 		AnalyzerArn: apiAnalyzer.Arn,
 	})
 	if err != nil {
@@ -3,17 +3,25 @@ package aws
 import (
 	"context"
 	"fmt"
+	"maps"
 	"slices"
+	"sync"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/aws/arn"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/aquasecurity/trivy-aws/internal/adapters/cloud/options"
+	"github.com/aquasecurity/trivy-aws/pkg/audit"
 	"github.com/aquasecurity/trivy-aws/pkg/concurrency"
 	"github.com/aquasecurity/trivy-aws/pkg/errs"
+	"github.com/aquasecurity/trivy-aws/pkg/partition"
 	"github.com/aquasecurity/trivy-aws/pkg/progress"
+	"github.com/aquasecurity/trivy-aws/pkg/stats"
+	"github.com/aquasecurity/trivy-aws/pkg/tracing"
 	"github.com/aquasecurity/trivy/pkg/iac/state"
 	"github.com/aquasecurity/trivy/pkg/iac/types"
 	"github.com/aquasecurity/trivy/pkg/log"
@@ -21,6 +29,9 @@ import (
 
 var registeredAdapters []ServiceAdapter
 
+// globalServices are not bound to a region, so they only need to be adapted once per account
+var globalServices = []string{"cloudfront", "iam"}
+
 func RegisterServiceAdapter(adapter ServiceAdapter) {
 	for _, existing := range registeredAdapters {
 		if existing.Name() == adapter.Name() {
@@ -33,9 +44,17 @@ func RegisterServiceAdapter(adapter ServiceAdapter) {
 type ServiceAdapter interface {
 	Name() string
 	Provider() string
+	// Permissions returns the IAM actions of every API call the adapter makes
+	Permissions() []string
 	Adapt(root *RootAdapter, state *state.State) error
 }
 
+// ResourceAdapter is implemented by service adapters which can adapt resources identified by their ARNs
+// directly, without discovering every resource of the service first.
+type ResourceAdapter interface {
+	AdaptResources(root *RootAdapter, state *state.State, arns []string) error
+}
+
 type RootAdapter struct {
 	ctx                 context.Context
 	sessionCfg          aws.Config
@@ -43,8 +62,14 @@ type RootAdapter struct {
 	accountID           string
 	currentService      string
 	region              string
+	partition           string
 	logger              *log.Logger
 	concurrencyStrategy concurrency.Strategy
+	serviceStrategies   map[string]concurrency.Strategy
+	resourceTimeout     time.Duration
+	errors              *errs.Collector
+	notFound            *options.ResourceSet
+	stats               *stats.Collector
 }
 
 func NewRootAdapter(ctx context.Context, cfg aws.Config, tracker progress.ServiceTracker, logger *log.Logger) *RootAdapter {
@@ -53,6 +78,7 @@ func NewRootAdapter(ctx context.Context, cfg aws.Config, tracker progress.Servic
 		tracker:    tracker,
 		sessionCfg: cfg,
 		region:     cfg.Region,
+		partition:  partition.FromRegion(cfg.Region),
 		logger:     logger,
 	}
 }
@@ -61,10 +87,19 @@ func (a *RootAdapter) Region() string {
 	return a.region
 }
 
+func (a *RootAdapter) Partition() string {
+	return a.partition
+}
+
 func (a *RootAdapter) ConcurrencyStrategy() concurrency.Strategy {
 	return a.concurrencyStrategy
 }
 
+// ResourceTimeout returns how long a single resource may take to adapt, zero means no limit
+func (a *RootAdapter) ResourceTimeout() time.Duration {
+	return a.resourceTimeout
+}
+
 func (a *RootAdapter) SessionConfig() aws.Config {
 	return a.sessionCfg
 }
@@ -81,21 +116,51 @@ func (a *RootAdapter) Logger() *log.Logger {
 	return a.logger
 }
 
+// RecordError records an error which left the resource, or the whole service if no resource is given,
+// not fully checked
+func (a *RootAdapter) RecordError(resource string, err error) {
+	a.tracker.ReportError(resource, err)
+	if a.errors == nil {
+		return
+	}
+	// denied and throttled calls are recorded by the SDK middleware already
+	if class := errs.Classify(err); resource == "" && (class == errs.ClassAccessDenied || class == errs.ClassThrottling) {
+		return
+	}
+	a.errors.Add(errs.NewScanError(a.currentService, a.region, resource, err))
+}
+
+// RecordNotFound records that a requested resource was confirmed not to exist, so that it is removed from
+// the cache. Resources which could not be fetched for any other reason must be recorded with RecordError,
+// which leaves them cached.
+func (a *RootAdapter) RecordNotFound(arn string) {
+	a.logger.Debug("Resource not found", log.String("arn", arn))
+	if a.notFound != nil {
+		a.notFound.Add(arn)
+	}
+}
+
 func (a *RootAdapter) CreateMetadata(resource string) types.Metadata {
 
-	// some services don't require region/account id in the ARN
+	// some services don't require region/account id in the ARN, and some are namespaced differently
 	// see https://docs.aws.amazon.com/general/latest/gr/aws-arns-and-namespaces.html#genref-aws-service-namespaces
+	service := a.currentService
 	namespace := a.accountID
 	region := a.region
 	switch a.currentService {
 	case "s3":
 		namespace = ""
 		region = ""
+	case "api-gateway":
+		service = "apigateway"
+		namespace = ""
+	case "elasticsearch":
+		service = "es"
 	}
 
 	return a.CreateMetadataFromARN((arn.ARN{
-		Partition: "aws",
-		Service:   a.currentService,
+		Partition: a.partition,
+		Service:   service,
 		Region:    region,
 		AccountID: namespace,
 		Resource:  resource,
@@ -132,29 +197,106 @@ func AllServices() []string {
 	return services
 }
 
-func Adapt(ctx context.Context, state *state.State, opt options.Options) error {
+// ResourceServices returns the services which can adapt individual resources identified by their ARNs
+func ResourceServices() []string {
+	var services []string
+	for _, reg := range registeredAdapters {
+		if _, ok := reg.(ResourceAdapter); ok {
+			services = append(services, reg.Name())
+		}
+	}
+	return services
+}
+
+func GlobalServices() []string {
+	return slices.Clone(globalServices)
+}
+
+// basePermissions are required regardless of the scanned services, to look up the caller identity and
+// the tags of the resources
+var basePermissions = []string{"sts:GetCallerIdentity", "tag:GetResources"}
+
+// Feature is a feature of the commands which calls AWS on top of the adapters of the scanned services
+type Feature string
+
+const (
+	// FeatureAllEnabledRegions lists the regions enabled for the account, for --regions all-enabled
+	FeatureAllEnabledRegions Feature = "all-enabled-regions"
+	// FeaturePreflight simulates the policies of the caller, for the preflight command
+	FeaturePreflight Feature = "preflight"
+	// FeatureOrgAccounts lists the accounts of the organization, for --org-accounts
+	FeatureOrgAccounts Feature = "org-accounts"
+	// FeatureAssumeRole assumes the role of each scanned account, for --assume-role
+	FeatureAssumeRole Feature = "assume-role"
+	// FeatureS3Cache reads and writes the cache in a bucket, for --cache-backend s3://...
+	FeatureS3Cache Feature = "s3-cache"
+)
+
+// featurePermissions are the IAM actions required by each feature, on top of the base permissions
+var featurePermissions = map[Feature][]string{
+	FeatureAllEnabledRegions: {"ec2:DescribeRegions"},
+	FeaturePreflight:         {"iam:GetRole", "iam:SimulatePrincipalPolicy"},
+	FeatureOrgAccounts:       {"organizations:ListAccounts"},
+	FeatureAssumeRole:        {"sts:AssumeRole"},
+	FeatureS3Cache:           {"s3:DeleteObject", "s3:GetObject", "s3:PutObject"},
+}
+
+// Features returns the sorted features which require permissions of their own
+func Features() []Feature {
+	features := slices.Collect(maps.Keys(featurePermissions))
+	slices.Sort(features)
+	return features
+}
+
+// FeaturePermissions returns the sorted IAM actions required by the given features on top of the
+// permissions of the scanned services
+func FeaturePermissions(features []Feature) ([]string, error) {
+	var permissions []string
+	for _, feature := range features {
+		actions, ok := featurePermissions[feature]
+		if !ok {
+			return nil, fmt.Errorf("unknown feature %q", feature)
+		}
+		permissions = append(permissions, actions...)
+	}
+	slices.Sort(permissions)
+	return slices.Compact(permissions), nil
+}
+
+// Permissions returns the sorted IAM actions required to adapt the given services, or every registered
+// service if none are given
+func Permissions(services []string) []string {
+	permissions := slices.Clone(basePermissions)
+	for _, adapter := range registeredAdapters {
+		if len(services) != 0 && !slices.Contains(services, adapter.Name()) {
+			continue
+		}
+		permissions = append(permissions, adapter.Permissions()...)
+	}
+	slices.Sort(permissions)
+	return slices.Compact(permissions)
+}
+
+func Adapt(ctx context.Context, cloudState *state.State, opt options.Options) error {
 	c := &RootAdapter{
 		ctx:                 ctx,
-		tracker:             opt.ProgressTracker,
+		tracker:             progress.NoProgress,
 		logger:              log.WithPrefix("adapt-aws"),
 		concurrencyStrategy: opt.ConcurrencyStrategy,
+		serviceStrategies:   opt.ServiceStrategies,
+		resourceTimeout:     opt.ResourceTimeout,
+		errors:              opt.Errors,
+		notFound:            opt.NotFound,
+		stats:               opt.Stats,
 	}
 
-	cfg, err := config.LoadDefaultConfig(ctx)
+	cfg, err := loadConfig(ctx, opt, c.logger)
 	if err != nil {
 		return err
 	}
 
-	c.sessionCfg = cfg
-
-	if opt.Region != "" {
-		c.logger.Info("Using region", log.String("region", opt.Region))
-		c.sessionCfg.Region = opt.Region
-	}
-	if opt.Endpoint != "" {
-		c.logger.Info("Using endpoint", log.String("endpoint", opt.Endpoint))
-		c.sessionCfg.EndpointResolverWithOptions = createResolver(opt.Endpoint)
-	}
+	guard := &readOnlyGuard{}
+	c.sessionCfg = withTracing(withThrottling(withReadOnlyGuard(cfg, guard), opt.APIRateLimiter))
 
 	c.logger.Debug("Discovering caller identity...")
 	stsClient := sts.NewFromConfig(c.sessionCfg)
@@ -166,33 +308,82 @@ func Adapt(ctx context.Context, state *state.State, opt options.Options) error {
 		return fmt.Errorf("missing account id for aws account")
 	}
 	c.accountID = *result.Account
-	c.logger.Info("AWS account ID", log.String("ID", c.accountID))
+	c.partition = partition.FromARN(aws.ToString(result.Arn), c.sessionCfg.Region)
+	c.logger.Info("AWS account ID", log.String("ID", c.accountID), log.String("partition", c.partition))
 
 	if len(opt.Services) == 0 {
 		c.logger.Info("Preparing to run for all registered services...", log.Int("count", len(registeredAdapters)))
-		opt.ProgressTracker.SetTotalServices(len(registeredAdapters))
 	} else {
 		c.logger.Info("Preparing to run for filtered services...", log.Int("count", len(opt.Services)))
-		opt.ProgressTracker.SetTotalServices(len(opt.Services))
 	}
 
 	c.region = c.sessionCfg.Region
 
-	var adapterErrors []error
-
+	var adapters []ServiceAdapter
 	for _, adapter := range registeredAdapters {
 		if len(opt.Services) != 0 && !slices.Contains(opt.Services, adapter.Name()) {
 			continue
 		}
-		c.currentService = adapter.Name()
-		c.logger.Debug("Running adapter", log.String("service", adapter.Name()))
-		opt.ProgressTracker.StartService(adapter.Name())
+		adapters = append(adapters, adapter)
+	}
+	opt.ProgressTracker.SetTotalServices(len(adapters))
+
+	parallelism := max(opt.ServiceParallelism, 1)
+	c.logger.Debug("Running adapters", log.Int("parallelism", parallelism))
+
+	// each adapter writes into its own state, which are merged in registration order afterwards
+	// so that the resulting state doesn't depend on which adapter finished first
+	serviceStates := make([]*state.State, len(adapters))
+	serviceErrors := make([]error, len(adapters))
+
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for i, adapter := range adapters {
+		acquired := false
+		select {
+		case sem <- struct{}{}:
+			acquired = true
+		case <-ctx.Done():
+		}
+		// a slot may have been freed as the context was cancelled, in which case either may have been selected
+		if err := ctx.Err(); err != nil {
+			if acquired {
+				<-sem
+			}
+			// the services which were not started are left out of the state entirely
+			serviceStates[i], serviceErrors[i] = &state.State{}, err
+			if c.errors != nil {
+				c.errors.Add(errs.NewScanError(adapter.Name(), c.region, "", err))
+			}
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			serviceStates[i], serviceErrors[i] = c.adaptService(adapter, opt.ProgressTracker, opt.ARNs, opt.ServiceTimeout)
+		}()
+	}
+	wg.Wait()
+
+	var adapterErrors []error
 
-		if err := adapter.Adapt(c, state); err != nil {
-			c.logger.Error("Failed to adapt", log.String("service", adapter.Name()), log.Err(err))
-			adapterErrors = append(adapterErrors, fmt.Errorf("failed to adapt service %s: %w", adapter.Name(), err))
+	for i, adapter := range adapters {
+		if serviceErrors[i] != nil {
+			adapterErrors = append(adapterErrors, fmt.Errorf("failed to adapt service %s: %w", adapter.Name(), serviceErrors[i]))
 		}
-		opt.ProgressTracker.FinishService()
+		merged, err := cloudState.Merge(serviceStates[i])
+		if err != nil {
+			return fmt.Errorf("failed to merge state of service %s: %w", adapter.Name(), err)
+		}
+		*cloudState = *merged
+	}
+
+	// an attempt to change the account is a bug of an adapter, which must not go unnoticed
+	if err := guard.err(); err != nil {
+		return err
 	}
 
 	if len(adapterErrors) > 0 {
@@ -201,3 +392,78 @@ func Adapt(ctx context.Context, state *state.State, opt options.Options) error {
 
 	return nil
 }
+
+func loadConfig(ctx context.Context, opt options.Options, logger *log.Logger) (aws.Config, error) {
+	var loadOptions []func(*config.LoadOptions) error
+	if opt.Credentials != nil {
+		loadOptions = append(loadOptions, config.WithCredentialsProvider(opt.Credentials))
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, loadOptions...)
+	if err != nil {
+		return aws.Config{}, err
+	}
+
+	if opt.Region != "" {
+		logger.Info("Using region", log.String("region", opt.Region))
+		cfg.Region = opt.Region
+	}
+	if opt.Endpoint != "" {
+		logger.Info("Using endpoint", log.String("endpoint", opt.Endpoint))
+		cfg.EndpointResolverWithOptions = createResolver(opt.Endpoint)
+	}
+	cfg.APIOptions = append(cfg.APIOptions, opt.APIOptions...)
+	return audit.WithLog(cfg, opt.AuditLog), nil
+}
+
+func (a *RootAdapter) adaptService(adapter ServiceAdapter, tracker progress.Tracker, arns []string, timeout time.Duration) (*state.State, error) {
+	// every service works on its own copy of the root adapter as they may run concurrently
+	root := *a
+	root.currentService = adapter.Name()
+	if strategy, ok := a.serviceStrategies[adapter.Name()]; ok {
+		root.concurrencyStrategy = strategy
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		root.ctx, cancel = context.WithTimeout(a.ctx, timeout)
+		defer cancel()
+	}
+
+	var span trace.Span
+	root.ctx, span = tracing.Tracer().Start(root.ctx, "adapt "+adapter.Name(),
+		trace.WithAttributes(tracing.Service(adapter.Name()), tracing.Region(a.region)))
+	if a.errors != nil {
+		root.sessionCfg = withErrorRecorder(root.sessionCfg, a.errors, adapter.Name(), a.region)
+	}
+	if a.stats != nil {
+		root.sessionCfg = withStatsRecorder(root.sessionCfg, a.stats, adapter.Name())
+	}
+	root.tracker = tracker.StartService(adapter.Name())
+	defer tracker.FinishService(adapter.Name())
+
+	serviceState := &state.State{}
+
+	adapt := adapter.Adapt
+	if len(arns) > 0 {
+		if resourceAdapter, ok := adapter.(ResourceAdapter); ok {
+			root.logger.Debug("Running adapter for resources", log.String("service", adapter.Name()), log.Any("arns", arns))
+			adapt = func(root *RootAdapter, state *state.State) error {
+				return resourceAdapter.AdaptResources(root, state, arns)
+			}
+		} else {
+			root.logger.Warn("Service does not support scanning individual resources, all resources will be adapted",
+				log.String("service", adapter.Name()))
+		}
+	} else {
+		root.logger.Debug("Running adapter", log.String("service", adapter.Name()))
+	}
+
+	if err := adapt(&root, serviceState); err != nil {
+		root.logger.Error("Failed to adapt", log.String("service", adapter.Name()), log.Err(err))
+		root.RecordError("", err)
+		tracing.End(span, err)
+		return serviceState, err
+	}
+	span.End()
+	return serviceState, nil
+}
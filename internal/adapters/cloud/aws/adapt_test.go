@@ -0,0 +1,365 @@
+package aws
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/trivy-aws/internal/adapters/cloud/options"
+	"github.com/aquasecurity/trivy-aws/pkg/errs"
+	"github.com/aquasecurity/trivy-aws/pkg/progress"
+	"github.com/aquasecurity/trivy/pkg/iac/providers/aws/s3"
+	"github.com/aquasecurity/trivy/pkg/iac/state"
+	iacTypes "github.com/aquasecurity/trivy/pkg/iac/types"
+)
+
+// withAdapters replaces the registered adapters with the given ones for the duration of the test
+func withAdapters(t *testing.T, adapters ...ServiceAdapter) {
+	registered := registeredAdapters
+	registeredAdapters = slices.Clone(adapters)
+	t.Cleanup(func() {
+		registeredAdapters = registered
+	})
+}
+
+// stubAWS answers the lookup of the caller identity in place of AWS and fails every other call, recording
+// the operations which get past the read-only guard
+type stubAWS struct {
+	// callerARN is the ARN of the caller, a user of the commercial partition if not set
+	callerARN string
+
+	mu         sync.Mutex
+	operations []string
+}
+
+func (s *stubAWS) apiOption(stack *middleware.Stack) error {
+	return stack.Serialize.Add(middleware.SerializeMiddlewareFunc("StubAWS",
+		func(ctx context.Context, _ middleware.SerializeInput, _ middleware.SerializeHandler) (middleware.SerializeOutput, middleware.Metadata, error) {
+			operation := awsmiddleware.GetOperationName(ctx)
+			s.mu.Lock()
+			s.operations = append(s.operations, operation)
+			s.mu.Unlock()
+			if operation != "GetCallerIdentity" {
+				return middleware.SerializeOutput{}, middleware.Metadata{}, fmt.Errorf("unexpected call of %s", operation)
+			}
+			callerARN := s.callerARN
+			if callerARN == "" {
+				callerARN = "arn:aws:iam::123456789012:user/scanner"
+			}
+			return middleware.SerializeOutput{Result: &sts.GetCallerIdentityOutput{
+				Account: aws.String("123456789012"),
+				Arn:     aws.String(callerARN),
+			}}, middleware.Metadata{}, nil
+		}), middleware.Before)
+}
+
+func (s *stubAWS) calls() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.operations)
+}
+
+func (s *stubAWS) options() options.Options {
+	return options.Options{
+		ProgressTracker: progress.NoProgress,
+		Region:          "us-east-1",
+		Credentials:     credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+		APIOptions:      []func(*middleware.Stack) error{s.apiOption},
+	}
+}
+
+// stubAdapter adapts a single bucket named after the service, after the given delay
+type stubAdapter struct {
+	name  string
+	delay time.Duration
+	err   error
+	// adapt is called before the bucket is added, if set
+	adapt func(root *RootAdapter, state *state.State)
+	// running counts the services adapting at the same time, if set
+	running *runningCounter
+}
+
+// runningCounter tracks the highest number of services adapted at the same time
+type runningCounter struct {
+	mu      sync.Mutex
+	current int
+	max     int
+}
+
+func (c *runningCounter) enter() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.current++
+	c.max = max(c.max, c.current)
+}
+
+func (c *runningCounter) leave() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.current--
+}
+
+func (c *runningCounter) highest() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.max
+}
+
+func (a *stubAdapter) Name() string          { return a.name }
+func (a *stubAdapter) Provider() string      { return "aws" }
+func (a *stubAdapter) Permissions() []string { return nil }
+
+func (a *stubAdapter) Adapt(root *RootAdapter, state *state.State) error {
+	if a.running != nil {
+		a.running.enter()
+		defer a.running.leave()
+	}
+	if a.adapt != nil {
+		a.adapt(root, state)
+	}
+	select {
+	case <-time.After(a.delay):
+	case <-root.Context().Done():
+		return root.Context().Err()
+	}
+	metadata := root.CreateMetadata(a.name)
+	state.AWS.S3.Buckets = append(state.AWS.S3.Buckets, s3.Bucket{
+		Metadata: metadata,
+		Name:     iacTypes.String(a.name, metadata),
+	})
+	return a.err
+}
+
+func bucketNames(s *state.State) []string {
+	var names []string
+	for _, bucket := range s.AWS.S3.Buckets {
+		names = append(names, bucket.Name.Value())
+	}
+	return names
+}
+
+func Test_AdaptConcurrency(t *testing.T) {
+	tests := []struct {
+		name        string
+		parallelism int
+		delays      []time.Duration
+	}{
+		{
+			name:        "sequential",
+			parallelism: 1,
+			delays:      []time.Duration{30 * time.Millisecond, 20 * time.Millisecond, 10 * time.Millisecond, 0},
+		},
+		{
+			name:        "bounded",
+			parallelism: 2,
+			delays:      []time.Duration{40 * time.Millisecond, 30 * time.Millisecond, 20 * time.Millisecond, 10 * time.Millisecond, 0},
+		},
+		{
+			name:        "all at once",
+			parallelism: 6,
+			delays:      []time.Duration{50 * time.Millisecond, 40 * time.Millisecond, 30 * time.Millisecond, 20 * time.Millisecond, 10 * time.Millisecond, 0},
+		},
+		{
+			name:        "more slots than services",
+			parallelism: 10,
+			delays:      []time.Duration{30 * time.Millisecond, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			running := &runningCounter{}
+			var adapters []ServiceAdapter
+			for i, delay := range tt.delays {
+				name := fmt.Sprintf("service%d", i)
+				adapters = append(adapters, &stubAdapter{
+					name: name,
+					// the services which are registered first take the longest, so they finish last
+					delay:   delay + 20*time.Millisecond,
+					running: running,
+				})
+			}
+			withAdapters(t, adapters...)
+
+			stub := &stubAWS{}
+			opt := stub.options()
+			opt.ServiceParallelism = tt.parallelism
+
+			cloudState := &state.State{}
+			require.NoError(t, Adapt(context.Background(), cloudState, opt))
+
+			// every service adapts the same buckets, and the states are merged in registration order, so the
+			// service registered last wins even though it finished first
+			assert.Equal(t, []string{fmt.Sprintf("service%d", len(adapters)-1)}, bucketNames(cloudState))
+			assert.Equal(t, min(tt.parallelism, len(adapters)), running.highest())
+		})
+	}
+}
+
+func Test_AdaptCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var started []string
+	var mu sync.Mutex
+	record := func(root *RootAdapter, _ *state.State) {
+		mu.Lock()
+		defer mu.Unlock()
+		started = append(started, root.currentService)
+	}
+	withAdapters(t,
+		&stubAdapter{name: "first", delay: time.Minute, adapt: func(root *RootAdapter, s *state.State) {
+			record(root, s)
+			// the scan is cancelled while the first service holds the only slot
+			cancel()
+		}},
+		&stubAdapter{name: "second", adapt: record},
+		&stubAdapter{name: "third", adapt: record},
+	)
+
+	stub := &stubAWS{}
+	opt := stub.options()
+	opt.ServiceParallelism = 1
+	opt.Errors = errs.NewCollector()
+
+	cloudState := &state.State{}
+	err := Adapt(ctx, cloudState, opt)
+
+	var adapterErr errs.AdapterError
+	require.ErrorAs(t, err, &adapterErr)
+	assert.Len(t, adapterErr.Errors(), 3)
+	for _, err := range adapterErr.Errors() {
+		assert.ErrorIs(t, err, context.Canceled)
+	}
+
+	// the services waiting for a slot are never started, but are reported as scan gaps
+	assert.Equal(t, []string{"first"}, started)
+	assert.Empty(t, bucketNames(cloudState))
+	var gaps []string
+	for _, scanErr := range opt.Errors.List() {
+		gaps = append(gaps, scanErr.Service)
+	}
+	assert.ElementsMatch(t, []string{"first", "second", "third"}, gaps)
+}
+
+func Test_AdaptIsolation(t *testing.T) {
+	var mu sync.Mutex
+	seen := make(map[string]string)
+	check := func(root *RootAdapter, s *state.State) {
+		mu.Lock()
+		defer mu.Unlock()
+		// every service starts with an empty state of its own, whatever the others adapted
+		seen[root.currentService] = fmt.Sprintf("%d buckets, %s", len(s.AWS.S3.Buckets), root.CreateMetadata("x").Reference())
+	}
+
+	withAdapters(t,
+		&stubAdapter{name: "s3", delay: 10 * time.Millisecond, adapt: check},
+		&stubAdapter{name: "failing", delay: 20 * time.Millisecond, adapt: check, err: errors.New("boom")},
+		&stubAdapter{name: "sqs", delay: 0, adapt: check},
+	)
+
+	stub := &stubAWS{}
+	opt := stub.options()
+	opt.ServiceParallelism = 3
+
+	cloudState := &state.State{}
+	err := Adapt(context.Background(), cloudState, opt)
+	require.ErrorContains(t, err, "failed to adapt service failing: boom")
+
+	assert.Equal(t, map[string]string{
+		"s3":      "0 buckets, arn:aws:s3:::x",
+		"failing": "0 buckets, arn:aws:failing:us-east-1:123456789012:x",
+		"sqs":     "0 buckets, arn:aws:sqs:us-east-1:123456789012:x",
+	}, seen)
+	// the service registered last wins the merge, although it finished first
+	assert.Equal(t, []string{"sqs"}, bucketNames(cloudState))
+	assert.Equal(t, []string{"GetCallerIdentity"}, stub.calls())
+}
+
+func Test_CreateMetadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		region   string
+		service  string
+		resource string
+		want     string
+	}{
+		{service: "ec2", resource: "instance/i-123", want: "arn:aws:ec2:us-east-1:123456789012:instance/i-123"},
+		{service: "s3", resource: "bucket", want: "arn:aws:s3:::bucket"},
+		{service: "api-gateway", resource: "/restapis/abc", want: "arn:aws:apigateway:us-east-1::/restapis/abc"},
+		{service: "elasticsearch", resource: "domain/search", want: "arn:aws:es:us-east-1:123456789012:domain/search"},
+		{service: "dynamodb", resource: "table/orders", want: "arn:aws:dynamodb:us-east-1:123456789012:table/orders"},
+		{
+			name:     "GovCloud",
+			region:   "us-gov-west-1",
+			service:  "ec2",
+			resource: "instance/i-123",
+			want:     "arn:aws-us-gov:ec2:us-gov-west-1:123456789012:instance/i-123",
+		},
+		{
+			name:     "GovCloud s3",
+			region:   "us-gov-east-1",
+			service:  "s3",
+			resource: "bucket",
+			want:     "arn:aws-us-gov:s3:::bucket",
+		},
+		{
+			name:     "China",
+			region:   "cn-north-1",
+			service:  "elasticsearch",
+			resource: "domain/search",
+			want:     "arn:aws-cn:es:cn-north-1:123456789012:domain/search",
+		},
+		{
+			name:     "China api-gateway",
+			region:   "cn-northwest-1",
+			service:  "api-gateway",
+			resource: "/restapis/abc",
+			want:     "arn:aws-cn:apigateway:cn-northwest-1::/restapis/abc",
+		},
+	}
+
+	for _, tt := range tests {
+		name := tt.name
+		if name == "" {
+			name = tt.service
+		}
+		t.Run(name, func(t *testing.T) {
+			a := NewRootAdapter(context.Background(), aws.Config{Region: cmp.Or(tt.region, "us-east-1")}, progress.NoProgress, nil)
+			a.accountID = "123456789012"
+			a.currentService = tt.service
+			assert.Equal(t, tt.want, a.CreateMetadata(tt.resource).Reference())
+		})
+	}
+}
+
+func Test_AdaptPartition(t *testing.T) {
+	withAdapters(t, &stubAdapter{name: "sqs"})
+
+	// the ARNs of the resources are built in the partition of the caller
+	stub := &stubAWS{callerARN: "arn:aws-us-gov:iam::123456789012:user/scanner"}
+	opt := stub.options()
+	opt.Region = "us-gov-west-1"
+
+	cloudState := &state.State{}
+	require.NoError(t, Adapt(context.Background(), cloudState, opt))
+
+	var arns []string
+	for _, bucket := range cloudState.AWS.S3.Buckets {
+		arns = append(arns, bucket.Metadata.Reference())
+	}
+	assert.Equal(t, []string{"arn:aws-us-gov:sqs:us-gov-west-1:123456789012:sqs"}, arns)
+}
@@ -26,6 +26,12 @@ func (a *adapter) Name() string {
 	return "api-gateway"
 }
 
+func (a *adapter) Permissions() []string {
+	return []string{
+		"apigateway:GET",
+	}
+}
+
 func (a *adapter) Adapt(root *aws.RootAdapter, state *state.State) error {
 
 	a.RootAdapter = root
@@ -1,6 +1,7 @@
 package api_gateway
 
 import (
+	"context"
 	"fmt"
 
 	api "github.com/aws/aws-sdk-go-v2/service/apigateway"
@@ -35,11 +36,11 @@ func (a *adapter) getAPIsV1() ([]v1.API, error) {
 	return concurrency.Adapt(apiRestApis, a.RootAdapter, a.adaptRestAPIV1), nil
 }
 
-func (a *adapter) adaptRestAPIV1(restAPI agTypes.RestApi) (*v1.API, error) {
+func (a *adapter) adaptRestAPIV1(ctx context.Context, restAPI agTypes.RestApi) (*v1.API, error) {
 
 	metadata := a.CreateMetadata(fmt.Sprintf("/restapis/%s", *restAPI.Id))
 
-	stagesOutput, err := a.clientV1.GetStages(a.Context(), &api.GetStagesInput{
+	stagesOutput, err := a.clientV1.GetStages(ctx, &api.GetStagesInput{
 		RestApiId: restAPI.Id,
 	})
 	if err != nil {
@@ -58,7 +59,7 @@ func (a *adapter) adaptRestAPIV1(restAPI agTypes.RestApi) (*v1.API, error) {
 		Position:  nil,
 	}
 	for {
-		resourcesOutput, err := a.clientV1.GetResources(a.Context(), &resourcesInput)
+		resourcesOutput, err := a.clientV1.GetResources(ctx, &resourcesInput)
 		if err != nil {
 			return nil, err
 		}
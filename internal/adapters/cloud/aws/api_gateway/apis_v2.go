@@ -1,6 +1,7 @@
 package api_gateway
 
 import (
+	"context"
 	"fmt"
 
 	api "github.com/aws/aws-sdk-go-v2/service/apigatewayv2"
@@ -33,7 +34,7 @@ func (a *adapter) getAPIsV2() ([]v2.API, error) {
 	return concurrency.Adapt(apiApis, a.RootAdapter, a.adaptAPIV2), nil
 }
 
-func (a *adapter) adaptAPIV2(remoteAPI agTypes.Api) (*v2.API, error) {
+func (a *adapter) adaptAPIV2(ctx context.Context, remoteAPI agTypes.Api) (*v2.API, error) {
 
 	metadata := a.CreateMetadata(fmt.Sprintf("/apis/%s", *remoteAPI.ApiId))
 
@@ -42,7 +43,7 @@ func (a *adapter) adaptAPIV2(remoteAPI agTypes.Api) (*v2.API, error) {
 		ApiId: remoteAPI.ApiId,
 	}
 	for {
-		stagesOutput, err := a.clientV2.GetStages(a.Context(), input)
+		stagesOutput, err := a.clientV2.GetStages(ctx, input)
 		if err != nil {
 			return nil, err
 		}
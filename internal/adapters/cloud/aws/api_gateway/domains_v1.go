@@ -1,6 +1,7 @@
 package api_gateway
 
 import (
+	"context"
 	"fmt"
 
 	api "github.com/aws/aws-sdk-go-v2/service/apigateway"
@@ -34,7 +35,7 @@ func (a *adapter) getDomainNamesv1() ([]v1.DomainName, error) {
 
 }
 
-func (a *adapter) adaptDomainNameV1(domain agTypes.DomainName) (*v1.DomainName, error) {
+func (a *adapter) adaptDomainNameV1(ctx context.Context, domain agTypes.DomainName) (*v1.DomainName, error) {
 	metadata := a.CreateMetadata(fmt.Sprintf("/domainnames/%s", *domain.DomainName))
 	return &v1.DomainName{
 		Metadata:       metadata,
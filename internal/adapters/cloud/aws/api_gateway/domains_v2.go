@@ -1,6 +1,7 @@
 package api_gateway
 
 import (
+	"context"
 	"fmt"
 
 	api "github.com/aws/aws-sdk-go-v2/service/apigatewayv2"
@@ -35,7 +36,7 @@ func (a *adapter) getDomainNamesV2() ([]v2.DomainName, error) {
 
 }
 
-func (a *adapter) adaptDomainNameV2(domain agTypes.DomainName) (*v2.DomainName, error) {
+func (a *adapter) adaptDomainNameV2(ctx context.Context, domain agTypes.DomainName) (*v2.DomainName, error) {
 	metadata := a.CreateMetadata(fmt.Sprintf("/domainnames/%s", *domain.DomainName))
 	securityPolicy := "TLS_1_2"
 	for _, policy := range domain.DomainNameConfigurations {
@@ -1,6 +1,7 @@
 package athena
 
 import (
+	"context"
 	"fmt"
 
 	api "github.com/aws/aws-sdk-go-v2/service/athena"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/aquasecurity/trivy-aws/internal/adapters/cloud/aws"
 	"github.com/aquasecurity/trivy-aws/pkg/concurrency"
+	"github.com/aquasecurity/trivy-aws/pkg/progress"
 	"github.com/aquasecurity/trivy/pkg/iac/providers/aws/athena"
 	"github.com/aquasecurity/trivy/pkg/iac/state"
 	trivyTypes "github.com/aquasecurity/trivy/pkg/iac/types"
@@ -30,6 +32,15 @@ func (a *adapter) Name() string {
 	return "athena"
 }
 
+func (a *adapter) Permissions() []string {
+	return []string{
+		"athena:GetWorkGroup",
+		"athena:ListDataCatalogs",
+		"athena:ListDatabases",
+		"athena:ListWorkGroups",
+	}
+}
+
 func (a *adapter) Adapt(root *aws.RootAdapter, state *state.State) error {
 
 	a.RootAdapter = root
@@ -72,10 +83,10 @@ func (a *adapter) getWorkgroups() ([]athena.Workgroup, error) {
 	return concurrency.Adapt(apiWorkgroups, a.RootAdapter, a.adaptWorkgroup), nil
 }
 
-func (a *adapter) adaptWorkgroup(workgroup types.WorkGroupSummary) (*athena.Workgroup, error) {
+func (a *adapter) adaptWorkgroup(ctx context.Context, workgroup types.WorkGroupSummary) (*athena.Workgroup, error) {
 	metadata := a.CreateMetadata(fmt.Sprintf("workgroup/%s", *workgroup.Name))
 
-	output, err := a.client.GetWorkGroup(a.Context(), &api.GetWorkGroupInput{
+	output, err := a.client.GetWorkGroup(ctx, &api.GetWorkGroupInput{
 		WorkGroup: workgroup.Name,
 	})
 	if err != nil {
@@ -142,6 +153,7 @@ func (a *adapter) getDatabases() ([]athena.Database, error) {
 		}
 		databases = append(databases, catalogueDatabases...)
 		a.Tracker().IncrementResource()
+		progress.ResourceAdapted(a.Tracker())
 	}
 	return databases, nil
 }
@@ -166,7 +178,7 @@ func (a *adapter) getDatabasesForCatalogue(catalog types.DataCatalogSummary) ([]
 	return concurrency.Adapt(apiDatabases, a.RootAdapter, a.adaptDatabase), nil
 }
 
-func (a *adapter) adaptDatabase(database types.Database) (*athena.Database, error) {
+func (a *adapter) adaptDatabase(ctx context.Context, database types.Database) (*athena.Database, error) {
 	metadata := a.CreateMetadata("database/" + *database.Name)
 	name := trivyTypes.StringDefault("", metadata)
 	if database.Name != nil {
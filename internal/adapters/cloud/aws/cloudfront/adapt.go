@@ -1,6 +1,8 @@
 package cloudfront
 
 import (
+	"context"
+
 	api "github.com/aws/aws-sdk-go-v2/service/cloudfront"
 	"github.com/aws/aws-sdk-go-v2/service/cloudfront/types"
 
@@ -28,6 +30,13 @@ func (a *adapter) Name() string {
 	return "cloudfront"
 }
 
+func (a *adapter) Permissions() []string {
+	return []string{
+		"cloudfront:GetDistributionConfig",
+		"cloudfront:ListDistributions",
+	}
+}
+
 func (a *adapter) Adapt(root *aws.RootAdapter, state *state.State) error {
 
 	a.RootAdapter = root
@@ -65,11 +74,11 @@ func (a *adapter) getDistributions() ([]cloudfront.Distribution, error) {
 	return concurrency.Adapt(apiDistributions, a.RootAdapter, a.adaptDistribution), nil
 }
 
-func (a *adapter) adaptDistribution(distribution types.DistributionSummary) (*cloudfront.Distribution, error) {
+func (a *adapter) adaptDistribution(ctx context.Context, distribution types.DistributionSummary) (*cloudfront.Distribution, error) {
 
 	metadata := a.CreateMetadataFromARN(*distribution.ARN)
 
-	config, err := a.client.GetDistributionConfig(a.Context(), &api.GetDistributionConfigInput{
+	config, err := a.client.GetDistributionConfig(ctx, &api.GetDistributionConfigInput{
 		Id: distribution.Id,
 	})
 	if err != nil {
@@ -1,6 +1,8 @@
 package cloudtrail
 
 import (
+	"context"
+
 	api "github.com/aws/aws-sdk-go-v2/service/cloudtrail"
 	"github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
 
@@ -28,6 +30,15 @@ func (a *adapter) Name() string {
 	return "cloudtrail"
 }
 
+func (a *adapter) Permissions() []string {
+	return []string{
+		"cloudtrail:GetEventSelectors",
+		"cloudtrail:GetTrail",
+		"cloudtrail:GetTrailStatus",
+		"cloudtrail:ListTrails",
+	}
+}
+
 func (a *adapter) Adapt(root *aws.RootAdapter, state *state.State) error {
 
 	a.RootAdapter = root
@@ -65,11 +76,11 @@ func (a *adapter) getTrails() ([]cloudtrail.Trail, error) {
 	return concurrency.Adapt(apiTrails, a.RootAdapter, a.adaptTrail), nil
 }
 
-func (a *adapter) adaptTrail(info types.TrailInfo) (*cloudtrail.Trail, error) {
+func (a *adapter) adaptTrail(ctx context.Context, info types.TrailInfo) (*cloudtrail.Trail, error) {
 
 	metadata := a.CreateMetadataFromARN(*info.TrailARN)
 
-	response, err := a.client.GetTrail(a.Context(), &api.GetTrailInput{
+	response, err := a.client.GetTrail(ctx, &api.GetTrailInput{
 		Name: info.TrailARN,
 	})
 	if err != nil {
@@ -81,7 +92,7 @@ func (a *adapter) adaptTrail(info types.TrailInfo) (*cloudtrail.Trail, error) {
 		kmsKeyID = *response.Trail.KmsKeyId
 	}
 
-	status, err := a.client.GetTrailStatus(a.Context(), &api.GetTrailStatusInput{
+	status, err := a.client.GetTrailStatus(ctx, &api.GetTrailStatusInput{
 		Name: response.Trail.Name,
 	})
 	if err != nil {
@@ -110,7 +121,7 @@ func (a *adapter) adaptTrail(info types.TrailInfo) (*cloudtrail.Trail, error) {
 
 	var eventSelectors []cloudtrail.EventSelector
 	if response.Trail.HasCustomEventSelectors != nil && *response.Trail.HasCustomEventSelectors {
-		output, err := a.client.GetEventSelectors(a.Context(), &api.GetEventSelectorsInput{
+		output, err := a.client.GetEventSelectors(ctx, &api.GetEventSelectorsInput{
 			TrailName: info.Name,
 		})
 		if err != nil {
@@ -1,6 +1,8 @@
 package cloudwatch
 
 import (
+	"context"
+
 	cwApi "github.com/aws/aws-sdk-go-v2/service/cloudwatch"
 	cwTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
 	api "github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
@@ -31,6 +33,14 @@ func (a *adapter) Name() string {
 	return "cloudwatch"
 }
 
+func (a *adapter) Permissions() []string {
+	return []string{
+		"cloudwatch:DescribeAlarms",
+		"logs:DescribeLogGroups",
+		"logs:DescribeMetricFilters",
+	}
+}
+
 func (a *adapter) Adapt(root *aws.RootAdapter, state *state.State) error {
 
 	a.RootAdapter = root
@@ -96,7 +106,7 @@ func (a *adapter) getLogGroups() ([]cloudwatch.LogGroup, error) {
 	return concurrency.Adapt(apiLogGroups, a.RootAdapter, a.adaptLogGroup), nil
 }
 
-func (a *adapter) adaptLogGroup(group types.LogGroup) (*cloudwatch.LogGroup, error) {
+func (a *adapter) adaptLogGroup(ctx context.Context, group types.LogGroup) (*cloudwatch.LogGroup, error) {
 
 	metadata := a.CreateMetadataFromARN(*group.Arn)
 
@@ -114,7 +124,7 @@ func (a *adapter) adaptLogGroup(group types.LogGroup) (*cloudwatch.LogGroup, err
 	var metricFilters []cloudwatch.MetricFilter
 	var err error
 	if *group.MetricFilterCount > 0 {
-		metricFilters, err = a.getMetricFilters(group.LogGroupName, metadata)
+		metricFilters, err = a.getMetricFilters(ctx, group.LogGroupName, metadata)
 		if err != nil {
 			return nil, err
 		}
@@ -141,7 +151,7 @@ func (a *adapter) adaptLogGroup(group types.LogGroup) (*cloudwatch.LogGroup, err
 	}, nil
 }
 
-func (a *adapter) adaptAlarm(alarm cwTypes.MetricAlarm) (*cloudwatch.Alarm, error) {
+func (a *adapter) adaptAlarm(ctx context.Context, alarm cwTypes.MetricAlarm) (*cloudwatch.Alarm, error) {
 
 	metadata := a.CreateMetadataFromARN(*alarm.AlarmArn)
 
@@ -204,14 +214,14 @@ func (a *adapter) adaptAlarm(alarm cwTypes.MetricAlarm) (*cloudwatch.Alarm, erro
 	}, nil
 }
 
-func (a *adapter) getMetricFilters(name *string, metadata trivyTypes.Metadata) ([]cloudwatch.MetricFilter, error) {
+func (a *adapter) getMetricFilters(ctx context.Context, name *string, metadata trivyTypes.Metadata) ([]cloudwatch.MetricFilter, error) {
 
 	var apiMetricFilters []types.MetricFilter
 	input := api.DescribeMetricFiltersInput{
 		LogGroupName: name,
 	}
 	for {
-		output, err := a.logsClient.DescribeMetricFilters(a.Context(), &input)
+		output, err := a.logsClient.DescribeMetricFilters(ctx, &input)
 		if err != nil {
 			return nil, err
 		}
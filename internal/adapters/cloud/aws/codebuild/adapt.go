@@ -1,6 +1,8 @@
 package codebuild
 
 import (
+	"context"
+
 	api "github.com/aws/aws-sdk-go-v2/service/codebuild"
 
 	"github.com/aquasecurity/trivy-aws/internal/adapters/cloud/aws"
@@ -27,6 +29,13 @@ func (a *adapter) Name() string {
 	return "codebuild"
 }
 
+func (a *adapter) Permissions() []string {
+	return []string{
+		"codebuild:BatchGetProjects",
+		"codebuild:ListProjects",
+	}
+}
+
 func (a *adapter) Adapt(root *aws.RootAdapter, state *state.State) error {
 
 	a.RootAdapter = root
@@ -64,9 +73,9 @@ func (a *adapter) getProjects() ([]codebuild.Project, error) {
 	return concurrency.Adapt(projectNames, a.RootAdapter, a.adaptProject), nil
 }
 
-func (a *adapter) adaptProject(name string) (*codebuild.Project, error) {
+func (a *adapter) adaptProject(ctx context.Context, name string) (*codebuild.Project, error) {
 
-	output, err := a.client.BatchGetProjects(a.Context(), &api.BatchGetProjectsInput{
+	output, err := a.client.BatchGetProjects(ctx, &api.BatchGetProjectsInput{
 		Names: []string{name},
 	})
 	if err != nil {
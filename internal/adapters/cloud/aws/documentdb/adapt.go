@@ -1,6 +1,8 @@
 package documentdb
 
 import (
+	"context"
+
 	api "github.com/aws/aws-sdk-go-v2/service/docdb"
 	docdbTypes "github.com/aws/aws-sdk-go-v2/service/docdb/types"
 
@@ -29,6 +31,13 @@ func (a *adapter) Name() string {
 	return "documentdb"
 }
 
+func (a *adapter) Permissions() []string {
+	return []string{
+		"rds:DescribeDBClusters",
+		"rds:DescribeDBInstances",
+	}
+}
+
 func (a *adapter) Adapt(root *aws.RootAdapter, state *state.State) error {
 
 	a.RootAdapter = root
@@ -66,7 +75,7 @@ func (a *adapter) getClusters() ([]documentdb.Cluster, error) {
 	return concurrency.Adapt(apiClusters, a.RootAdapter, a.adaptCluster), nil
 }
 
-func (a *adapter) adaptCluster(cluster docdbTypes.DBCluster) (*documentdb.Cluster, error) {
+func (a *adapter) adaptCluster(ctx context.Context, cluster docdbTypes.DBCluster) (*documentdb.Cluster, error) {
 
 	metadata := a.CreateMetadataFromARN(*cluster.DBClusterArn)
 
@@ -77,7 +86,7 @@ func (a *adapter) adaptCluster(cluster docdbTypes.DBCluster) (*documentdb.Cluste
 
 	var instances []documentdb.Instance
 	for _, instance := range cluster.DBClusterMembers {
-		output, err := a.client.DescribeDBInstances(a.Context(), &api.DescribeDBInstancesInput{
+		output, err := a.client.DescribeDBInstances(ctx, &api.DescribeDBInstancesInput{
 			DBInstanceIdentifier: instance.DBInstanceIdentifier,
 		})
 		if err != nil {
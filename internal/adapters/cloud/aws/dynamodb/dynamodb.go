@@ -1,6 +1,8 @@
 package dynamodb
 
 import (
+	"context"
+
 	"github.com/aws/aws-sdk-go-v2/aws"
 	dynamodbApi "github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	dynamodbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
@@ -25,6 +27,14 @@ func (a *adapter) Name() string {
 	return "dynamodb"
 }
 
+func (a *adapter) Permissions() []string {
+	return []string{
+		"dynamodb:DescribeContinuousBackups",
+		"dynamodb:DescribeTable",
+		"dynamodb:ListTables",
+	}
+}
+
 func (a *adapter) Provider() string {
 	return "aws"
 }
@@ -66,11 +76,11 @@ func (a *adapter) getTables() (tables []dynamodb.Table, err error) {
 
 }
 
-func (a *adapter) adaptTable(tableName string) (*dynamodb.Table, error) {
+func (a *adapter) adaptTable(ctx context.Context, tableName string) (*dynamodb.Table, error) {
 
-	tableMetadata := a.CreateMetadata(tableName)
+	tableMetadata := a.CreateMetadata("table/" + tableName)
 
-	table, err := a.client.DescribeTable(a.Context(), &dynamodbApi.DescribeTableInput{
+	table, err := a.client.DescribeTable(ctx, &dynamodbApi.DescribeTableInput{
 		TableName: aws.String(tableName),
 	})
 	if err != nil {
@@ -92,7 +102,7 @@ func (a *adapter) adaptTable(tableName string) (*dynamodb.Table, error) {
 		}
 	}
 	pitRecovery := trivyTypes.Bool(false, tableMetadata)
-	continuousBackup, err := a.client.DescribeContinuousBackups(a.Context(), &dynamodbApi.DescribeContinuousBackupsInput{
+	continuousBackup, err := a.client.DescribeContinuousBackups(ctx, &dynamodbApi.DescribeContinuousBackupsInput{
 		TableName: aws.String(tableName),
 	})
 
@@ -1,6 +1,7 @@
 package ec2
 
 import (
+	"context"
 	"fmt"
 
 	ec2api "github.com/aws/aws-sdk-go-v2/service/ec2"
@@ -35,7 +36,7 @@ func (a *adapter) getLaunchTemplates() ([]ec2.LaunchTemplate, error) {
 	return concurrency.Adapt(apiTemplates, a.RootAdapter, a.adaptLaunchTemplate), nil
 }
 
-func (a *adapter) adaptLaunchTemplate(template types.LaunchTemplate) (*ec2.LaunchTemplate, error) {
+func (a *adapter) adaptLaunchTemplate(ctx context.Context, template types.LaunchTemplate) (*ec2.LaunchTemplate, error) {
 
 	metadata := a.CreateMetadata("launch-template/" + *template.LaunchTemplateId)
 
@@ -46,7 +47,7 @@ func (a *adapter) adaptLaunchTemplate(template types.LaunchTemplate) (*ec2.Launc
 		version = fmt.Sprintf("%d", *template.LatestVersionNumber)
 	}
 
-	output, err := a.client.DescribeLaunchTemplateVersions(a.Context(), &ec2api.DescribeLaunchTemplateVersionsInput{
+	output, err := a.client.DescribeLaunchTemplateVersions(ctx, &ec2api.DescribeLaunchTemplateVersionsInput{
 		LaunchTemplateId: template.LaunchTemplateId,
 		Versions:         []string{version},
 	})
@@ -1,19 +1,23 @@
 package ec2
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
 	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
 	ec2api "github.com/aws/aws-sdk-go-v2/service/ec2"
 	ec2Types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 
 	"github.com/aquasecurity/trivy-aws/internal/adapters/cloud/aws"
 	"github.com/aquasecurity/trivy-aws/pkg/concurrency"
+	"github.com/aquasecurity/trivy-aws/pkg/errs"
 	"github.com/aquasecurity/trivy-aws/pkg/types"
 	"github.com/aquasecurity/trivy/pkg/iac/providers/aws/ec2"
 	"github.com/aquasecurity/trivy/pkg/iac/state"
 	trivyTypes "github.com/aquasecurity/trivy/pkg/iac/types"
+	"github.com/aquasecurity/trivy/pkg/log"
 )
 
 type adapter struct {
@@ -33,6 +37,19 @@ func (a *adapter) Name() string {
 	return "ec2"
 }
 
+func (a *adapter) Permissions() []string {
+	return []string{
+		"ec2:DescribeFlowLogs",
+		"ec2:DescribeInstances",
+		"ec2:DescribeLaunchTemplateVersions",
+		"ec2:DescribeLaunchTemplates",
+		"ec2:DescribeNetworkAcls",
+		"ec2:DescribeSecurityGroups",
+		"ec2:DescribeVolumes",
+		"ec2:DescribeVpcs",
+	}
+}
+
 func (a *adapter) Adapt(root *aws.RootAdapter, state *state.State) error {
 
 	a.RootAdapter = root
@@ -80,6 +97,112 @@ func (a *adapter) Adapt(root *aws.RootAdapter, state *state.State) error {
 	return nil
 }
 
+// AdaptResources adapts the requested instances, security groups, network ACLs, launch templates and volumes.
+// VPCs are not supported, as they are adapted together with every security group of the region.
+func (a *adapter) AdaptResources(root *aws.RootAdapter, state *state.State, arns []string) error {
+
+	a.RootAdapter = root
+	a.client = ec2api.NewFromConfig(root.SessionConfig())
+
+	resources := make(map[string][]string)
+	var total int
+	for _, resource := range arns {
+		parsed, err := arn.Parse(resource)
+		if err != nil || parsed.Service != "ec2" {
+			a.Logger().Warn("Skipping resource which is not an EC2 resource", log.String("arn", resource))
+			continue
+		}
+		resourceType, _, _ := strings.Cut(parsed.Resource, "/")
+		switch resourceType {
+		case "instance", "security-group", "network-acl", "launch-template", "volume":
+			resources[resourceType] = append(resources[resourceType], resource)
+			total++
+		default:
+			a.Logger().Warn("Skipping EC2 resource which can not be scanned individually", log.String("arn", resource))
+		}
+	}
+
+	a.Tracker().SetTotalResources(total)
+	a.Tracker().SetServiceLabel("Adapting EC2 resources...")
+
+	state.AWS.EC2.Instances = concurrency.Adapt(resources["instance"], a.RootAdapter, func(ctx context.Context, resource string) (*ec2.Instance, error) {
+		output, err := a.client.DescribeInstances(ctx, &ec2api.DescribeInstancesInput{
+			InstanceIds: []string{resourceID(resource)},
+			Filters: []ec2Types.Filter{
+				{
+					Name:   awssdk.String("instance-state-name"),
+					Values: []string{"running"},
+				},
+			},
+		})
+		// an instance which is no longer running is removed, as it would not be discovered by a full scan
+		if err != nil || len(output.Reservations) == 0 || len(output.Reservations[0].Instances) == 0 {
+			return nil, a.ignoreNotFound(resource, err)
+		}
+		return a.adaptInstance(ctx, output.Reservations[0].Instances[0])
+	})
+
+	state.AWS.EC2.SecurityGroups = concurrency.Adapt(resources["security-group"], a.RootAdapter, func(ctx context.Context, resource string) (*ec2.SecurityGroup, error) {
+		output, err := a.client.DescribeSecurityGroups(ctx, &ec2api.DescribeSecurityGroupsInput{
+			GroupIds: []string{resourceID(resource)},
+		})
+		if err != nil || len(output.SecurityGroups) == 0 {
+			return nil, a.ignoreNotFound(resource, err)
+		}
+		return a.adaptSecurityGroup(ctx, output.SecurityGroups[0])
+	})
+
+	state.AWS.EC2.NetworkACLs = concurrency.Adapt(resources["network-acl"], a.RootAdapter, func(ctx context.Context, resource string) (*ec2.NetworkACL, error) {
+		output, err := a.client.DescribeNetworkAcls(ctx, &ec2api.DescribeNetworkAclsInput{
+			NetworkAclIds: []string{resourceID(resource)},
+		})
+		if err != nil || len(output.NetworkAcls) == 0 {
+			return nil, a.ignoreNotFound(resource, err)
+		}
+		return a.adaptNetworkACL(ctx, output.NetworkAcls[0])
+	})
+
+	state.AWS.EC2.LaunchTemplates = concurrency.Adapt(resources["launch-template"], a.RootAdapter, func(ctx context.Context, resource string) (*ec2.LaunchTemplate, error) {
+		output, err := a.client.DescribeLaunchTemplates(ctx, &ec2api.DescribeLaunchTemplatesInput{
+			LaunchTemplateIds: []string{resourceID(resource)},
+		})
+		if err != nil || len(output.LaunchTemplates) == 0 {
+			return nil, a.ignoreNotFound(resource, err)
+		}
+		return a.adaptLaunchTemplate(ctx, output.LaunchTemplates[0])
+	})
+
+	state.AWS.EC2.Volumes = concurrency.Adapt(resources["volume"], a.RootAdapter, func(ctx context.Context, resource string) (*ec2.Volume, error) {
+		output, err := a.client.DescribeVolumes(ctx, &ec2api.DescribeVolumesInput{
+			VolumeIds: []string{resourceID(resource)},
+		})
+		if err != nil || len(output.Volumes) == 0 {
+			return nil, a.ignoreNotFound(resource, err)
+		}
+		return a.adaptVolume(ctx, output.Volumes[0])
+	})
+
+	return nil
+}
+
+// ignoreNotFound records a requested resource which no longer exists, so that it is removed from the cache.
+// Any other error is returned, as a resource which could not be fetched is kept in the cache.
+func (a *adapter) ignoreNotFound(resource string, err error) error {
+	if err != nil && errs.Classify(err) != errs.ClassNotFound {
+		return err
+	}
+	a.RecordNotFound(resource)
+	return nil
+}
+
+// resourceID returns the ID of an EC2 resource from its ARN, e.g. "i-1234567890abcdef0" for
+// "arn:aws:ec2:us-east-1:123456789012:instance/i-1234567890abcdef0"
+func resourceID(resource string) string {
+	parsed, _ := arn.Parse(resource)
+	_, id, _ := strings.Cut(parsed.Resource, "/")
+	return id
+}
+
 func (a *adapter) getInstances() (instances []ec2.Instance, err error) {
 
 	a.Tracker().SetServiceLabel("Discovering instances...")
@@ -113,7 +236,7 @@ func (a *adapter) getInstances() (instances []ec2.Instance, err error) {
 	return concurrency.Adapt(apiInstances, a.RootAdapter, a.adaptInstance), nil
 }
 
-func (a *adapter) adaptInstance(instance ec2Types.Instance) (*ec2.Instance, error) {
+func (a *adapter) adaptInstance(ctx context.Context, instance ec2Types.Instance) (*ec2.Instance, error) {
 
 	volumeBlockMap := make(map[string]*ec2.BlockDevice)
 	var volumeIds []string
@@ -143,7 +266,7 @@ func (a *adapter) adaptInstance(instance ec2Types.Instance) (*ec2.Instance, erro
 		}
 	}
 
-	volumes, err := a.client.DescribeVolumes(a.Context(), &ec2api.DescribeVolumesInput{
+	volumes, err := a.client.DescribeVolumes(ctx, &ec2api.DescribeVolumesInput{
 		VolumeIds: volumeIds,
 	})
 	if err != nil {
@@ -1,6 +1,7 @@
 package ec2
 
 import (
+	"context"
 	"fmt"
 
 	ec2api "github.com/aws/aws-sdk-go-v2/service/ec2"
@@ -35,7 +36,7 @@ func (a *adapter) getVolumes() ([]ec2.Volume, error) {
 	return concurrency.Adapt(apiVolumes, a.RootAdapter, a.adaptVolume), nil
 }
 
-func (a *adapter) adaptVolume(volume types.Volume) (*ec2.Volume, error) {
+func (a *adapter) adaptVolume(ctx context.Context, volume types.Volume) (*ec2.Volume, error) {
 
 	metadata := a.CreateMetadata(fmt.Sprintf("volume/%s", *volume.VolumeId))
 
@@ -1,6 +1,8 @@
 package ec2
 
 import (
+	"context"
+
 	"github.com/aws/aws-sdk-go-v2/aws"
 	ec2api "github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
@@ -79,7 +81,7 @@ func (a *adapter) getVPCs() (defaultVpcs []ec2.VPC, err error) {
 	return concurrency.Adapt(apiVPCs, a.RootAdapter, a.adaptVPC), nil
 }
 
-func (a *adapter) adaptSecurityGroup(apiSecurityGroup types.SecurityGroup) (*ec2.SecurityGroup, error) {
+func (a *adapter) adaptSecurityGroup(ctx context.Context, apiSecurityGroup types.SecurityGroup) (*ec2.SecurityGroup, error) {
 
 	sgMetadata := a.CreateMetadata("security-group/" + *apiSecurityGroup.GroupId)
 
@@ -120,7 +122,7 @@ func (a *adapter) adaptSecurityGroup(apiSecurityGroup types.SecurityGroup) (*ec2
 
 }
 
-func (a *adapter) adaptNetworkACL(apiNacl types.NetworkAcl) (*ec2.NetworkACL, error) {
+func (a *adapter) adaptNetworkACL(ctx context.Context, apiNacl types.NetworkAcl) (*ec2.NetworkACL, error) {
 
 	naclMetadata := a.CreateMetadata("network-acl/" + *apiNacl.NetworkAclId)
 
@@ -146,7 +148,7 @@ func (a *adapter) adaptNetworkACL(apiNacl types.NetworkAcl) (*ec2.NetworkACL, er
 	return nacl, nil
 }
 
-func (a *adapter) adaptVPC(v types.Vpc) (*ec2.VPC, error) {
+func (a *adapter) adaptVPC(ctx context.Context, v types.Vpc) (*ec2.VPC, error) {
 
 	vpcMetadata := a.CreateMetadata("vpc/" + *v.VpcId)
 	vpc := &ec2.VPC{
@@ -161,7 +163,7 @@ func (a *adapter) adaptVPC(v types.Vpc) (*ec2.VPC, error) {
 		vpc.IsDefault = trivyTypes.BoolDefault(*v.IsDefault, vpcMetadata)
 	}
 
-	logs, err := a.client.DescribeFlowLogs(a.Context(), &ec2api.DescribeFlowLogsInput{
+	logs, err := a.client.DescribeFlowLogs(ctx, &ec2api.DescribeFlowLogsInput{
 		Filter: []types.Filter{
 			{
 				Name:   aws.String("resource-id"),
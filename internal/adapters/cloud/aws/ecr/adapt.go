@@ -1,6 +1,8 @@
 package ecr
 
 import (
+	"context"
+
 	ecrapi "github.com/aws/aws-sdk-go-v2/service/ecr"
 	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
 
@@ -30,6 +32,13 @@ func (a *adapter) Name() string {
 	return "ecr"
 }
 
+func (a *adapter) Permissions() []string {
+	return []string{
+		"ecr:DescribeRepositories",
+		"ecr:GetRepositoryPolicy",
+	}
+}
+
 func (a *adapter) Adapt(root *aws.RootAdapter, state *state.State) error {
 
 	a.RootAdapter = root
@@ -68,7 +77,7 @@ func (a *adapter) getRepositories() ([]ecr.Repository, error) {
 	return concurrency.Adapt(apiRepositories, a.RootAdapter, a.adaptRepository), nil
 }
 
-func (a *adapter) adaptRepository(apiRepository types.Repository) (*ecr.Repository, error) {
+func (a *adapter) adaptRepository(ctx context.Context, apiRepository types.Repository) (*ecr.Repository, error) {
 
 	metadata := a.CreateMetadataFromARN(*apiRepository.RepositoryArn)
 
@@ -86,7 +95,7 @@ func (a *adapter) adaptRepository(apiRepository types.Repository) (*ecr.Reposito
 
 	var policies []iam.Policy
 
-	if output, err := a.api.GetRepositoryPolicy(a.Context(), &ecrapi.GetRepositoryPolicyInput{
+	if output, err := a.api.GetRepositoryPolicy(ctx, &ecrapi.GetRepositoryPolicyInput{
 		RepositoryName: apiRepository.RepositoryName,
 	}); err == nil {
 		parsed, err := iamgo.ParseString(*output.PolicyText)
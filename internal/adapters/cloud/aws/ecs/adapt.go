@@ -24,6 +24,15 @@ func (a *adapter) Name() string {
 	return "ecs"
 }
 
+func (a *adapter) Permissions() []string {
+	return []string{
+		"ecs:DescribeClusters",
+		"ecs:DescribeTaskDefinition",
+		"ecs:ListClusters",
+		"ecs:ListTaskDefinitions",
+	}
+}
+
 func (a *adapter) Adapt(root *aws.RootAdapter, state *state.State) error {
 
 	a.RootAdapter = root
@@ -1,6 +1,7 @@
 package ecs
 
 import (
+	"context"
 	"fmt"
 
 	ecsapi "github.com/aws/aws-sdk-go-v2/service/ecs"
@@ -36,13 +37,13 @@ func (a *adapter) getClusters() ([]ecs.Cluster, error) {
 	return concurrency.Adapt(clusterARNs, a.RootAdapter, a.adaptCluster), nil
 }
 
-func (a *adapter) adaptCluster(arn string) (*ecs.Cluster, error) {
+func (a *adapter) adaptCluster(ctx context.Context, arn string) (*ecs.Cluster, error) {
 
 	metadata := a.CreateMetadataFromARN(arn)
 
 	var enableInsights bool
 
-	output, err := a.api.DescribeClusters(a.Context(), &ecsapi.DescribeClustersInput{
+	output, err := a.api.DescribeClusters(ctx, &ecsapi.DescribeClustersInput{
 		Clusters: []string{arn},
 		Include: []types.ClusterField{
 			types.ClusterFieldSettings,
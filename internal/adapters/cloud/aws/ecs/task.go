@@ -1,6 +1,7 @@
 package ecs
 
 import (
+	"context"
 	"strconv"
 
 	ecsapi "github.com/aws/aws-sdk-go-v2/service/ecs"
@@ -32,9 +33,9 @@ func (a *adapter) getTaskDefinitions() ([]ecs.TaskDefinition, error) {
 	return concurrency.Adapt(definitionARNs, a.RootAdapter, a.adaptTaskDefinition), nil
 }
 
-func (a *adapter) adaptTaskDefinition(arn string) (*ecs.TaskDefinition, error) {
+func (a *adapter) adaptTaskDefinition(ctx context.Context, arn string) (*ecs.TaskDefinition, error) {
 
-	output, err := a.api.DescribeTaskDefinition(a.Context(), &ecsapi.DescribeTaskDefinitionInput{
+	output, err := a.api.DescribeTaskDefinition(ctx, &ecsapi.DescribeTaskDefinitionInput{
 		TaskDefinition: &arn,
 	})
 	if err != nil {
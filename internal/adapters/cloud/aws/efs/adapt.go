@@ -1,6 +1,8 @@
 package efs
 
 import (
+	"context"
+
 	api "github.com/aws/aws-sdk-go-v2/service/efs"
 	"github.com/aws/aws-sdk-go-v2/service/efs/types"
 
@@ -28,6 +30,12 @@ func (a *adapter) Name() string {
 	return "efs"
 }
 
+func (a *adapter) Permissions() []string {
+	return []string{
+		"elasticfilesystem:DescribeFileSystems",
+	}
+}
+
 func (a *adapter) Adapt(root *aws.RootAdapter, state *state.State) error {
 
 	a.RootAdapter = root
@@ -65,7 +73,7 @@ func (a *adapter) getFilesystems() ([]efs.FileSystem, error) {
 	return concurrency.Adapt(apiFilesystems, a.RootAdapter, a.adaptFilesystem), nil
 }
 
-func (a *adapter) adaptFilesystem(apiFilesystem types.FileSystemDescription) (*efs.FileSystem, error) {
+func (a *adapter) adaptFilesystem(ctx context.Context, apiFilesystem types.FileSystemDescription) (*efs.FileSystem, error) {
 	metadata := a.CreateMetadataFromARN(*apiFilesystem.FileSystemArn)
 	encrypted := trivyTypes.BoolDefault(false, metadata)
 	if apiFilesystem.Encrypted != nil {
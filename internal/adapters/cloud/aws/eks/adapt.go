@@ -1,6 +1,8 @@
 package eks
 
 import (
+	"context"
+
 	eksapi "github.com/aws/aws-sdk-go-v2/service/eks"
 	"github.com/aws/aws-sdk-go-v2/service/eks/types"
 
@@ -28,6 +30,13 @@ func (a *adapter) Name() string {
 	return "eks"
 }
 
+func (a *adapter) Permissions() []string {
+	return []string{
+		"eks:DescribeCluster",
+		"eks:ListClusters",
+	}
+}
+
 func (a *adapter) Adapt(root *aws.RootAdapter, state *state.State) error {
 
 	a.RootAdapter = root
@@ -66,9 +75,9 @@ func (a *adapter) getClusters() ([]eks.Cluster, error) {
 }
 
 // nolint
-func (a *adapter) adaptCluster(name string) (*eks.Cluster, error) {
+func (a *adapter) adaptCluster(ctx context.Context, name string) (*eks.Cluster, error) {
 
-	output, err := a.api.DescribeCluster(a.Context(), &eksapi.DescribeClusterInput{
+	output, err := a.api.DescribeCluster(ctx, &eksapi.DescribeClusterInput{
 		Name: &name,
 	})
 	if err != nil {
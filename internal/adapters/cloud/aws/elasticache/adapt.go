@@ -1,11 +1,14 @@
 package elasticache
 
 import (
+	"context"
+
 	api "github.com/aws/aws-sdk-go-v2/service/elasticache"
 	"github.com/aws/aws-sdk-go-v2/service/elasticache/types"
 
 	"github.com/aquasecurity/trivy-aws/internal/adapters/cloud/aws"
 	"github.com/aquasecurity/trivy-aws/pkg/concurrency"
+	"github.com/aquasecurity/trivy-aws/pkg/progress"
 	"github.com/aquasecurity/trivy/pkg/iac/providers/aws/elasticache"
 	"github.com/aquasecurity/trivy/pkg/iac/state"
 	trivyTypes "github.com/aquasecurity/trivy/pkg/iac/types"
@@ -29,6 +32,14 @@ func (a *adapter) Name() string {
 	return "elasticache"
 }
 
+func (a *adapter) Permissions() []string {
+	return []string{
+		"elasticache:DescribeCacheClusters",
+		"elasticache:DescribeCacheSecurityGroups",
+		"elasticache:DescribeReplicationGroups",
+	}
+}
+
 func (a *adapter) Adapt(root *aws.RootAdapter, state *state.State) error {
 
 	a.RootAdapter = root
@@ -74,7 +85,7 @@ func (a *adapter) getClusters() ([]elasticache.Cluster, error) {
 	return concurrency.Adapt(apiClusters, a.RootAdapter, a.adaptCluster), nil
 }
 
-func (a *adapter) adaptCluster(apiCluster types.CacheCluster) (*elasticache.Cluster, error) {
+func (a *adapter) adaptCluster(ctx context.Context, apiCluster types.CacheCluster) (*elasticache.Cluster, error) {
 	metadata := a.CreateMetadataFromARN(*apiCluster.ARN)
 
 	engine := trivyTypes.StringDefault("", metadata)
@@ -130,6 +141,7 @@ func (a *adapter) getReplicationGroups() ([]elasticache.ReplicationGroup, error)
 		}
 		groups = append(groups, *group)
 		a.Tracker().IncrementResource()
+		progress.ResourceAdapted(a.Tracker())
 	}
 
 	return groups, nil
@@ -184,6 +196,7 @@ func (a *adapter) getSecurityGroups() ([]elasticache.SecurityGroup, error) {
 		}
 		groups = append(groups, *group)
 		a.Tracker().IncrementResource()
+		progress.ResourceAdapted(a.Tracker())
 	}
 
 	return groups, nil
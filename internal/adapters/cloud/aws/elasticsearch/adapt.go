@@ -1,6 +1,8 @@
 package elasticsearch
 
 import (
+	"context"
+
 	api "github.com/aws/aws-sdk-go-v2/service/elasticsearchservice"
 	"github.com/aws/aws-sdk-go-v2/service/elasticsearchservice/types"
 
@@ -28,6 +30,13 @@ func (a *adapter) Name() string {
 	return "elasticsearch"
 }
 
+func (a *adapter) Permissions() []string {
+	return []string{
+		"es:DescribeElasticsearchDomain",
+		"es:ListDomainNames",
+	}
+}
+
 func (a *adapter) Adapt(root *aws.RootAdapter, state *state.State) error {
 
 	a.RootAdapter = root
@@ -58,10 +67,10 @@ func (a *adapter) getDomains() ([]elasticsearch.Domain, error) {
 	return concurrency.Adapt(apiDomains, a.RootAdapter, a.adaptDomain), nil
 }
 
-func (a *adapter) adaptDomain(apiDomain types.DomainInfo) (*elasticsearch.Domain, error) {
-	metadata := a.CreateMetadata(*apiDomain.DomainName)
+func (a *adapter) adaptDomain(ctx context.Context, apiDomain types.DomainInfo) (*elasticsearch.Domain, error) {
+	metadata := a.CreateMetadata("domain/" + *apiDomain.DomainName)
 
-	output, err := a.api.DescribeElasticsearchDomain(a.Context(), &api.DescribeElasticsearchDomainInput{
+	output, err := a.api.DescribeElasticsearchDomain(ctx, &api.DescribeElasticsearchDomainInput{
 		DomainName: apiDomain.DomainName,
 	})
 	if err != nil {
@@ -1,6 +1,8 @@
 package elb
 
 import (
+	"context"
+
 	api "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
 	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
 
@@ -28,6 +30,14 @@ func (a *adapter) Name() string {
 	return "elb"
 }
 
+func (a *adapter) Permissions() []string {
+	return []string{
+		"elasticloadbalancing:DescribeListeners",
+		"elasticloadbalancing:DescribeLoadBalancerAttributes",
+		"elasticloadbalancing:DescribeLoadBalancers",
+	}
+}
+
 func (a *adapter) Adapt(root *aws.RootAdapter, state *state.State) error {
 
 	a.RootAdapter = root
@@ -65,13 +75,13 @@ func (a *adapter) getLoadBalancers() ([]elb.LoadBalancer, error) {
 	return concurrency.Adapt(apiLoadBalancers, a.RootAdapter, a.adaptLoadBalancer), nil
 }
 
-func (a *adapter) adaptLoadBalancer(apiLoadBalancer types.LoadBalancer) (*elb.LoadBalancer, error) {
+func (a *adapter) adaptLoadBalancer(ctx context.Context, apiLoadBalancer types.LoadBalancer) (*elb.LoadBalancer, error) {
 	metadata := a.CreateMetadataFromARN(*apiLoadBalancer.LoadBalancerArn)
 
 	var dropInvalidHeaders bool
 	{
 		// routing.http.drop_invalid_header_fields.enabled
-		output, err := a.api.DescribeLoadBalancerAttributes(a.Context(), &api.DescribeLoadBalancerAttributesInput{
+		output, err := a.api.DescribeLoadBalancerAttributes(ctx, &api.DescribeLoadBalancerAttributesInput{
 			LoadBalancerArn: apiLoadBalancer.LoadBalancerArn,
 		})
 		if err != nil {
@@ -91,7 +101,7 @@ func (a *adapter) adaptLoadBalancer(apiLoadBalancer types.LoadBalancer) (*elb.Lo
 			LoadBalancerArn: apiLoadBalancer.LoadBalancerArn,
 		}
 		for {
-			output, err := a.api.DescribeListeners(a.Context(), &input)
+			output, err := a.api.DescribeListeners(ctx, &input)
 			if err != nil {
 				return nil, err
 			}
@@ -1,11 +1,14 @@
 package emr
 
 import (
+	"context"
+
 	api "github.com/aws/aws-sdk-go-v2/service/emr"
 	"github.com/aws/aws-sdk-go-v2/service/emr/types"
 
 	"github.com/aquasecurity/trivy-aws/internal/adapters/cloud/aws"
 	"github.com/aquasecurity/trivy-aws/pkg/concurrency"
+	"github.com/aquasecurity/trivy-aws/pkg/progress"
 	"github.com/aquasecurity/trivy/pkg/iac/providers/aws/emr"
 	"github.com/aquasecurity/trivy/pkg/iac/state"
 	trivyTypes "github.com/aquasecurity/trivy/pkg/iac/types"
@@ -29,6 +32,15 @@ func (a *adapter) Name() string {
 	return "emr"
 }
 
+func (a *adapter) Permissions() []string {
+	return []string{
+		"elasticmapreduce:DescribeCluster",
+		"elasticmapreduce:DescribeSecurityConfiguration",
+		"elasticmapreduce:ListClusters",
+		"elasticmapreduce:ListSecurityConfigurations",
+	}
+}
+
 func (a *adapter) Adapt(root *aws.RootAdapter, state *state.State) error {
 
 	a.RootAdapter = root
@@ -71,11 +83,11 @@ func (a *adapter) getClusters() ([]emr.Cluster, error) {
 	return concurrency.Adapt(apiClusters, a.RootAdapter, a.adaptCluster), nil
 }
 
-func (a *adapter) adaptCluster(apiCluster types.ClusterSummary) (*emr.Cluster, error) {
+func (a *adapter) adaptCluster(ctx context.Context, apiCluster types.ClusterSummary) (*emr.Cluster, error) {
 
 	metadata := a.CreateMetadataFromARN(*apiCluster.ClusterArn)
 
-	output, err := a.api.DescribeCluster(a.Context(), &api.DescribeClusterInput{
+	output, err := a.api.DescribeCluster(ctx, &api.DescribeClusterInput{
 		ClusterId: apiCluster.Id,
 	})
 	if err != nil {
@@ -138,6 +150,7 @@ func (a *adapter) getSecurityConfigurations() ([]emr.SecurityConfiguration, erro
 		}
 		configs = append(configs, *config)
 		a.Tracker().IncrementResource()
+		progress.ResourceAdapted(a.Tracker())
 	}
 
 	return configs, nil
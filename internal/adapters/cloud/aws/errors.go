@@ -0,0 +1,36 @@
+package aws
+
+import (
+	"context"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/middleware"
+
+	"github.com/aquasecurity/trivy-aws/pkg/errs"
+)
+
+// withErrorRecorder returns a copy of the config which records the denied and throttled API calls of the service.
+// Adapters often tolerate a failed call and carry on without the data, so these calls are recorded at the SDK
+// level rather than relying on the adapters to report them. Other errors, such as a missing bucket policy, are
+// commonly expected and only recorded when an adapter fails because of them.
+func withErrorRecorder(cfg aws.Config, collector *errs.Collector, service, region string) aws.Config {
+	cfg = cfg.Copy()
+	cfg.APIOptions = append(slices.Clone(cfg.APIOptions), func(stack *middleware.Stack) error {
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("RecordScanErrors",
+			func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+				out, metadata, err := next.HandleInitialize(ctx, in)
+				if err == nil {
+					return out, metadata, err
+				}
+				if class := errs.Classify(err); class == errs.ClassAccessDenied || class == errs.ClassThrottling {
+					scanErr := errs.NewScanError(service, region, "", err)
+					scanErr.Operation = awsmiddleware.GetOperationName(ctx)
+					collector.Add(scanErr)
+				}
+				return out, metadata, err
+			}), middleware.After)
+	})
+	return cfg
+}
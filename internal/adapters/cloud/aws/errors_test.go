@@ -0,0 +1,79 @@
+package aws
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/trivy-aws/pkg/errs"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// stubConfig returns a config whose requests are answered by the handler rather than AWS
+func stubConfig(handler func(req *http.Request) (int, string)) aws.Config {
+	return aws.Config{
+		Region:      "us-east-1",
+		Credentials: credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+		Retryer:     func() aws.Retryer { return aws.NopRetryer{} },
+		HTTPClient: &http.Client{
+			Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				status, body := handler(req)
+				return &http.Response{
+					StatusCode: status,
+					Header:     http.Header{"Content-Type": []string{"text/xml"}},
+					Body:       io.NopCloser(strings.NewReader(body)),
+					Request:    req,
+				}, nil
+			}),
+		},
+	}
+}
+
+func Test_ErrorRecorder(t *testing.T) {
+	cfg := stubConfig(func(req *http.Request) (int, string) {
+		if req.URL.Query().Has("logging") {
+			return http.StatusForbidden, `<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`
+		}
+		return http.StatusNotFound, `<Error><Code>NoSuchBucket</Code><Message>missing</Message></Error>`
+	})
+
+	collector := errs.NewCollector()
+	client := s3.NewFromConfig(withErrorRecorder(cfg, collector, "s3", "us-east-1"), func(o *s3.Options) {
+		o.UsePathStyle = true
+	})
+
+	for range 2 {
+		_, err := client.GetBucketLogging(context.Background(), &s3.GetBucketLoggingInput{Bucket: aws.String("bucket")})
+		require.Error(t, err)
+	}
+	_, err := client.GetBucketPolicy(context.Background(), &s3.GetBucketPolicyInput{Bucket: aws.String("bucket")})
+	require.Error(t, err)
+
+	// missing resources are not gaps in the coverage, so only the denied calls are recorded
+	assert.Equal(t, []errs.ScanError{
+		{
+			Service:   "s3",
+			Region:    "us-east-1",
+			Operation: "GetBucketLogging",
+			Class:     errs.ClassAccessDenied,
+			Message:   "Access Denied",
+			Count:     2,
+		},
+	}, collector.List())
+
+	// the original config is left untouched
+	assert.Len(t, cfg.APIOptions, 0)
+}
@@ -1,6 +1,7 @@
 package iam
 
 import (
+	"context"
 	"fmt"
 
 	iamapi "github.com/aws/aws-sdk-go-v2/service/iam"
@@ -37,8 +38,8 @@ func (a *adapter) adaptServerCertificates(state *state.State) error {
 	return nil
 }
 
-func (a *adapter) adaptServerCertificate(certInfo iamtypes.ServerCertificateMetadata) (*iam.ServerCertificate, error) {
-	cert, err := a.api.GetServerCertificate(a.Context(), &iamapi.GetServerCertificateInput{
+func (a *adapter) adaptServerCertificate(ctx context.Context, certInfo iamtypes.ServerCertificateMetadata) (*iam.ServerCertificate, error) {
+	cert, err := a.api.GetServerCertificate(ctx, &iamapi.GetServerCertificateInput{
 		ServerCertificateName: certInfo.ServerCertificateName,
 	})
 	if err != nil {
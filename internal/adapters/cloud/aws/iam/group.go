@@ -1,6 +1,7 @@
 package iam
 
 import (
+	"context"
 	"fmt"
 
 	iamapi "github.com/aws/aws-sdk-go-v2/service/iam"
@@ -39,7 +40,7 @@ func (a *adapter) adaptGroups(state *state.State) error {
 	return nil
 }
 
-func (a *adapter) adaptGroup(apiGroup iamtypes.Group, state *state.State) (*iam.Group, error) {
+func (a *adapter) adaptGroup(ctx context.Context, apiGroup iamtypes.Group, state *state.State) (*iam.Group, error) {
 
 	if apiGroup.Arn == nil {
 		return nil, fmt.Errorf("group arn not specified")
@@ -56,7 +57,7 @@ func (a *adapter) adaptGroup(apiGroup iamtypes.Group, state *state.State) (*iam.
 			GroupName: apiGroup.GroupName,
 		}
 		for {
-			policiesOutput, err := a.api.ListAttachedGroupPolicies(a.Context(), input)
+			policiesOutput, err := a.api.ListAttachedGroupPolicies(ctx, input)
 			if err != nil {
 				a.Logger().Error("Failed to locate policies attached to group",
 					log.String("name", *apiGroup.GroupName), log.Err(err))
@@ -64,7 +65,7 @@ func (a *adapter) adaptGroup(apiGroup iamtypes.Group, state *state.State) (*iam.
 			}
 
 			for _, apiPolicy := range policiesOutput.AttachedPolicies {
-				policy, err := a.adaptAttachedPolicy(apiPolicy)
+				policy, err := a.adaptAttachedPolicy(ctx, apiPolicy)
 				if err != nil {
 					a.Logger().Error("Failed to adapt policy attached to group",
 						log.String("name", *apiGroup.GroupName), log.Err(err))
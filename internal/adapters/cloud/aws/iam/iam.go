@@ -1,9 +1,17 @@
 package iam
 
 import (
+	"context"
+	"path"
+	"strings"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
 	iamapi "github.com/aws/aws-sdk-go-v2/service/iam"
 
 	"github.com/aquasecurity/trivy-aws/internal/adapters/cloud/aws"
+	"github.com/aquasecurity/trivy-aws/pkg/concurrency"
+	"github.com/aquasecurity/trivy-aws/pkg/errs"
 	"github.com/aquasecurity/trivy/pkg/iac/providers/aws/iam"
 	"github.com/aquasecurity/trivy/pkg/iac/state"
 	"github.com/aquasecurity/trivy/pkg/iac/types"
@@ -27,6 +35,29 @@ func (a *adapter) Name() string {
 	return "iam"
 }
 
+func (a *adapter) Permissions() []string {
+	return []string{
+		"iam:GetAccessKeyLastUsed",
+		"iam:GetAccountPasswordPolicy",
+		"iam:GetGroup",
+		"iam:GetPolicy",
+		"iam:GetPolicyVersion",
+		"iam:GetRole",
+		"iam:GetServerCertificate",
+		"iam:GetUser",
+		"iam:ListAccessKeys",
+		"iam:ListAttachedGroupPolicies",
+		"iam:ListAttachedRolePolicies",
+		"iam:ListAttachedUserPolicies",
+		"iam:ListGroups",
+		"iam:ListMFADevices",
+		"iam:ListPolicies",
+		"iam:ListRoles",
+		"iam:ListServerCertificates",
+		"iam:ListUsers",
+	}
+}
+
 func (a *adapter) Adapt(root *aws.RootAdapter, state *state.State) error {
 
 	a.RootAdapter = root
@@ -60,6 +91,94 @@ func (a *adapter) Adapt(root *aws.RootAdapter, state *state.State) error {
 	return nil
 }
 
+// AdaptResources adapts the requested policies, roles, users and groups. The account password policy and
+// the server certificates are only adapted when every resource of the service is.
+func (a *adapter) AdaptResources(root *aws.RootAdapter, state *state.State, arns []string) error {
+
+	a.RootAdapter = root
+	a.api = iamapi.NewFromConfig(root.SessionConfig())
+
+	resources := make(map[string][]string)
+	var total int
+	for _, resource := range arns {
+		parsed, err := arn.Parse(resource)
+		if err != nil || parsed.Service != "iam" {
+			a.Logger().Warn("Skipping resource which is not an IAM resource", log.String("arn", resource))
+			continue
+		}
+		resourceType, _, _ := strings.Cut(parsed.Resource, "/")
+		switch resourceType {
+		case "policy", "role", "user", "group":
+			resources[resourceType] = append(resources[resourceType], resource)
+			total++
+		default:
+			a.Logger().Warn("Skipping IAM resource which can not be scanned individually", log.String("arn", resource))
+		}
+	}
+
+	a.Tracker().SetTotalResources(total)
+	a.Tracker().SetServiceLabel("Adapting IAM resources...")
+
+	state.AWS.IAM.Policies = concurrency.Adapt(resources["policy"], a.RootAdapter, func(ctx context.Context, resource string) (*iam.Policy, error) {
+		output, err := a.api.GetPolicy(ctx, &iamapi.GetPolicyInput{
+			PolicyArn: awssdk.String(resource),
+		})
+		if err != nil || output.Policy == nil {
+			return nil, a.ignoreNotFound(resource, err)
+		}
+		return a.adaptPolicy(ctx, *output.Policy)
+	})
+
+	state.AWS.IAM.Roles = concurrency.Adapt(resources["role"], a.RootAdapter, func(ctx context.Context, resource string) (*iam.Role, error) {
+		output, err := a.api.GetRole(ctx, &iamapi.GetRoleInput{
+			RoleName: awssdk.String(resourceName(resource)),
+		})
+		if err != nil || output.Role == nil {
+			return nil, a.ignoreNotFound(resource, err)
+		}
+		return a.adaptRole(ctx, *output.Role)
+	})
+
+	state.AWS.IAM.Users = concurrency.Adapt(resources["user"], a.RootAdapter, func(ctx context.Context, resource string) (*iam.User, error) {
+		output, err := a.api.GetUser(ctx, &iamapi.GetUserInput{
+			UserName: awssdk.String(resourceName(resource)),
+		})
+		if err != nil || output.User == nil {
+			return nil, a.ignoreNotFound(resource, err)
+		}
+		return a.adaptUser(ctx, *output.User)
+	})
+
+	state.AWS.IAM.Groups = concurrency.Adapt(resources["group"], a.RootAdapter, func(ctx context.Context, resource string) (*iam.Group, error) {
+		output, err := a.api.GetGroup(ctx, &iamapi.GetGroupInput{
+			GroupName: awssdk.String(resourceName(resource)),
+		})
+		if err != nil || output.Group == nil {
+			return nil, a.ignoreNotFound(resource, err)
+		}
+		return a.adaptGroup(ctx, *output.Group, state)
+	})
+
+	return nil
+}
+
+// ignoreNotFound records a requested resource which no longer exists, so that it is removed from the cache.
+// Any other error is returned, as a resource which could not be fetched is kept in the cache.
+func (a *adapter) ignoreNotFound(resource string, err error) error {
+	if err != nil && errs.Classify(err) != errs.ClassNotFound {
+		return err
+	}
+	a.RecordNotFound(resource)
+	return nil
+}
+
+// resourceName returns the name of an IAM resource from its ARN, without its path, e.g. "admin" for
+// "arn:aws:iam::123456789012:role/ops/admin"
+func resourceName(resource string) string {
+	parsed, _ := arn.Parse(resource)
+	return path.Base(parsed.Resource)
+}
+
 func (a *adapter) adaptPasswordPolicy(state *state.State) error {
 
 	a.Tracker().SetServiceLabel("Checking password policy...")
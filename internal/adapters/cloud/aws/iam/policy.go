@@ -1,6 +1,7 @@
 package iam
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -42,7 +43,7 @@ func (a *adapter) adaptPolicies(state *state.State) error {
 	return nil
 }
 
-func (a *adapter) adaptPolicy(apiPolicy iamtypes.Policy) (*iam.Policy, error) {
+func (a *adapter) adaptPolicy(ctx context.Context, apiPolicy iamtypes.Policy) (*iam.Policy, error) {
 
 	if apiPolicy.Arn == nil {
 		return nil, fmt.Errorf("policy arn not specified")
@@ -51,7 +52,7 @@ func (a *adapter) adaptPolicy(apiPolicy iamtypes.Policy) (*iam.Policy, error) {
 		return nil, fmt.Errorf("policy name not specified")
 	}
 
-	output, err := a.api.GetPolicyVersion(a.Context(), &iamapi.GetPolicyVersionInput{
+	output, err := a.api.GetPolicyVersion(ctx, &iamapi.GetPolicyVersionInput{
 		PolicyArn: apiPolicy.Arn,
 		VersionId: apiPolicy.DefaultVersionId,
 	})
@@ -78,11 +79,11 @@ func (a *adapter) adaptPolicy(apiPolicy iamtypes.Policy) (*iam.Policy, error) {
 			Metadata: metadata,
 			Parsed:   *document,
 		},
-		Builtin: trivyTypes.Bool(strings.HasPrefix(*apiPolicy.Arn, "arn:aws:iam::aws:"), metadata),
+		Builtin: trivyTypes.Bool(strings.HasPrefix(*apiPolicy.Arn, "arn:"+a.Partition()+":iam::aws:"), metadata),
 	}, nil
 }
 
-func (a *adapter) adaptAttachedPolicy(apiPolicy iamtypes.AttachedPolicy) (*iam.Policy, error) {
+func (a *adapter) adaptAttachedPolicy(ctx context.Context, apiPolicy iamtypes.AttachedPolicy) (*iam.Policy, error) {
 
 	if apiPolicy.PolicyArn == nil {
 		return nil, fmt.Errorf("policy arn not specified")
@@ -91,12 +92,12 @@ func (a *adapter) adaptAttachedPolicy(apiPolicy iamtypes.AttachedPolicy) (*iam.P
 		return nil, fmt.Errorf("policy name not specified")
 	}
 
-	policyOutput, err := a.api.GetPolicy(a.Context(), &iamapi.GetPolicyInput{
+	policyOutput, err := a.api.GetPolicy(ctx, &iamapi.GetPolicyInput{
 		PolicyArn: apiPolicy.PolicyArn,
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	return a.adaptPolicy(*policyOutput.Policy)
+	return a.adaptPolicy(ctx, *policyOutput.Policy)
 }
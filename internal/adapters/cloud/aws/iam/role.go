@@ -1,6 +1,7 @@
 package iam
 
 import (
+	"context"
 	"fmt"
 
 	iamapi "github.com/aws/aws-sdk-go-v2/service/iam"
@@ -39,7 +40,7 @@ func (a *adapter) adaptRoles(state *state.State) error {
 	return nil
 }
 
-func (a *adapter) adaptRole(apiRole iamtypes.Role) (*iam.Role, error) {
+func (a *adapter) adaptRole(ctx context.Context, apiRole iamtypes.Role) (*iam.Role, error) {
 
 	if apiRole.Arn == nil {
 		return nil, fmt.Errorf("role arn not specified")
@@ -54,7 +55,7 @@ func (a *adapter) adaptRole(apiRole iamtypes.Role) (*iam.Role, error) {
 		RoleName: apiRole.RoleName,
 	}
 	for {
-		policiesOutput, err := a.api.ListAttachedRolePolicies(a.Context(), input)
+		policiesOutput, err := a.api.ListAttachedRolePolicies(ctx, input)
 		if err != nil {
 			a.Logger().Error("Failed to locate policies attached to role",
 				log.String("name", *apiRole.RoleName), log.Err(err))
@@ -62,7 +63,7 @@ func (a *adapter) adaptRole(apiRole iamtypes.Role) (*iam.Role, error) {
 		}
 
 		for _, apiPolicy := range policiesOutput.AttachedPolicies {
-			policy, err := a.adaptAttachedPolicy(apiPolicy)
+			policy, err := a.adaptAttachedPolicy(ctx, apiPolicy)
 			if err != nil {
 				a.Logger().Error("Failed to adapt policy attached to role",
 					log.String("name", *apiRole.RoleName), log.Err(err))
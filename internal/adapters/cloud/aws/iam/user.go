@@ -1,6 +1,7 @@
 package iam
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -41,14 +42,14 @@ func (a *adapter) adaptUsers(state *state.State) error {
 	return nil
 }
 
-func (a *adapter) getMFADevices(user iamtypes.User) ([]iam.MFADevice, error) {
+func (a *adapter) getMFADevices(ctx context.Context, user iamtypes.User) ([]iam.MFADevice, error) {
 	input := &iamapi.ListMFADevicesInput{
 		Marker:   nil,
 		UserName: user.UserName,
 	}
 	var apiDevices []iamtypes.MFADevice
 	for {
-		output, err := a.api.ListMFADevices(a.Context(), input)
+		output, err := a.api.ListMFADevices(ctx, input)
 		if err != nil {
 			return nil, err
 		}
@@ -76,13 +77,13 @@ func (a *adapter) getMFADevices(user iamtypes.User) ([]iam.MFADevice, error) {
 	return devices, nil
 }
 
-func (a *adapter) getUserPolicies(apiUser iamtypes.User) []iam.Policy {
+func (a *adapter) getUserPolicies(ctx context.Context, apiUser iamtypes.User) []iam.Policy {
 	var policies []iam.Policy
 	input := &iamapi.ListAttachedUserPoliciesInput{
 		UserName: apiUser.UserName,
 	}
 	for {
-		policiesOutput, err := a.api.ListAttachedUserPolicies(a.Context(), input)
+		policiesOutput, err := a.api.ListAttachedUserPolicies(ctx, input)
 		if err != nil {
 			a.Logger().Error("Failed to locate policies attached to user",
 				log.String("name", *apiUser.UserName), log.Err(err))
@@ -90,7 +91,7 @@ func (a *adapter) getUserPolicies(apiUser iamtypes.User) []iam.Policy {
 		}
 
 		for _, apiPolicy := range policiesOutput.AttachedPolicies {
-			policy, err := a.adaptAttachedPolicy(apiPolicy)
+			policy, err := a.adaptAttachedPolicy(ctx, apiPolicy)
 			if err != nil {
 				a.Logger().Error("Failed to adapt policy attached to user",
 					log.String("name", *apiUser.UserName), log.Err(err))
@@ -107,7 +108,7 @@ func (a *adapter) getUserPolicies(apiUser iamtypes.User) []iam.Policy {
 	return policies
 }
 
-func (a *adapter) getUserKeys(apiUser iamtypes.User) ([]iam.AccessKey, error) {
+func (a *adapter) getUserKeys(ctx context.Context, apiUser iamtypes.User) ([]iam.AccessKey, error) {
 
 	var keys []iam.AccessKey
 	metadata := a.CreateMetadataFromARN(*apiUser.Arn)
@@ -115,14 +116,14 @@ func (a *adapter) getUserKeys(apiUser iamtypes.User) ([]iam.AccessKey, error) {
 		UserName: apiUser.UserName,
 	}
 	for {
-		output, err := a.api.ListAccessKeys(a.Context(), &input)
+		output, err := a.api.ListAccessKeys(ctx, &input)
 		if err != nil {
 			return nil, err
 		}
 		for _, apiAccessKey := range output.AccessKeyMetadata {
 
 			lastUsed := trivyTypes.TimeUnresolvable(metadata)
-			if output, err := a.api.GetAccessKeyLastUsed(a.Context(), &iamapi.GetAccessKeyLastUsedInput{
+			if output, err := a.api.GetAccessKeyLastUsed(ctx, &iamapi.GetAccessKeyLastUsedInput{
 				AccessKeyId: apiAccessKey.AccessKeyId,
 			}); err == nil {
 				if output.AccessKeyLastUsed != nil && output.AccessKeyLastUsed.LastUsedDate != nil {
@@ -156,7 +157,7 @@ func (a *adapter) getUserKeys(apiUser iamtypes.User) ([]iam.AccessKey, error) {
 	return keys, nil
 }
 
-func (a *adapter) adaptUser(apiUser iamtypes.User) (*iam.User, error) {
+func (a *adapter) adaptUser(ctx context.Context, apiUser iamtypes.User) (*iam.User, error) {
 
 	if apiUser.Arn == nil {
 		return nil, fmt.Errorf("user arn not specified")
@@ -167,13 +168,13 @@ func (a *adapter) adaptUser(apiUser iamtypes.User) (*iam.User, error) {
 
 	metadata := a.CreateMetadataFromARN(*apiUser.Arn)
 
-	policies := a.getUserPolicies(apiUser)
-	keys, err := a.getUserKeys(apiUser)
+	policies := a.getUserPolicies(ctx, apiUser)
+	keys, err := a.getUserKeys(ctx, apiUser)
 	if err != nil {
 		return nil, err
 	}
 
-	mfaDevices, err := a.getMFADevices(apiUser)
+	mfaDevices, err := a.getMFADevices(ctx, apiUser)
 	if err != nil {
 		return nil, err
 	}
@@ -1,6 +1,8 @@
 package kinesis
 
 import (
+	"context"
+
 	api "github.com/aws/aws-sdk-go-v2/service/kinesis"
 
 	"github.com/aquasecurity/trivy-aws/internal/adapters/cloud/aws"
@@ -27,6 +29,13 @@ func (a *adapter) Name() string {
 	return "kinesis"
 }
 
+func (a *adapter) Permissions() []string {
+	return []string{
+		"kinesis:DescribeStream",
+		"kinesis:ListStreams",
+	}
+}
+
 func (a *adapter) Adapt(root *aws.RootAdapter, state *state.State) error {
 
 	a.RootAdapter = root
@@ -64,9 +73,9 @@ func (a *adapter) getStreams() ([]kinesis.Stream, error) {
 	return concurrency.Adapt(apiStreams, a.RootAdapter, a.adaptStream), nil
 }
 
-func (a *adapter) adaptStream(streamName string) (*kinesis.Stream, error) {
+func (a *adapter) adaptStream(ctx context.Context, streamName string) (*kinesis.Stream, error) {
 
-	output, err := a.api.DescribeStream(a.Context(), &api.DescribeStreamInput{
+	output, err := a.api.DescribeStream(ctx, &api.DescribeStreamInput{
 		StreamName:            &streamName,
 		ExclusiveStartShardId: nil,
 		Limit:                 nil,
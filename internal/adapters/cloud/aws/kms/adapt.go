@@ -1,6 +1,8 @@
 package kms
 
 import (
+	"context"
+
 	api "github.com/aws/aws-sdk-go-v2/service/kms"
 	"github.com/aws/aws-sdk-go-v2/service/kms/types"
 
@@ -28,6 +30,13 @@ func (a *adapter) Name() string {
 	return "kms"
 }
 
+func (a *adapter) Permissions() []string {
+	return []string{
+		"kms:DescribeKey",
+		"kms:ListKeys",
+	}
+}
+
 func (a *adapter) Adapt(root *aws.RootAdapter, state *state.State) error {
 
 	a.RootAdapter = root
@@ -65,11 +74,11 @@ func (a *adapter) getKeys() ([]kms.Key, error) {
 	return concurrency.Adapt(apiKeys, a.RootAdapter, a.adaptKey), nil
 }
 
-func (a *adapter) adaptKey(apiKey types.KeyListEntry) (*kms.Key, error) {
+func (a *adapter) adaptKey(ctx context.Context, apiKey types.KeyListEntry) (*kms.Key, error) {
 
 	metadata := a.CreateMetadataFromARN(*apiKey.KeyArn)
 
-	output, err := a.api.DescribeKey(a.Context(), &api.DescribeKeyInput{
+	output, err := a.api.DescribeKey(ctx, &api.DescribeKeyInput{
 		KeyId: apiKey.KeyId,
 	})
 	if err != nil {
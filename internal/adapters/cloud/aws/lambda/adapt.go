@@ -1,18 +1,22 @@
 package lambda
 
 import (
+	"context"
 	"strings"
 
 	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
 	lambdaapi "github.com/aws/aws-sdk-go-v2/service/lambda"
 	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
 	"github.com/liamg/iamgo"
 
 	"github.com/aquasecurity/trivy-aws/internal/adapters/cloud/aws"
 	"github.com/aquasecurity/trivy-aws/pkg/concurrency"
+	"github.com/aquasecurity/trivy-aws/pkg/errs"
 	"github.com/aquasecurity/trivy/pkg/iac/providers/aws/lambda"
 	"github.com/aquasecurity/trivy/pkg/iac/state"
 	trivyTypes "github.com/aquasecurity/trivy/pkg/iac/types"
+	"github.com/aquasecurity/trivy/pkg/log"
 )
 
 type adapter struct {
@@ -32,6 +36,14 @@ func (a *adapter) Name() string {
 	return "lambda"
 }
 
+func (a *adapter) Permissions() []string {
+	return []string{
+		"lambda:GetFunction",
+		"lambda:GetPolicy",
+		"lambda:ListFunctions",
+	}
+}
+
 func (a *adapter) Adapt(root *aws.RootAdapter, state *state.State) error {
 
 	a.RootAdapter = root
@@ -46,6 +58,40 @@ func (a *adapter) Adapt(root *aws.RootAdapter, state *state.State) error {
 	return nil
 }
 
+func (a *adapter) AdaptResources(root *aws.RootAdapter, state *state.State, arns []string) error {
+
+	a.RootAdapter = root
+	a.api = lambdaapi.NewFromConfig(root.SessionConfig())
+
+	var functionARNs []string
+	for _, resource := range arns {
+		parsed, err := arn.Parse(resource)
+		if err != nil || parsed.Service != "lambda" || !strings.HasPrefix(parsed.Resource, "function:") {
+			a.Logger().Warn("Skipping resource which is not a Lambda function", log.String("arn", resource))
+			continue
+		}
+		functionARNs = append(functionARNs, resource)
+	}
+
+	a.Tracker().SetTotalResources(len(functionARNs))
+	a.Tracker().SetServiceLabel("Adapting functions...")
+	state.AWS.Lambda.Functions = concurrency.Adapt(functionARNs, a.RootAdapter, func(ctx context.Context, functionARN string) (*lambda.Function, error) {
+		// a function which could not be fetched is kept in the cache, unless it no longer exists
+		output, err := a.api.GetFunction(ctx, &lambdaapi.GetFunctionInput{
+			FunctionName: awssdk.String(functionARN),
+		})
+		if err != nil && errs.Classify(err) != errs.ClassNotFound {
+			return nil, err
+		}
+		if err != nil || output.Configuration == nil {
+			a.RecordNotFound(functionARN)
+			return nil, nil
+		}
+		return a.adaptFunction(ctx, *output.Configuration)
+	})
+	return nil
+}
+
 func (a *adapter) getFunctions() ([]lambda.Function, error) {
 
 	a.Tracker().SetServiceLabel(" Discovering functions...")
@@ -73,7 +119,7 @@ func (a *adapter) getFunctions() ([]lambda.Function, error) {
 	return concurrency.Adapt(apiFunctions, a.RootAdapter, a.adaptFunction), nil
 }
 
-func (a *adapter) adaptFunction(function types.FunctionConfiguration) (*lambda.Function, error) {
+func (a *adapter) adaptFunction(ctx context.Context, function types.FunctionConfiguration) (*lambda.Function, error) {
 	metadata := a.CreateMetadataFromARN(*function.FunctionArn)
 	var tracingMode string
 	if function.TracingConfig != nil {
@@ -81,7 +127,7 @@ func (a *adapter) adaptFunction(function types.FunctionConfiguration) (*lambda.F
 	}
 
 	var permissions []lambda.Permission
-	getPolicyResult, err := a.api.GetPolicy(a.Context(), &lambdaapi.GetPolicyInput{
+	getPolicyResult, err := a.api.GetPolicy(ctx, &lambdaapi.GetPolicyInput{
 		FunctionName: function.FunctionName,
 		Qualifier:    function.Version,
 	})
@@ -1,6 +1,8 @@
 package mq
 
 import (
+	"context"
+
 	api "github.com/aws/aws-sdk-go-v2/service/mq"
 	mqTypes "github.com/aws/aws-sdk-go-v2/service/mq/types"
 
@@ -28,6 +30,13 @@ func (a *adapter) Name() string {
 	return "mq"
 }
 
+func (a *adapter) Permissions() []string {
+	return []string{
+		"mq:DescribeBroker",
+		"mq:ListBrokers",
+	}
+}
+
 func (a *adapter) Adapt(root *aws.RootAdapter, state *state.State) error {
 
 	a.RootAdapter = root
@@ -65,11 +74,11 @@ func (a *adapter) getBrokers() ([]mq.Broker, error) {
 	return concurrency.Adapt(apiBrokers, a.RootAdapter, a.adaptBroker), nil
 }
 
-func (a *adapter) adaptBroker(apiBroker mqTypes.BrokerSummary) (*mq.Broker, error) {
+func (a *adapter) adaptBroker(ctx context.Context, apiBroker mqTypes.BrokerSummary) (*mq.Broker, error) {
 
 	metadata := a.CreateMetadataFromARN(*apiBroker.BrokerArn)
 
-	output, err := a.api.DescribeBroker(a.Context(), &api.DescribeBrokerInput{
+	output, err := a.api.DescribeBroker(ctx, &api.DescribeBrokerInput{
 		BrokerId: apiBroker.BrokerId,
 	})
 	if err != nil {
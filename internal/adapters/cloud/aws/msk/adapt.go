@@ -1,6 +1,8 @@
 package msk
 
 import (
+	"context"
+
 	awssdk "github.com/aws/aws-sdk-go-v2/aws"
 	api "github.com/aws/aws-sdk-go-v2/service/kafka"
 	"github.com/aws/aws-sdk-go-v2/service/kafka/types"
@@ -29,6 +31,12 @@ func (a *adapter) Name() string {
 	return "msk"
 }
 
+func (a *adapter) Permissions() []string {
+	return []string{
+		"kafka:ListClusters",
+	}
+}
+
 func (a *adapter) Adapt(root *aws.RootAdapter, state *state.State) error {
 
 	a.RootAdapter = root
@@ -66,7 +74,7 @@ func (a *adapter) getClusters() ([]msk.Cluster, error) {
 	return concurrency.Adapt(apiClusters, a.RootAdapter, a.adaptCluster), nil
 }
 
-func (a *adapter) adaptCluster(apiCluster types.ClusterInfo) (*msk.Cluster, error) {
+func (a *adapter) adaptCluster(ctx context.Context, apiCluster types.ClusterInfo) (*msk.Cluster, error) {
 
 	metadata := a.CreateMetadataFromARN(*apiCluster.ClusterArn)
 
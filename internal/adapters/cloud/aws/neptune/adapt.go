@@ -1,6 +1,8 @@
 package neptune
 
 import (
+	"context"
+
 	api "github.com/aws/aws-sdk-go-v2/service/neptune"
 	neptuneTypes "github.com/aws/aws-sdk-go-v2/service/neptune/types"
 
@@ -29,6 +31,12 @@ func (a *adapter) Name() string {
 	return "neptune"
 }
 
+func (a *adapter) Permissions() []string {
+	return []string{
+		"rds:DescribeDBClusters",
+	}
+}
+
 func (a *adapter) Adapt(root *aws.RootAdapter, state *state.State) error {
 
 	a.RootAdapter = root
@@ -66,7 +74,7 @@ func (a *adapter) getClusters() ([]neptune.Cluster, error) {
 	return concurrency.Adapt(apiClusters, a.RootAdapter, a.adaptCluster), nil
 }
 
-func (a *adapter) adaptCluster(apiCluster neptuneTypes.DBCluster) (*neptune.Cluster, error) {
+func (a *adapter) adaptCluster(ctx context.Context, apiCluster neptuneTypes.DBCluster) (*neptune.Cluster, error) {
 
 	metadata := a.CreateMetadataFromARN(*apiCluster.DBClusterArn)
 
@@ -0,0 +1,148 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamTypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+
+	"github.com/aquasecurity/trivy-aws/internal/adapters/cloud/options"
+	"github.com/aquasecurity/trivy/pkg/log"
+)
+
+// CommonPermissionsService is the service name the permissions required by every scan are reported under
+const CommonPermissionsService = "common"
+
+// PermissionCheck is the outcome of the evaluation of an IAM action required to adapt a service
+type PermissionCheck struct {
+	Service  string `json:"service"`
+	Action   string `json:"action"`
+	Allowed  bool   `json:"allowed"`
+	Decision string `json:"decision"`
+	// Simulated is set when the decision comes from the IAM policy simulator rather than from a call of the action
+	Simulated bool `json:"simulated"`
+}
+
+// CheckPermissions evaluates every IAM action required to adapt the services against the policies of the
+// caller, using the IAM policy simulator so that no resources have to exist for the actions to be checked.
+// The simulation itself requires iam:SimulatePrincipalPolicy, and iam:GetRole for an assumed role.
+//
+// The actions are simulated against every resource, so the decisions only reflect the identity-based policies
+// of the caller, its permissions boundary and the service control policies of the organization. Resource-based
+// policies, such as S3 bucket policies and KMS key policies, and conditions on the context of a request, such
+// as resource tags or the source IP, are not evaluated: an allowed action may still be denied for some resources.
+func CheckPermissions(ctx context.Context, opt options.Options) ([]PermissionCheck, error) {
+	logger := log.WithPrefix("adapt-aws")
+
+	cfg, err := loadConfig(ctx, opt, logger)
+	if err != nil {
+		return nil, err
+	}
+	cfg = withReadOnlyGuard(cfg, &readOnlyGuard{})
+
+	identity, err := sts.NewFromConfig(cfg).GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover AWS caller identity: %w", err)
+	}
+
+	required := map[string][]string{
+		CommonPermissionsService: basePermissions,
+	}
+	var actions []string
+	actions = append(actions, basePermissions...)
+	for _, adapter := range registeredAdapters {
+		if len(opt.Services) != 0 && !slices.Contains(opt.Services, adapter.Name()) {
+			continue
+		}
+		required[adapter.Name()] = adapter.Permissions()
+		actions = append(actions, adapter.Permissions()...)
+	}
+	slices.Sort(actions)
+	actions = slices.Compact(actions)
+
+	decisions, err := simulatePermissions(ctx, iam.NewFromConfig(cfg), aws.ToString(identity.Arn), actions)
+	if err != nil {
+		return nil, err
+	}
+
+	var checks []PermissionCheck
+	for service, serviceActions := range required {
+		for _, action := range serviceActions {
+			decision, ok := decisions[action]
+			if !ok {
+				decision = "notEvaluated"
+			}
+			checks = append(checks, PermissionCheck{
+				Service:   service,
+				Action:    action,
+				Allowed:   decision == string(iamTypes.PolicyEvaluationDecisionTypeAllowed),
+				Decision:  decision,
+				Simulated: true,
+			})
+		}
+	}
+	slices.SortFunc(checks, func(a, b PermissionCheck) int {
+		if a.Service != b.Service {
+			return strings.Compare(a.Service, b.Service)
+		}
+		return strings.Compare(a.Action, b.Action)
+	})
+	return checks, nil
+}
+
+// simulatePermissions returns the decision of the policy simulator for each action, keyed by action
+func simulatePermissions(ctx context.Context, client *iam.Client, callerARN string, actions []string) (map[string]string, error) {
+	logger := log.WithPrefix("adapt-aws")
+
+	decisions := make(map[string]string)
+
+	parsed, err := arn.Parse(callerARN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse caller ARN %q: %w", callerARN, err)
+	}
+
+	var principal string
+	switch {
+	case parsed.Service == "iam" && parsed.Resource == "root":
+		// the root user cannot be restricted by IAM policies
+		for _, action := range actions {
+			decisions[action] = string(iamTypes.PolicyEvaluationDecisionTypeAllowed)
+		}
+		return decisions, nil
+	case parsed.Service == "iam":
+		principal = callerARN
+	case parsed.Service == "sts" && strings.HasPrefix(parsed.Resource, "assumed-role/"):
+		// the policies are attached to the role, whose ARN may include a path which the session ARN lacks
+		roleName := strings.Split(parsed.Resource, "/")[1]
+		role, err := client.GetRole(ctx, &iam.GetRoleInput{RoleName: aws.String(roleName)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get role %s: %w", roleName, err)
+		}
+		principal = aws.ToString(role.Role.Arn)
+	default:
+		return nil, fmt.Errorf("the permissions of %s cannot be simulated", callerARN)
+	}
+
+	logger.Debug("Simulating permissions", log.String("principal", principal), log.Int("actions", len(actions)))
+
+	paginator := iam.NewSimulatePrincipalPolicyPaginator(client, &iam.SimulatePrincipalPolicyInput{
+		PolicySourceArn: aws.String(principal),
+		ActionNames:     actions,
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to simulate the policies of %s: %w", principal, err)
+		}
+		for _, result := range page.EvaluationResults {
+			decisions[aws.ToString(result.EvalActionName)] = string(result.EvalDecision)
+		}
+	}
+	return decisions, nil
+}
@@ -0,0 +1,113 @@
+package aws
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const simulateResponse = `<SimulatePrincipalPolicyResponse xmlns="https://iam.amazonaws.com/doc/2010-05-08/">
+  <SimulatePrincipalPolicyResult>
+    <IsTruncated>false</IsTruncated>
+    <EvaluationResults>
+      <member>
+        <EvalActionName>s3:ListAllMyBuckets</EvalActionName>
+        <EvalDecision>allowed</EvalDecision>
+      </member>
+      <member>
+        <EvalActionName>s3:GetBucketLogging</EvalActionName>
+        <EvalDecision>implicitDeny</EvalDecision>
+      </member>
+    </EvaluationResults>
+  </SimulatePrincipalPolicyResult>
+</SimulatePrincipalPolicyResponse>`
+
+const getRoleResponse = `<GetRoleResponse xmlns="https://iam.amazonaws.com/doc/2010-05-08/">
+  <GetRoleResult>
+    <Role>
+      <RoleName>scanner</RoleName>
+      <Path>/security/</Path>
+      <Arn>arn:aws:iam::123456789012:role/security/scanner</Arn>
+    </Role>
+  </GetRoleResult>
+</GetRoleResponse>`
+
+func Test_simulatePermissions(t *testing.T) {
+	actions := []string{"s3:GetBucketLogging", "s3:ListAllMyBuckets"}
+
+	tests := []struct {
+		name          string
+		callerARN     string
+		wantPrincipal string
+		want          map[string]string
+		wantErr       string
+	}{
+		{
+			name:          "user",
+			callerARN:     "arn:aws:iam::123456789012:user/scanner",
+			wantPrincipal: "arn:aws:iam::123456789012:user/scanner",
+			want: map[string]string{
+				"s3:ListAllMyBuckets": "allowed",
+				"s3:GetBucketLogging": "implicitDeny",
+			},
+		},
+		{
+			name:          "assumed role with a path",
+			callerARN:     "arn:aws:sts::123456789012:assumed-role/scanner/session",
+			wantPrincipal: "arn:aws:iam::123456789012:role/security/scanner",
+			want: map[string]string{
+				"s3:ListAllMyBuckets": "allowed",
+				"s3:GetBucketLogging": "implicitDeny",
+			},
+		},
+		{
+			name:      "root",
+			callerARN: "arn:aws:iam::123456789012:root",
+			want: map[string]string{
+				"s3:ListAllMyBuckets": "allowed",
+				"s3:GetBucketLogging": "allowed",
+			},
+		},
+		{
+			name:      "federated user",
+			callerARN: "arn:aws:sts::123456789012:federated-user/scanner",
+			wantErr:   "cannot be simulated",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var principal string
+			cfg := stubConfig(func(req *http.Request) (int, string) {
+				body, err := io.ReadAll(req.Body)
+				require.NoError(t, err)
+				form, err := url.ParseQuery(string(body))
+				require.NoError(t, err)
+
+				switch form.Get("Action") {
+				case "GetRole":
+					return http.StatusOK, getRoleResponse
+				case "SimulatePrincipalPolicy":
+					principal = form.Get("PolicySourceArn")
+					return http.StatusOK, simulateResponse
+				}
+				return http.StatusBadRequest, ""
+			})
+
+			got, err := simulatePermissions(context.Background(), iam.NewFromConfig(cfg), test.callerARN, actions)
+			if test.wantErr != "" {
+				require.ErrorContains(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+			assert.Equal(t, test.wantPrincipal, principal)
+		})
+	}
+}
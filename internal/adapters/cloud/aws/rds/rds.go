@@ -1,12 +1,17 @@
 package rds
 
 import (
+	"context"
+	"strings"
+
 	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
 	rdsApi "github.com/aws/aws-sdk-go-v2/service/rds"
 	rdsTypes "github.com/aws/aws-sdk-go-v2/service/rds/types"
 
 	"github.com/aquasecurity/trivy-aws/internal/adapters/cloud/aws"
 	"github.com/aquasecurity/trivy-aws/pkg/concurrency"
+	"github.com/aquasecurity/trivy-aws/pkg/errs"
 	"github.com/aquasecurity/trivy-aws/pkg/types"
 	"github.com/aquasecurity/trivy/pkg/iac/providers/aws/rds"
 	"github.com/aquasecurity/trivy/pkg/iac/state"
@@ -27,6 +32,18 @@ func (a *adapter) Name() string {
 	return "rds"
 }
 
+func (a *adapter) Permissions() []string {
+	return []string{
+		"rds:DescribeDBClusters",
+		"rds:DescribeDBInstances",
+		"rds:DescribeDBParameterGroups",
+		"rds:DescribeDBParameters",
+		"rds:DescribeDBSecurityGroups",
+		"rds:DescribeDBSnapshotAttributes",
+		"rds:DescribeDBSnapshots",
+	}
+}
+
 func (a *adapter) Provider() string {
 	return "aws"
 }
@@ -66,6 +83,102 @@ func (a *adapter) Adapt(root *aws.RootAdapter, state *state.State) error {
 	return nil
 }
 
+// AdaptResources adapts the requested instances, clusters, snapshots and parameter groups. Other RDS
+// resources, such as the classic security groups, are not supported.
+func (a *adapter) AdaptResources(root *aws.RootAdapter, state *state.State, arns []string) error {
+
+	a.RootAdapter = root
+	a.api = rdsApi.NewFromConfig(root.SessionConfig())
+
+	var instances, clusters, snapshots, parameterGroups []string
+	for _, resource := range arns {
+		parsed, err := arn.Parse(resource)
+		if err != nil || parsed.Service != "rds" {
+			a.Logger().Warn("Skipping resource which is not an RDS resource", log.String("arn", resource))
+			continue
+		}
+		switch resourceType, _, _ := strings.Cut(parsed.Resource, ":"); resourceType {
+		case "db":
+			instances = append(instances, resource)
+		case "cluster":
+			clusters = append(clusters, resource)
+		case "snapshot":
+			snapshots = append(snapshots, resource)
+		case "pg":
+			parameterGroups = append(parameterGroups, resource)
+		default:
+			a.Logger().Warn("Skipping RDS resource which can not be scanned individually", log.String("arn", resource))
+		}
+	}
+
+	a.Tracker().SetTotalResources(len(instances) + len(clusters) + len(snapshots) + len(parameterGroups))
+	a.Tracker().SetServiceLabel("Adapting RDS resources...")
+
+	state.AWS.RDS.Instances = concurrency.Adapt(instances, a.RootAdapter, func(ctx context.Context, resource string) (*rds.Instance, error) {
+		output, err := a.api.DescribeDBInstances(ctx, &rdsApi.DescribeDBInstancesInput{
+			DBInstanceIdentifier: awssdk.String(resource),
+		})
+		if err != nil || len(output.DBInstances) == 0 {
+			return nil, a.ignoreNotFound(resource, err)
+		}
+		return a.adaptDBInstance(ctx, output.DBInstances[0])
+	})
+
+	state.AWS.RDS.Clusters = concurrency.Adapt(clusters, a.RootAdapter, func(ctx context.Context, resource string) (*rds.Cluster, error) {
+		output, err := a.api.DescribeDBClusters(ctx, &rdsApi.DescribeDBClustersInput{
+			DBClusterIdentifier: awssdk.String(resource),
+		})
+		if err != nil || len(output.DBClusters) == 0 {
+			return nil, a.ignoreNotFound(resource, err)
+		}
+		return a.adaptCluster(ctx, output.DBClusters[0])
+	})
+
+	state.AWS.RDS.Snapshots = concurrency.Adapt(snapshots, a.RootAdapter, func(ctx context.Context, resource string) (*rds.Snapshots, error) {
+		input := &rdsApi.DescribeDBSnapshotsInput{
+			DBSnapshotIdentifier: awssdk.String(resourceName(resource)),
+		}
+		// automated snapshots are only described when their type is given
+		if strings.HasPrefix(*input.DBSnapshotIdentifier, "rds:") {
+			input.SnapshotType = awssdk.String("automated")
+		}
+		output, err := a.api.DescribeDBSnapshots(ctx, input)
+		if err != nil || len(output.DBSnapshots) == 0 {
+			return nil, a.ignoreNotFound(resource, err)
+		}
+		return a.adaptDBSnapshots(ctx, output.DBSnapshots[0])
+	})
+
+	state.AWS.RDS.ParameterGroups = concurrency.Adapt(parameterGroups, a.RootAdapter, func(ctx context.Context, resource string) (*rds.ParameterGroups, error) {
+		output, err := a.api.DescribeDBParameterGroups(ctx, &rdsApi.DescribeDBParameterGroupsInput{
+			DBParameterGroupName: awssdk.String(resourceName(resource)),
+		})
+		if err != nil || len(output.DBParameterGroups) == 0 {
+			return nil, a.ignoreNotFound(resource, err)
+		}
+		return a.adaptParameterGroup(ctx, output.DBParameterGroups[0])
+	})
+
+	return nil
+}
+
+// ignoreNotFound records a requested resource which no longer exists, so that it is removed from the cache.
+// Any other error is returned, as a resource which could not be fetched is kept in the cache.
+func (a *adapter) ignoreNotFound(resource string, err error) error {
+	if err != nil && errs.Classify(err) != errs.ClassNotFound {
+		return err
+	}
+	a.RecordNotFound(resource)
+	return nil
+}
+
+// resourceName returns the name of an RDS resource from its ARN, e.g. "my-group" for "arn:aws:rds:us-east-1:123456789012:pg:my-group"
+func resourceName(resource string) string {
+	parsed, _ := arn.Parse(resource)
+	_, name, _ := strings.Cut(parsed.Resource, ":")
+	return name
+}
+
 func (a *adapter) getSnapshots() (snapshots []rds.Snapshots, err error) {
 	a.Tracker().SetServiceLabel("Discovering Snapshots...")
 	var apiDBSnapshots []rdsTypes.DBSnapshot
@@ -184,7 +297,7 @@ func (a *adapter) getClassic() (rds.Classic, error) {
 	return classic, nil
 }
 
-func (a *adapter) adaptDBInstance(dbInstance rdsTypes.DBInstance) (*rds.Instance, error) {
+func (a *adapter) adaptDBInstance(ctx context.Context, dbInstance rdsTypes.DBInstance) (*rds.Instance, error) {
 
 	metadata := a.CreateMetadata("db:" + awssdk.ToString(dbInstance.DBInstanceIdentifier))
 
@@ -242,7 +355,7 @@ func (a *adapter) adaptDBInstance(dbInstance rdsTypes.DBInstance) (*rds.Instance
 	return instance, nil
 }
 
-func (a *adapter) adaptCluster(dbCluster rdsTypes.DBCluster) (*rds.Cluster, error) {
+func (a *adapter) adaptCluster(ctx context.Context, dbCluster rdsTypes.DBCluster) (*rds.Cluster, error) {
 
 	dbClusterMetadata := a.CreateMetadata("cluster:" + awssdk.ToString(dbCluster.DBClusterIdentifier))
 
@@ -276,11 +389,11 @@ func (a *adapter) adaptCluster(dbCluster rdsTypes.DBCluster) (*rds.Cluster, erro
 	return cluster, nil
 }
 
-func (a *adapter) adaptParameterGroup(dbParameterGroup rdsTypes.DBParameterGroup) (*rds.ParameterGroups, error) {
+func (a *adapter) adaptParameterGroup(ctx context.Context, dbParameterGroup rdsTypes.DBParameterGroup) (*rds.ParameterGroups, error) {
 
-	metadata := a.CreateMetadata("dbparametergroup:" + awssdk.ToString(dbParameterGroup.DBParameterGroupArn))
+	metadata := a.CreateMetadataFromARN(awssdk.ToString(dbParameterGroup.DBParameterGroupArn))
 	var parameter []rds.Parameters
-	output, err := a.api.DescribeDBParameters(a.Context(), &rdsApi.DescribeDBParametersInput{
+	output, err := a.api.DescribeDBParameters(ctx, &rdsApi.DescribeDBParametersInput{
 		DBParameterGroupName: dbParameterGroup.DBParameterGroupName,
 	})
 	if err != nil {
@@ -305,11 +418,11 @@ func (a *adapter) adaptParameterGroup(dbParameterGroup rdsTypes.DBParameterGroup
 
 }
 
-func (a *adapter) adaptDBSnapshots(dbSnapshots rdsTypes.DBSnapshot) (*rds.Snapshots, error) {
-	metadata := a.CreateMetadata("dbsnapshots" + awssdk.ToString(dbSnapshots.DBSnapshotArn))
+func (a *adapter) adaptDBSnapshots(ctx context.Context, dbSnapshots rdsTypes.DBSnapshot) (*rds.Snapshots, error) {
+	metadata := a.CreateMetadataFromARN(awssdk.ToString(dbSnapshots.DBSnapshotArn))
 
 	var SnapshotAttributes []rds.DBSnapshotAttributes
-	output, err := a.api.DescribeDBSnapshotAttributes(a.Context(), &rdsApi.DescribeDBSnapshotAttributesInput{
+	output, err := a.api.DescribeDBSnapshotAttributes(ctx, &rdsApi.DescribeDBSnapshotAttributesInput{
 		DBSnapshotIdentifier: dbSnapshots.DBSnapshotIdentifier,
 	})
 	if err != nil {
@@ -349,7 +462,7 @@ func (a *adapter) adaptDBSnapshots(dbSnapshots rdsTypes.DBSnapshot) (*rds.Snapsh
 	return snapshots, nil
 }
 
-func (a *adapter) adaptClassic(dbSecurityGroup rdsTypes.DBSecurityGroup) (*rds.DBSecurityGroup, error) {
+func (a *adapter) adaptClassic(ctx context.Context, dbSecurityGroup rdsTypes.DBSecurityGroup) (*rds.DBSecurityGroup, error) {
 
 	dbSecurityGroupMetadata := a.CreateMetadata("secgrp:" + awssdk.ToString(dbSecurityGroup.DBSecurityGroupName))
 
@@ -0,0 +1,88 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/middleware"
+
+	"github.com/aquasecurity/trivy/pkg/log"
+)
+
+// readOnlyPrefixes are the prefixes of the names of the operations which only read the state of an account
+var readOnlyPrefixes = []string{"Describe", "List", "Get", "BatchGet"}
+
+// readOnlyExceptions are the operations which don't follow the naming of read-only operations, but don't
+// change any resources either
+var readOnlyExceptions = []string{
+	// creates the credential report of IAM, which is read afterwards
+	"GenerateCredentialReport",
+	// evaluates the policies of the caller for the preflight check
+	"SimulatePrincipalPolicy",
+}
+
+// isReadOnly returns whether the operation only reads the state of an account
+func isReadOnly(operation string) bool {
+	if slices.Contains(readOnlyExceptions, operation) {
+		return true
+	}
+	for _, prefix := range readOnlyPrefixes {
+		if strings.HasPrefix(operation, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// readOnlyGuard records the operations it refused to call, so that a scan which attempted to change an
+// account fails even if the adapter ignored the error of the call
+type readOnlyGuard struct {
+	mu       sync.Mutex
+	rejected []string
+}
+
+func (g *readOnlyGuard) reject(operation string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !slices.Contains(g.rejected, operation) {
+		g.rejected = append(g.rejected, operation)
+	}
+}
+
+// err returns an error listing the refused operations, or nil if none were refused
+func (g *readOnlyGuard) err() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.rejected) == 0 {
+		return nil
+	}
+	rejected := slices.Sorted(slices.Values(g.rejected))
+	return fmt.Errorf("refused to call operations which are not read-only: %s", strings.Join(rejected, ", "))
+}
+
+// withReadOnlyGuard returns a copy of the config which refuses to call any operation which is not known to
+// be read-only, so that the scanner can never change an account, whatever an adapter attempts to call
+func withReadOnlyGuard(cfg aws.Config, guard *readOnlyGuard) aws.Config {
+	cfg = cfg.Copy()
+	cfg.APIOptions = append(slices.Clone(cfg.APIOptions), func(stack *middleware.Stack) error {
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("ReadOnlyGuard",
+			func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+				operation := awsmiddleware.GetOperationName(ctx)
+				if !isReadOnly(operation) {
+					serviceID := awsmiddleware.GetServiceID(ctx)
+					log.WithPrefix("adapt-aws").Error("Refused to call an operation which is not read-only",
+						log.String("service", serviceID), log.String("operation", operation))
+					guard.reject(serviceID + "." + operation)
+					return middleware.InitializeOutput{}, middleware.Metadata{},
+						fmt.Errorf("refused to call %s.%s: only read-only operations are allowed", serviceID, operation)
+				}
+				return next.HandleInitialize(ctx, in)
+			}), middleware.After)
+	})
+	return cfg
+}
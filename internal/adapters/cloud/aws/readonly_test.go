@@ -0,0 +1,87 @@
+package aws
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/trivy/pkg/iac/state"
+)
+
+func Test_isReadOnly(t *testing.T) {
+	tests := []struct {
+		operation string
+		want      bool
+	}{
+		{operation: "DescribeInstances", want: true},
+		{operation: "ListBuckets", want: true},
+		{operation: "GetBucketPolicy", want: true},
+		{operation: "BatchGetProjects", want: true},
+		{operation: "GenerateCredentialReport", want: true},
+		{operation: "SimulatePrincipalPolicy", want: true},
+		{operation: "PutBucketPolicy", want: false},
+		{operation: "DeleteBucket", want: false},
+		{operation: "CreateRole", want: false},
+		{operation: "", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.operation, func(t *testing.T) {
+			assert.Equal(t, tt.want, isReadOnly(tt.operation))
+		})
+	}
+}
+
+func Test_ReadOnlyGuard(t *testing.T) {
+	var calls []string
+	cfg := stubConfig(func(req *http.Request) (int, string) {
+		calls = append(calls, req.Method+" "+req.URL.RawQuery)
+		return http.StatusOK, `<GetBucketPolicyOutput></GetBucketPolicyOutput>`
+	})
+
+	guard := &readOnlyGuard{}
+	client := s3.NewFromConfig(withReadOnlyGuard(cfg, guard), func(o *s3.Options) {
+		o.UsePathStyle = true
+	})
+
+	_, err := client.GetBucketPolicy(context.Background(), &s3.GetBucketPolicyInput{Bucket: aws.String("bucket")})
+	require.NoError(t, err)
+	require.NoError(t, guard.err())
+
+	_, err = client.DeleteBucketPolicy(context.Background(), &s3.DeleteBucketPolicyInput{Bucket: aws.String("bucket")})
+	require.ErrorContains(t, err, "refused to call S3.DeleteBucketPolicy")
+	_, err = client.PutBucketPolicy(context.Background(), &s3.PutBucketPolicyInput{Bucket: aws.String("bucket"), Policy: aws.String("{}")})
+	require.Error(t, err)
+
+	// the refused calls never reach AWS
+	assert.Equal(t, []string{"GET policy="}, calls)
+	assert.EqualError(t, guard.err(), "refused to call operations which are not read-only: S3.DeleteBucketPolicy, S3.PutBucketPolicy")
+}
+
+// deletingAdapter attempts to delete a bucket, ignoring the error of the call
+type deletingAdapter struct{}
+
+func (deletingAdapter) Name() string          { return "deleting" }
+func (deletingAdapter) Provider() string      { return "aws" }
+func (deletingAdapter) Permissions() []string { return nil }
+
+func (deletingAdapter) Adapt(root *RootAdapter, _ *state.State) error {
+	client := s3.NewFromConfig(root.SessionConfig())
+	_, _ = client.DeleteBucket(root.Context(), &s3.DeleteBucketInput{Bucket: aws.String("bucket")})
+	return nil
+}
+
+func Test_AdaptRefusesWrites(t *testing.T) {
+	withAdapters(t, deletingAdapter{})
+
+	stub := &stubAWS{}
+	err := Adapt(context.Background(), &state.State{}, stub.options())
+
+	// the scan fails although the adapter ignored the refusal, and the call never reaches AWS
+	require.EqualError(t, err, "refused to call operations which are not read-only: S3.DeleteBucket")
+	assert.Equal(t, []string{"GetCallerIdentity"}, stub.calls())
+}
@@ -1,6 +1,7 @@
 package redshift
 
 import (
+	"context"
 	"strings"
 
 	awssdk "github.com/aws/aws-sdk-go-v2/aws"
@@ -33,6 +34,17 @@ func (a *adapter) Name() string {
 	return "redshift"
 }
 
+func (a *adapter) Permissions() []string {
+	return []string{
+		"redshift:DescribeClusterParameterGroups",
+		"redshift:DescribeClusterParameters",
+		"redshift:DescribeClusterSecurityGroups",
+		"redshift:DescribeClusters",
+		"redshift:DescribeLoggingStatus",
+		"redshift:DescribeReservedNodes",
+	}
+}
+
 func (a *adapter) Adapt(root *aws.RootAdapter, state *state.State) error {
 
 	a.RootAdapter = root
@@ -87,11 +99,11 @@ func (a *adapter) getClusters() ([]redshift.Cluster, error) {
 	return concurrency.Adapt(apiClusters, a.RootAdapter, a.adaptCluster), nil
 }
 
-func (a *adapter) adaptCluster(apiCluster redshiftTypes.Cluster) (*redshift.Cluster, error) {
+func (a *adapter) adaptCluster(ctx context.Context, apiCluster redshiftTypes.Cluster) (*redshift.Cluster, error) {
 
 	metadata := a.CreateMetadataFromARN(awssdk.ToString(apiCluster.ClusterNamespaceArn))
 
-	output, err := a.api.DescribeLoggingStatus(a.Context(), &api.DescribeLoggingStatusInput{
+	output, err := a.api.DescribeLoggingStatus(ctx, &api.DescribeLoggingStatusInput{
 		ClusterIdentifier: apiCluster.ClusterIdentifier,
 	})
 
@@ -147,7 +159,7 @@ func (a *adapter) getSecurityGroups() ([]redshift.SecurityGroup, error) {
 	return concurrency.Adapt(apiGroups, a.RootAdapter, a.adaptSecurityGroup), nil
 }
 
-func (a *adapter) adaptSecurityGroup(apiSG redshiftTypes.ClusterSecurityGroup) (*redshift.SecurityGroup, error) {
+func (a *adapter) adaptSecurityGroup(ctx context.Context, apiSG redshiftTypes.ClusterSecurityGroup) (*redshift.SecurityGroup, error) {
 
 	metadata := a.CreateMetadata("securitygroup:" + awssdk.ToString(apiSG.ClusterSecurityGroupName))
 
@@ -180,7 +192,7 @@ func (a *adapter) getReservedNodes() ([]redshift.ReservedNode, error) {
 	return concurrency.Adapt(apiReservednodes, a.RootAdapter, a.adaptnode), nil
 }
 
-func (a *adapter) adaptnode(node redshiftTypes.ReservedNode) (*redshift.ReservedNode, error) {
+func (a *adapter) adaptnode(ctx context.Context, node redshiftTypes.ReservedNode) (*redshift.ReservedNode, error) {
 	metadata := a.CreateMetadata(awssdk.ToString(node.ReservedNodeId))
 	return &redshift.ReservedNode{
 		Metadata: metadata,
@@ -221,7 +233,7 @@ func (a *adapter) getParameters() ([]redshift.ClusterParameter, error) {
 	return concurrency.Adapt(apiClusters, a.RootAdapter, a.adaptParameter), nil
 }
 
-func (a *adapter) adaptParameter(parameter redshiftTypes.Parameter) (*redshift.ClusterParameter, error) {
+func (a *adapter) adaptParameter(ctx context.Context, parameter redshiftTypes.Parameter) (*redshift.ClusterParameter, error) {
 
 	metadata := a.CreateMetadata(awssdk.ToString(parameter.ParameterName))
 
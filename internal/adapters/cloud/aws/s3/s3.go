@@ -1,15 +1,19 @@
 package s3
 
 import (
+	"context"
 	"strings"
 
 	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
 	s3api "github.com/aws/aws-sdk-go-v2/service/s3"
 	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
 
 	"github.com/aquasecurity/iamgo"
 	"github.com/aquasecurity/trivy-aws/internal/adapters/cloud/aws"
 	"github.com/aquasecurity/trivy-aws/pkg/concurrency"
+	"github.com/aquasecurity/trivy-aws/pkg/errs"
+	"github.com/aquasecurity/trivy-aws/pkg/partition"
 	"github.com/aquasecurity/trivy-aws/pkg/types"
 	"github.com/aquasecurity/trivy/pkg/iac/providers/aws/iam"
 	"github.com/aquasecurity/trivy/pkg/iac/providers/aws/s3"
@@ -40,6 +44,23 @@ func (a *adapter) Name() string {
 	return "s3"
 }
 
+func (a *adapter) Permissions() []string {
+	return []string{
+		"s3:GetAccelerateConfiguration",
+		"s3:GetBucketAcl",
+		"s3:GetBucketLocation",
+		"s3:GetBucketLogging",
+		"s3:GetBucketPolicy",
+		"s3:GetBucketPublicAccessBlock",
+		"s3:GetBucketVersioning",
+		"s3:GetBucketWebsite",
+		"s3:GetEncryptionConfiguration",
+		"s3:GetLifecycleConfiguration",
+		"s3:ListAllMyBuckets",
+		"s3:ListBucket",
+	}
+}
+
 func (a *adapter) Adapt(root *aws.RootAdapter, state *state.State) error {
 
 	a.RootAdapter = root
@@ -54,6 +75,41 @@ func (a *adapter) Adapt(root *aws.RootAdapter, state *state.State) error {
 	return nil
 }
 
+func (a *adapter) AdaptResources(root *aws.RootAdapter, state *state.State, arns []string) error {
+
+	a.RootAdapter = root
+	a.api = s3api.NewFromConfig(root.SessionConfig())
+
+	var apiBuckets []s3types.Bucket
+	bucketARNs := make(map[string]string)
+	for _, resource := range arns {
+		parsed, err := arn.Parse(resource)
+		if err != nil || parsed.Service != "s3" || strings.Contains(parsed.Resource, "/") {
+			a.Logger().Warn("Skipping resource which is not an S3 bucket", log.String("arn", resource))
+			continue
+		}
+		apiBuckets = append(apiBuckets, s3types.Bucket{Name: awssdk.String(parsed.Resource)})
+		bucketARNs[parsed.Resource] = resource
+	}
+
+	a.Tracker().SetTotalResources(len(apiBuckets))
+	a.Tracker().SetServiceLabel("Adapting buckets...")
+	state.AWS.S3.Buckets = concurrency.Adapt(apiBuckets, a.RootAdapter, func(ctx context.Context, bucket s3types.Bucket) (*s3.Bucket, error) {
+		// unlike a discovered bucket, a requested bucket which cannot be located is kept in the cache,
+		// unless it no longer exists
+		region, err := a.bucketRegion(ctx, bucket.Name)
+		if err != nil {
+			if errs.Classify(err) == errs.ClassNotFound {
+				a.RecordNotFound(bucketARNs[*bucket.Name])
+				return nil, nil
+			}
+			return nil, err
+		}
+		return a.adaptBucketInRegion(ctx, bucket, region)
+	})
+	return nil
+}
+
 func (a *adapter) getBuckets() (buckets []s3.Bucket, err error) {
 	a.Tracker().SetServiceLabel("Discovering buckets...")
 	apiBuckets, err := a.api.ListBuckets(a.Context(), &s3api.ListBucketsInput{})
@@ -66,23 +122,37 @@ func (a *adapter) getBuckets() (buckets []s3.Bucket, err error) {
 	return concurrency.Adapt(apiBuckets.Buckets, a.RootAdapter, a.adaptBucket), nil
 }
 
-func (a *adapter) adaptBucket(bucket s3types.Bucket) (*s3.Bucket, error) {
+func (a *adapter) adaptBucket(ctx context.Context, bucket s3types.Bucket) (*s3.Bucket, error) {
 
 	if bucket.Name == nil {
 		return nil, nil
 	}
 
-	location, err := a.api.GetBucketLocation(a.Context(), &s3api.GetBucketLocationInput{
-		Bucket: bucket.Name,
-	})
+	region, err := a.bucketRegion(ctx, bucket.Name)
 	if err != nil {
 		a.Logger().Error("Error getting bucket location", log.Err(err))
 		return nil, nil
 	}
+	return a.adaptBucketInRegion(ctx, bucket, region)
+}
+
+// bucketRegion returns the region the bucket is located in
+func (a *adapter) bucketRegion(ctx context.Context, bucketName *string) (string, error) {
+	location, err := a.api.GetBucketLocation(ctx, &s3api.GetBucketLocationInput{
+		Bucket: bucketName,
+	})
+	if err != nil {
+		return "", err
+	}
 	region := string(location.LocationConstraint)
 	if region == "" { // Region us-east-1 have a LocationConstraint of null (???)
-		region = "us-east-1"
+		region = partition.DefaultRegion(a.Partition())
 	}
+	return region, nil
+}
+
+// adaptBucketInRegion adapts the bucket if it is located in the scanned region
+func (a *adapter) adaptBucketInRegion(ctx context.Context, bucket s3types.Bucket, region string) (*s3.Bucket, error) {
 	if region != a.Region() {
 		return nil, nil
 	}
@@ -97,26 +167,26 @@ func (a *adapter) adaptBucket(bucket s3types.Bucket) (*s3.Bucket, error) {
 	b := s3.Bucket{
 		Metadata:                      bucketMetadata,
 		Name:                          name,
-		PublicAccessBlock:             a.getPublicAccessBlock(bucket.Name, bucketMetadata),
-		BucketPolicies:                a.getBucketPolicies(bucket.Name, bucketMetadata),
-		Encryption:                    a.getBucketEncryption(bucket.Name, bucketMetadata),
-		Versioning:                    a.getBucketVersioning(bucket.Name, bucketMetadata),
-		Logging:                       a.getBucketLogging(bucket.Name, bucketMetadata),
-		ACL:                           a.getBucketACL(bucket.Name, bucketMetadata),
-		Objects:                       a.getObjects(bucket.Name, bucketMetadata),
-		AccelerateConfigurationStatus: a.getBucketAccelarate(bucket.Name, bucketMetadata),
-		LifecycleConfiguration:        a.getBucketLifecycle(bucket.Name, bucketMetadata),
-		BucketLocation:                a.getBucketLocation(bucket.Name, bucketMetadata),
-		Website:                       a.getWebsite(bucket.Name, bucketMetadata),
+		PublicAccessBlock:             a.getPublicAccessBlock(ctx, bucket.Name, bucketMetadata),
+		BucketPolicies:                a.getBucketPolicies(ctx, bucket.Name, bucketMetadata),
+		Encryption:                    a.getBucketEncryption(ctx, bucket.Name, bucketMetadata),
+		Versioning:                    a.getBucketVersioning(ctx, bucket.Name, bucketMetadata),
+		Logging:                       a.getBucketLogging(ctx, bucket.Name, bucketMetadata),
+		ACL:                           a.getBucketACL(ctx, bucket.Name, bucketMetadata),
+		Objects:                       a.getObjects(ctx, bucket.Name, bucketMetadata),
+		AccelerateConfigurationStatus: a.getBucketAccelarate(ctx, bucket.Name, bucketMetadata),
+		LifecycleConfiguration:        a.getBucketLifecycle(ctx, bucket.Name, bucketMetadata),
+		BucketLocation:                a.getBucketLocation(ctx, bucket.Name, bucketMetadata),
+		Website:                       a.getWebsite(ctx, bucket.Name, bucketMetadata),
 	}
 
 	return &b, nil
 
 }
 
-func (a *adapter) getPublicAccessBlock(bucketName *string, metadata trivyTypes.Metadata) *s3.PublicAccessBlock {
+func (a *adapter) getPublicAccessBlock(ctx context.Context, bucketName *string, metadata trivyTypes.Metadata) *s3.PublicAccessBlock {
 
-	publicAccessBlocks, err := a.api.GetPublicAccessBlock(a.Context(), &s3api.GetPublicAccessBlockInput{
+	publicAccessBlocks, err := a.api.GetPublicAccessBlock(ctx, &s3api.GetPublicAccessBlockInput{
 		Bucket: bucketName,
 	})
 	if err != nil {
@@ -145,10 +215,10 @@ func (a *adapter) getPublicAccessBlock(bucketName *string, metadata trivyTypes.M
 	return &pab
 }
 
-func (a *adapter) getBucketPolicies(bucketName *string, metadata trivyTypes.Metadata) []iam.Policy {
+func (a *adapter) getBucketPolicies(ctx context.Context, bucketName *string, metadata trivyTypes.Metadata) []iam.Policy {
 	var bucketPolicies []iam.Policy
 
-	bucketPolicy, err := a.api.GetBucketPolicy(a.Context(), &s3api.GetBucketPolicyInput{Bucket: bucketName})
+	bucketPolicy, err := a.api.GetBucketPolicy(ctx, &s3api.GetBucketPolicyInput{Bucket: bucketName})
 	if err != nil {
 		// nolint
 		if awsError, ok := err.(awsError); ok {
@@ -183,7 +253,7 @@ func (a *adapter) getBucketPolicies(bucketName *string, metadata trivyTypes.Meta
 
 }
 
-func (a *adapter) getBucketEncryption(bucketName *string, metadata trivyTypes.Metadata) s3.Encryption {
+func (a *adapter) getBucketEncryption(ctx context.Context, bucketName *string, metadata trivyTypes.Metadata) s3.Encryption {
 	bucketEncryption := s3.Encryption{
 		Metadata:  metadata,
 		Enabled:   trivyTypes.BoolDefault(false, metadata),
@@ -191,7 +261,7 @@ func (a *adapter) getBucketEncryption(bucketName *string, metadata trivyTypes.Me
 		KMSKeyId:  trivyTypes.StringDefault("", metadata),
 	}
 
-	encryption, err := a.api.GetBucketEncryption(a.Context(), &s3api.GetBucketEncryptionInput{Bucket: bucketName})
+	encryption, err := a.api.GetBucketEncryption(ctx, &s3api.GetBucketEncryptionInput{Bucket: bucketName})
 	if err != nil {
 		// nolint
 		if awsError, ok := err.(awsError); ok {
@@ -220,14 +290,14 @@ func (a *adapter) getBucketEncryption(bucketName *string, metadata trivyTypes.Me
 	return bucketEncryption
 }
 
-func (a *adapter) getBucketVersioning(bucketName *string, metadata trivyTypes.Metadata) s3.Versioning {
+func (a *adapter) getBucketVersioning(ctx context.Context, bucketName *string, metadata trivyTypes.Metadata) s3.Versioning {
 	bucketVersioning := s3.Versioning{
 		Metadata:  metadata,
 		Enabled:   trivyTypes.BoolDefault(false, metadata),
 		MFADelete: trivyTypes.BoolDefault(false, metadata),
 	}
 
-	versioning, err := a.api.GetBucketVersioning(a.Context(), &s3api.GetBucketVersioningInput{Bucket: bucketName})
+	versioning, err := a.api.GetBucketVersioning(ctx, &s3api.GetBucketVersioningInput{Bucket: bucketName})
 	if err != nil {
 		// nolint
 		if awsError, ok := err.(awsError); ok {
@@ -248,7 +318,7 @@ func (a *adapter) getBucketVersioning(bucketName *string, metadata trivyTypes.Me
 	return bucketVersioning
 }
 
-func (a *adapter) getBucketLogging(bucketName *string, metadata trivyTypes.Metadata) s3.Logging {
+func (a *adapter) getBucketLogging(ctx context.Context, bucketName *string, metadata trivyTypes.Metadata) s3.Logging {
 
 	bucketLogging := s3.Logging{
 		Metadata:     metadata,
@@ -256,7 +326,7 @@ func (a *adapter) getBucketLogging(bucketName *string, metadata trivyTypes.Metad
 		TargetBucket: trivyTypes.StringDefault("", metadata),
 	}
 
-	logging, err := a.api.GetBucketLogging(a.Context(), &s3api.GetBucketLoggingInput{Bucket: bucketName})
+	logging, err := a.api.GetBucketLogging(ctx, &s3api.GetBucketLoggingInput{Bucket: bucketName})
 	if err != nil {
 		a.Logger().Error("Error getting bucket logging", log.Err(err))
 		return bucketLogging
@@ -270,8 +340,8 @@ func (a *adapter) getBucketLogging(bucketName *string, metadata trivyTypes.Metad
 	return bucketLogging
 }
 
-func (a *adapter) getBucketACL(bucketName *string, metadata trivyTypes.Metadata) trivyTypes.StringValue {
-	acl, err := a.api.GetBucketAcl(a.Context(), &s3api.GetBucketAclInput{Bucket: bucketName})
+func (a *adapter) getBucketACL(ctx context.Context, bucketName *string, metadata trivyTypes.Metadata) trivyTypes.StringValue {
+	acl, err := a.api.GetBucketAcl(ctx, &s3api.GetBucketAclInput{Bucket: bucketName})
 	if err != nil {
 		a.Logger().Error("Error getting bucket ACL", log.Err(err))
 		return trivyTypes.StringDefault("private", metadata)
@@ -297,8 +367,8 @@ func (a *adapter) getBucketACL(bucketName *string, metadata trivyTypes.Metadata)
 	return trivyTypes.String(aclValue, metadata)
 }
 
-func (a *adapter) getBucketLifecycle(bucketName *string, metadata trivyTypes.Metadata) []s3.Rules {
-	output, err := a.api.GetBucketLifecycleConfiguration(a.Context(), &s3api.GetBucketLifecycleConfigurationInput{
+func (a *adapter) getBucketLifecycle(ctx context.Context, bucketName *string, metadata trivyTypes.Metadata) []s3.Rules {
+	output, err := a.api.GetBucketLifecycleConfiguration(ctx, &s3api.GetBucketLifecycleConfigurationInput{
 		Bucket: bucketName,
 	})
 	if err != nil {
@@ -314,8 +384,8 @@ func (a *adapter) getBucketLifecycle(bucketName *string, metadata trivyTypes.Met
 	return rules
 }
 
-func (a *adapter) getBucketAccelarate(bucketName *string, metadata trivyTypes.Metadata) trivyTypes.StringValue {
-	output, err := a.api.GetBucketAccelerateConfiguration(a.Context(), &s3api.GetBucketAccelerateConfigurationInput{
+func (a *adapter) getBucketAccelarate(ctx context.Context, bucketName *string, metadata trivyTypes.Metadata) trivyTypes.StringValue {
+	output, err := a.api.GetBucketAccelerateConfiguration(ctx, &s3api.GetBucketAccelerateConfigurationInput{
 		Bucket: bucketName,
 	})
 	if err != nil {
@@ -324,8 +394,8 @@ func (a *adapter) getBucketAccelarate(bucketName *string, metadata trivyTypes.Me
 	return trivyTypes.String(string(output.Status), metadata)
 }
 
-func (a *adapter) getBucketLocation(bucketName *string, metadata trivyTypes.Metadata) trivyTypes.StringValue {
-	output, err := a.api.GetBucketLocation(a.Context(), &s3api.GetBucketLocationInput{
+func (a *adapter) getBucketLocation(ctx context.Context, bucketName *string, metadata trivyTypes.Metadata) trivyTypes.StringValue {
+	output, err := a.api.GetBucketLocation(ctx, &s3api.GetBucketLocationInput{
 		Bucket: bucketName,
 	})
 	if err != nil {
@@ -334,8 +404,8 @@ func (a *adapter) getBucketLocation(bucketName *string, metadata trivyTypes.Meta
 	return trivyTypes.String(string(output.LocationConstraint), metadata)
 }
 
-func (a *adapter) getObjects(bucketName *string, metadata trivyTypes.Metadata) []s3.Contents {
-	output, err := a.api.ListObjects(a.Context(), &s3api.ListObjectsInput{
+func (a *adapter) getObjects(ctx context.Context, bucketName *string, metadata trivyTypes.Metadata) []s3.Contents {
+	output, err := a.api.ListObjects(ctx, &s3api.ListObjectsInput{
 		Bucket: bucketName,
 	})
 	if err != nil {
@@ -350,9 +420,9 @@ func (a *adapter) getObjects(bucketName *string, metadata trivyTypes.Metadata) [
 	return obj
 }
 
-func (a *adapter) getWebsite(bucketName *string, metadata trivyTypes.Metadata) *s3.Website {
+func (a *adapter) getWebsite(ctx context.Context, bucketName *string, metadata trivyTypes.Metadata) *s3.Website {
 
-	website, err := a.api.GetBucketWebsite(a.Context(), &s3api.GetBucketWebsiteInput{
+	website, err := a.api.GetBucketWebsite(ctx, &s3api.GetBucketWebsiteInput{
 		Bucket: bucketName,
 	})
 	if err != nil {
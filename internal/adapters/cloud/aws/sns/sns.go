@@ -1,11 +1,16 @@
 package sns
 
 import (
+	"context"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
 	snsapi "github.com/aws/aws-sdk-go-v2/service/sns"
 	snsTypes "github.com/aws/aws-sdk-go-v2/service/sns/types"
 
 	"github.com/aquasecurity/trivy-aws/internal/adapters/cloud/aws"
 	"github.com/aquasecurity/trivy-aws/pkg/concurrency"
+	"github.com/aquasecurity/trivy-aws/pkg/errs"
 	"github.com/aquasecurity/trivy/pkg/iac/providers/aws/sns"
 	"github.com/aquasecurity/trivy/pkg/iac/state"
 	"github.com/aquasecurity/trivy/pkg/iac/types"
@@ -29,6 +34,13 @@ func (a *adapter) Name() string {
 	return "sns"
 }
 
+func (a *adapter) Permissions() []string {
+	return []string{
+		"sns:GetTopicAttributes",
+		"sns:ListTopics",
+	}
+}
+
 func (a *adapter) Adapt(root *aws.RootAdapter, state *state.State) error {
 
 	a.RootAdapter = root
@@ -43,6 +55,35 @@ func (a *adapter) Adapt(root *aws.RootAdapter, state *state.State) error {
 	return nil
 }
 
+func (a *adapter) AdaptResources(root *aws.RootAdapter, state *state.State, arns []string) error {
+
+	a.RootAdapter = root
+	a.client = snsapi.NewFromConfig(root.SessionConfig())
+
+	apiTopics := make([]snsTypes.Topic, 0, len(arns))
+	for _, resource := range arns {
+		parsed, err := arn.Parse(resource)
+		if err != nil || parsed.Service != "sns" {
+			a.Logger().Warn("Skipping resource which is not an SNS topic", log.String("arn", resource))
+			continue
+		}
+		apiTopics = append(apiTopics, snsTypes.Topic{TopicArn: awssdk.String(resource)})
+	}
+
+	a.Tracker().SetTotalResources(len(apiTopics))
+	a.Tracker().SetServiceLabel("Adapting SNS topics...")
+	state.AWS.SNS.Topics = concurrency.Adapt(apiTopics, a.RootAdapter, func(ctx context.Context, topic snsTypes.Topic) (*sns.Topic, error) {
+		// a topic which could not be fetched is kept in the cache, unless it no longer exists
+		t, err := a.adaptTopic(ctx, topic)
+		if err != nil && errs.Classify(err) == errs.ClassNotFound {
+			a.RecordNotFound(*topic.TopicArn)
+			return nil, nil
+		}
+		return t, err
+	})
+	return nil
+}
+
 func (a *adapter) getTopics() (queues []sns.Topic, err error) {
 
 	a.Tracker().SetServiceLabel("Discovering SNS topics...")
@@ -67,12 +108,12 @@ func (a *adapter) getTopics() (queues []sns.Topic, err error) {
 
 }
 
-func (a *adapter) adaptTopic(topic snsTypes.Topic) (*sns.Topic, error) {
+func (a *adapter) adaptTopic(ctx context.Context, topic snsTypes.Topic) (*sns.Topic, error) {
 
 	topicMetadata := a.CreateMetadataFromARN(*topic.TopicArn)
 
 	t := NewTopic(*topic.TopicArn, topicMetadata)
-	topicAttributes, err := a.client.GetTopicAttributes(a.Context(), &snsapi.GetTopicAttributesInput{
+	topicAttributes, err := a.client.GetTopicAttributes(ctx, &snsapi.GetTopicAttributesInput{
 		TopicArn: topic.TopicArn,
 	})
 	if err != nil {
@@ -1,17 +1,22 @@
 package sqs
 
 import (
+	"context"
+
 	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
 	sqsApi "github.com/aws/aws-sdk-go-v2/service/sqs"
 	sqsTypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
 
 	"github.com/aquasecurity/iamgo"
 	"github.com/aquasecurity/trivy-aws/internal/adapters/cloud/aws"
 	"github.com/aquasecurity/trivy-aws/pkg/concurrency"
+	"github.com/aquasecurity/trivy-aws/pkg/errs"
 	"github.com/aquasecurity/trivy/pkg/iac/providers/aws/iam"
 	"github.com/aquasecurity/trivy/pkg/iac/providers/aws/sqs"
 	"github.com/aquasecurity/trivy/pkg/iac/state"
 	trivyTypes "github.com/aquasecurity/trivy/pkg/iac/types"
+	"github.com/aquasecurity/trivy/pkg/log"
 )
 
 type adapter struct {
@@ -31,6 +36,14 @@ func (a *adapter) Name() string {
 	return "sqs"
 }
 
+func (a *adapter) Permissions() []string {
+	return []string{
+		"sqs:GetQueueAttributes",
+		"sqs:GetQueueUrl",
+		"sqs:ListQueues",
+	}
+}
+
 func (a *adapter) Adapt(root *aws.RootAdapter, state *state.State) error {
 
 	a.RootAdapter = root
@@ -45,6 +58,50 @@ func (a *adapter) Adapt(root *aws.RootAdapter, state *state.State) error {
 	return nil
 }
 
+func (a *adapter) AdaptResources(root *aws.RootAdapter, state *state.State, arns []string) error {
+
+	a.RootAdapter = root
+	a.client = sqsApi.NewFromConfig(root.SessionConfig())
+
+	var apiQueueURLs []string
+	queueARNs := make(map[string]string)
+	for _, resource := range arns {
+		parsed, err := arn.Parse(resource)
+		if err != nil || parsed.Service != "sqs" {
+			a.Logger().Warn("Skipping resource which is not an SQS queue", log.String("arn", resource))
+			continue
+		}
+		output, err := a.client.GetQueueUrl(a.Context(), &sqsApi.GetQueueUrlInput{
+			QueueName:              awssdk.String(parsed.Resource),
+			QueueOwnerAWSAccountId: awssdk.String(parsed.AccountID),
+		})
+		if err != nil {
+			// a queue which could not be looked up is kept in the cache, unless it no longer exists
+			if errs.Classify(err) == errs.ClassNotFound {
+				a.RecordNotFound(resource)
+				continue
+			}
+			a.Logger().Error("Failed to get queue URL", log.String("arn", resource), log.Err(err))
+			a.RecordError(resource, err)
+			continue
+		}
+		apiQueueURLs = append(apiQueueURLs, awssdk.ToString(output.QueueUrl))
+		queueARNs[awssdk.ToString(output.QueueUrl)] = resource
+	}
+
+	a.Tracker().SetTotalResources(len(apiQueueURLs))
+	a.Tracker().SetServiceLabel("Adapting SQS queues...")
+	state.AWS.SQS.Queues = concurrency.Adapt(apiQueueURLs, a.RootAdapter, func(ctx context.Context, queueURL string) (*sqs.Queue, error) {
+		queue, err := a.adaptQueue(ctx, queueURL)
+		if err != nil && errs.Classify(err) == errs.ClassNotFound {
+			a.RecordNotFound(queueARNs[queueURL])
+			return nil, nil
+		}
+		return queue, err
+	})
+	return nil
+}
+
 func (a *adapter) getQueues() (queues []sqs.Queue, err error) {
 
 	a.Tracker().SetServiceLabel("Discovering SQS queues...")
@@ -69,10 +126,10 @@ func (a *adapter) getQueues() (queues []sqs.Queue, err error) {
 
 }
 
-func (a *adapter) adaptQueue(queueURL string) (*sqs.Queue, error) {
+func (a *adapter) adaptQueue(ctx context.Context, queueURL string) (*sqs.Queue, error) {
 
 	// make another call to get the attributes for the Queue
-	queueAttributes, err := a.client.GetQueueAttributes(a.Context(), &sqsApi.GetQueueAttributesInput{
+	queueAttributes, err := a.client.GetQueueAttributes(ctx, &sqsApi.GetQueueAttributesInput{
 		QueueUrl: awssdk.String(queueURL),
 		AttributeNames: []sqsTypes.QueueAttributeName{
 			sqsTypes.QueueAttributeNameSqsManagedSseEnabled,
@@ -1,6 +1,8 @@
 package ssm
 
 import (
+	"context"
+
 	awssdk "github.com/aws/aws-sdk-go-v2/aws"
 	api "github.com/aws/aws-sdk-go-v2/service/secretsmanager"
 	secretsmanagerTypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
@@ -29,6 +31,12 @@ func (a *adapter) Name() string {
 	return "ssm"
 }
 
+func (a *adapter) Permissions() []string {
+	return []string{
+		"secretsmanager:ListSecrets",
+	}
+}
+
 func (a *adapter) Adapt(root *aws.RootAdapter, state *state.State) error {
 
 	a.RootAdapter = root
@@ -66,7 +74,7 @@ func (a *adapter) getSecrets() ([]ssm.Secret, error) {
 	return concurrency.Adapt(apiSecrets, a.RootAdapter, a.adaptSecret), nil
 }
 
-func (a *adapter) adaptSecret(apiSecret secretsmanagerTypes.SecretListEntry) (*ssm.Secret, error) {
+func (a *adapter) adaptSecret(ctx context.Context, apiSecret secretsmanagerTypes.SecretListEntry) (*ssm.Secret, error) {
 
 	metadata := a.CreateMetadataFromARN(awssdk.ToString(apiSecret.ARN))
 
@@ -0,0 +1,39 @@
+package aws
+
+import (
+	"context"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go/middleware"
+
+	"github.com/aquasecurity/trivy-aws/pkg/errs"
+	"github.com/aquasecurity/trivy-aws/pkg/stats"
+)
+
+// withStatsRecorder returns a copy of the config which records every API call of the service,
+// along with the attempts the call took and how many of them were throttled
+func withStatsRecorder(cfg aws.Config, collector *stats.Collector, service string) aws.Config {
+	cfg = cfg.Copy()
+	cfg.APIOptions = append(slices.Clone(cfg.APIOptions), func(stack *middleware.Stack) error {
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("RecordStats",
+			func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+				out, metadata, err := next.HandleInitialize(ctx, in)
+
+				attempts, throttles := 1, 0
+				if results, ok := retry.GetAttemptResults(metadata); ok && len(results.Results) > 0 {
+					attempts = len(results.Results)
+					for _, result := range results.Results {
+						if result.Err != nil && errs.Classify(result.Err) == errs.ClassThrottling {
+							throttles++
+						}
+					}
+				}
+				collector.RecordCall(service, awsmiddleware.GetOperationName(ctx), attempts, throttles)
+				return out, metadata, err
+			}), middleware.After)
+	})
+	return cfg
+}
@@ -0,0 +1,54 @@
+package aws
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/trivy-aws/pkg/progress"
+	"github.com/aquasecurity/trivy-aws/pkg/stats"
+)
+
+func Test_StatsRecorder(t *testing.T) {
+	var calls int
+	cfg := stubConfig(func(req *http.Request) (int, string) {
+		calls++
+		// the first attempt is throttled
+		if calls == 1 {
+			return http.StatusServiceUnavailable, `<Error><Code>SlowDown</Code><Message>slow down</Message></Error>`
+		}
+		return http.StatusOK, `<VersioningConfiguration><Status>Enabled</Status></VersioningConfiguration>`
+	})
+	cfg.Retryer = func() aws.Retryer {
+		return retry.NewStandard(func(o *retry.StandardOptions) {
+			o.Backoff = retry.BackoffDelayerFunc(func(int, error) (time.Duration, error) { return 0, nil })
+		})
+	}
+
+	collector := stats.NewCollector(progress.NoProgress, "us-east-1")
+	client := s3.NewFromConfig(withStatsRecorder(cfg, collector, "s3"), func(o *s3.Options) {
+		o.UsePathStyle = true
+	})
+
+	_, err := client.GetBucketVersioning(context.Background(), &s3.GetBucketVersioningInput{Bucket: aws.String("bucket")})
+	require.NoError(t, err)
+	_, err = client.GetBucketVersioning(context.Background(), &s3.GetBucketVersioningInput{Bucket: aws.String("bucket")})
+	require.NoError(t, err)
+
+	assert.Equal(t, []stats.Service{
+		{
+			Service:   "s3",
+			Region:    "us-east-1",
+			Calls:     map[string]int{"GetBucketVersioning": 2},
+			Retries:   1,
+			Throttles: 1,
+		},
+	}, collector.List())
+}
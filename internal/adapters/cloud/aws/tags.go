@@ -0,0 +1,83 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+
+	"github.com/aquasecurity/trivy-aws/internal/adapters/cloud/options"
+	"github.com/aquasecurity/trivy-aws/pkg/partition"
+	"github.com/aquasecurity/trivy/pkg/log"
+)
+
+// globalTagRegions are the regions from which the Resource Groups Tagging API returns the tags of the
+// resources of global services in the commercial partition, as no other region returns them
+var globalTagRegions = map[string]string{
+	"cloudfront": "us-east-1",
+}
+
+// ResourceTags lists the tags of every tagged resource in the region in a single pass over the
+// Resource Groups Tagging API. The tags of the scanned global services, or of every global service if
+// no service is given, are listed from the region serving them. The tags are keyed by the ARN of the resource.
+func ResourceTags(ctx context.Context, opt options.Options) (map[string]map[string]string, error) {
+	logger := log.WithPrefix("adapt-aws")
+
+	cfg, err := loadConfig(ctx, opt, logger)
+	if err != nil {
+		return nil, err
+	}
+	cfg = withReadOnlyGuard(cfg, &readOnlyGuard{})
+
+	logger.Debug("Discovering resource tags...")
+
+	tags := make(map[string]map[string]string)
+	if err := listResourceTags(ctx, cfg, &resourcegroupstaggingapi.GetResourcesInput{}, tags); err != nil {
+		return nil, err
+	}
+
+	for service, region := range globalTagRegions {
+		if region == cfg.Region || partition.FromRegion(cfg.Region) != partition.AWS ||
+			(len(opt.Services) > 0 && !slices.Contains(opt.Services, service)) {
+			continue
+		}
+		logger.Debug("Discovering resource tags of global service...", log.String("service", service), log.String("region", region))
+		globalCfg := cfg.Copy()
+		globalCfg.Region = region
+		if err := listResourceTags(ctx, globalCfg, &resourcegroupstaggingapi.GetResourcesInput{
+			ResourceTypeFilters: []string{service},
+		}, tags); err != nil {
+			return nil, err
+		}
+	}
+
+	logger.Debug("Discovered tagged resources", log.Int("count", len(tags)))
+	return tags, nil
+}
+
+// listResourceTags adds the tags of the resources listed by the Resource Groups Tagging API to tags
+func listResourceTags(ctx context.Context, cfg aws.Config, input *resourcegroupstaggingapi.GetResourcesInput, tags map[string]map[string]string) error {
+	paginator := resourcegroupstaggingapi.NewGetResourcesPaginator(resourcegroupstaggingapi.NewFromConfig(cfg), input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get resource tags: %w", err)
+		}
+		for _, mapping := range page.ResourceTagMappingList {
+			if mapping.ResourceARN == nil || len(mapping.Tags) == 0 {
+				continue
+			}
+			resourceTags := make(map[string]string, len(mapping.Tags))
+			for _, tag := range mapping.Tags {
+				if tag.Key == nil {
+					continue
+				}
+				resourceTags[*tag.Key] = aws.ToString(tag.Value)
+			}
+			tags[*mapping.ResourceARN] = resourceTags
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package aws
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi/types"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/trivy-aws/internal/adapters/cloud/options"
+)
+
+// stubTags answers the Resource Groups Tagging API with a tagged resource per region, the CloudFront
+// distributions being only returned from us-east-1
+type stubTags struct {
+	mu       sync.Mutex
+	requests []string
+}
+
+func (s *stubTags) apiOption(stack *middleware.Stack) error {
+	return stack.Serialize.Add(middleware.SerializeMiddlewareFunc("StubTags",
+		func(ctx context.Context, in middleware.SerializeInput, _ middleware.SerializeHandler) (middleware.SerializeOutput, middleware.Metadata, error) {
+			region := awsmiddleware.GetRegion(ctx)
+			input := in.Parameters.(*resourcegroupstaggingapi.GetResourcesInput)
+			s.mu.Lock()
+			s.requests = append(s.requests, region)
+			s.mu.Unlock()
+
+			var mappings []types.ResourceTagMapping
+			if !slices.Contains(input.ResourceTypeFilters, "cloudfront") {
+				mappings = append(mappings, tagMapping("arn:aws:sqs:"+region+":123456789012:queue", region))
+			}
+			if region == "us-east-1" {
+				mappings = append(mappings, tagMapping("arn:aws:cloudfront::123456789012:distribution/E1", "global"))
+			}
+			return middleware.SerializeOutput{Result: &resourcegroupstaggingapi.GetResourcesOutput{
+				ResourceTagMappingList: mappings,
+			}}, middleware.Metadata{}, nil
+		}), middleware.Before)
+}
+
+func tagMapping(resourceARN, team string) types.ResourceTagMapping {
+	return types.ResourceTagMapping{
+		ResourceARN: aws.String(resourceARN),
+		Tags:        []types.Tag{{Key: aws.String("team"), Value: aws.String(team)}},
+	}
+}
+
+func Test_ResourceTagsGlobalServices(t *testing.T) {
+	tests := []struct {
+		name     string
+		region   string
+		services []string
+		want     map[string]map[string]string
+		requests []string
+	}{
+		{
+			name:     "global service listed from us-east-1",
+			region:   "eu-west-1",
+			services: []string{"cloudfront", "sqs"},
+			want: map[string]map[string]string{
+				"arn:aws:sqs:eu-west-1:123456789012:queue":         {"team": "eu-west-1"},
+				"arn:aws:cloudfront::123456789012:distribution/E1": {"team": "global"},
+			},
+			requests: []string{"eu-west-1", "us-east-1"},
+		},
+		{
+			name:     "global service not scanned",
+			region:   "eu-west-1",
+			services: []string{"sqs"},
+			want: map[string]map[string]string{
+				"arn:aws:sqs:eu-west-1:123456789012:queue": {"team": "eu-west-1"},
+			},
+			requests: []string{"eu-west-1"},
+		},
+		{
+			name:     "scanned region serving the global service",
+			region:   "us-east-1",
+			services: []string{"cloudfront", "sqs"},
+			want: map[string]map[string]string{
+				"arn:aws:sqs:us-east-1:123456789012:queue":         {"team": "us-east-1"},
+				"arn:aws:cloudfront::123456789012:distribution/E1": {"team": "global"},
+			},
+			requests: []string{"us-east-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &stubTags{}
+			tags, err := ResourceTags(context.Background(), options.Options{
+				Region:      tt.region,
+				Services:    tt.services,
+				Credentials: credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+				APIOptions:  []func(*middleware.Stack) error{stub.apiOption},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, tags)
+			assert.Equal(t, tt.requests, stub.requests)
+		})
+	}
+}
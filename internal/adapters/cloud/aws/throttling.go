@@ -0,0 +1,52 @@
+package aws
+
+import (
+	"context"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go/middleware"
+
+	"github.com/aquasecurity/trivy-aws/internal/adapters/cloud/options"
+)
+
+// withThrottling returns a copy of the config which retries throttled calls in the adaptive mode, so that
+// the SDK slows down the calls to an API once AWS starts throttling them. If a limiter is given, the calls
+// to each API are limited by it up front, as the rate limits of AWS are shared by every client of the
+// account and throttling is best avoided altogether.
+func withThrottling(cfg aws.Config, rateLimiter *options.APIRateLimiter) aws.Config {
+	cfg = cfg.Copy()
+	cfg.Retryer = func() aws.Retryer {
+		return retry.NewAdaptiveMode()
+	}
+	if rateLimiter == nil {
+		return cfg
+	}
+
+	limiter := &apiRateLimiter{limiter: rateLimiter}
+	cfg.APIOptions = append(slices.Clone(cfg.APIOptions), func(stack *middleware.Stack) error {
+		// every attempt takes a token, including the retries
+		return stack.Finalize.Insert(limiter, "Retry", middleware.After)
+	})
+	return cfg
+}
+
+// apiRateLimiter is a middleware which waits for a token of the API before every call
+type apiRateLimiter struct {
+	limiter *options.APIRateLimiter
+}
+
+func (l *apiRateLimiter) ID() string {
+	return "APIRateLimit"
+}
+
+func (l *apiRateLimiter) HandleFinalize(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (
+	middleware.FinalizeOutput, middleware.Metadata, error,
+) {
+	if err := l.limiter.Limiter(awsmiddleware.GetServiceID(ctx)).Wait(ctx); err != nil {
+		return middleware.FinalizeOutput{}, middleware.Metadata{}, err
+	}
+	return next.HandleFinalize(ctx, in)
+}
@@ -0,0 +1,63 @@
+package aws
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aquasecurity/trivy-aws/internal/adapters/cloud/options"
+)
+
+func Test_Throttling(t *testing.T) {
+	cfg := withThrottling(stubConfig(func(req *http.Request) (int, string) {
+		return http.StatusNotFound, `<Error><Code>NoSuchBucket</Code><Message>missing</Message></Error>`
+	}), options.NewAPIRateLimiter(10))
+
+	assert.IsType(t, &retry.AdaptiveMode{}, cfg.Retryer())
+
+	s3Client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		o.UsePathStyle = true
+	})
+	stsClient := sts.NewFromConfig(cfg)
+
+	// the burst of 10 calls goes through at once, the rest at 10 calls per second
+	start := time.Now()
+	for range 12 {
+		_, _ = s3Client.GetBucketPolicy(context.Background(), &s3.GetBucketPolicyInput{Bucket: aws.String("bucket")})
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+
+	// every API has its own limit
+	start = time.Now()
+	_, _ = stsClient.GetCallerIdentity(context.Background(), &sts.GetCallerIdentityInput{})
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+}
+
+func Test_ThrottlingSharedLimiter(t *testing.T) {
+	limiter := options.NewAPIRateLimiter(10)
+	newClient := func(region string) *sts.Client {
+		cfg := withThrottling(stubConfig(func(req *http.Request) (int, string) {
+			return http.StatusForbidden, `<ErrorResponse><Error><Code>AccessDenied</Code><Message>denied</Message></Error></ErrorResponse>`
+		}), limiter)
+		cfg.Region = region
+		return sts.NewFromConfig(cfg)
+	}
+
+	// the clients of the regions of an account share the limit of each API
+	first, second := newClient("us-east-1"), newClient("eu-west-1")
+	for range 10 {
+		_, _ = first.GetCallerIdentity(context.Background(), &sts.GetCallerIdentityInput{})
+	}
+	start := time.Now()
+	for range 2 {
+		_, _ = second.GetCallerIdentity(context.Background(), &sts.GetCallerIdentityInput{})
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+}
@@ -0,0 +1,50 @@
+package aws
+
+import (
+	"context"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/aquasecurity/trivy-aws/pkg/tracing"
+)
+
+// withTracing returns a copy of the config which traces every API call in a span of its own, a child of
+// the span of the adapted service. The span covers every attempt of the call, including the retries.
+func withTracing(cfg aws.Config) aws.Config {
+	cfg = cfg.Copy()
+	cfg.APIOptions = append(slices.Clone(cfg.APIOptions), func(stack *middleware.Stack) error {
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("TraceAPICalls",
+			func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+				serviceID := awsmiddleware.GetServiceID(ctx)
+				operation := awsmiddleware.GetOperationName(ctx)
+
+				ctx, span := tracing.Tracer().Start(ctx, serviceID+"."+operation,
+					trace.WithSpanKind(trace.SpanKindClient),
+					trace.WithAttributes(
+						attribute.String("rpc.system", "aws-api"),
+						attribute.String("rpc.service", serviceID),
+						attribute.String("rpc.method", operation),
+						tracing.Region(awsmiddleware.GetRegion(ctx)),
+					),
+				)
+
+				out, metadata, err := next.HandleInitialize(ctx, in)
+
+				if results, ok := retry.GetAttemptResults(metadata); ok {
+					span.SetAttributes(attribute.Int("aws.attempts", len(results.Results)))
+				}
+				if requestID, ok := awsmiddleware.GetRequestIDMetadata(metadata); ok {
+					span.SetAttributes(attribute.String("aws.request_id", requestID))
+				}
+				tracing.End(span, err)
+				return out, metadata, err
+			}), middleware.After)
+	})
+	return cfg
+}
@@ -0,0 +1,48 @@
+package aws
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/aquasecurity/trivy-aws/pkg/tracing"
+)
+
+func Test_Tracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(previous)
+
+	cfg := withTracing(stubConfig(func(req *http.Request) (int, string) {
+		return http.StatusNotFound, `<Error><Code>NoSuchBucket</Code><Message>missing</Message></Error>`
+	}))
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		o.UsePathStyle = true
+	})
+
+	ctx, parent := tracing.Tracer().Start(context.Background(), "adapt s3")
+	_, err := client.GetBucketPolicy(ctx, &s3.GetBucketPolicyInput{Bucket: aws.String("bucket")})
+	require.Error(t, err)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	call := spans[0]
+	assert.Equal(t, "S3.GetBucketPolicy", call.Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), call.Parent().SpanID())
+	assert.Equal(t, codes.Error, call.Status().Code)
+	assert.Contains(t, call.Attributes(), attribute.String("rpc.method", "GetBucketPolicy"))
+	assert.Contains(t, call.Attributes(), attribute.String("aws.region", "us-east-1"))
+}
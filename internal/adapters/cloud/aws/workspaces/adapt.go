@@ -1,6 +1,8 @@
 package workspaces
 
 import (
+	"context"
+
 	awssdk "github.com/aws/aws-sdk-go-v2/aws"
 	api "github.com/aws/aws-sdk-go-v2/service/workspaces"
 	workspaceTypes "github.com/aws/aws-sdk-go-v2/service/workspaces/types"
@@ -29,6 +31,12 @@ func (a *adapter) Name() string {
 	return "workspaces"
 }
 
+func (a *adapter) Permissions() []string {
+	return []string{
+		"workspaces:DescribeWorkspaces",
+	}
+}
+
 func (a *adapter) Adapt(root *aws.RootAdapter, state *state.State) error {
 
 	a.RootAdapter = root
@@ -66,7 +74,7 @@ func (a *adapter) getWorkspaces() ([]workspaces.WorkSpace, error) {
 	return concurrency.Adapt(apiWorkspaces, a.RootAdapter, a.adaptWorkspace), nil
 }
 
-func (a *adapter) adaptWorkspace(apiWorkspace workspaceTypes.Workspace) (*workspaces.WorkSpace, error) {
+func (a *adapter) adaptWorkspace(ctx context.Context, apiWorkspace workspaceTypes.Workspace) (*workspaces.WorkSpace, error) {
 
 	metadata := a.CreateMetadata("workspace/" + awssdk.ToString(apiWorkspace.WorkspaceId))
 	return &workspaces.WorkSpace{
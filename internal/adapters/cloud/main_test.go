@@ -0,0 +1,14 @@
+package cloud
+
+import (
+	"os"
+	"testing"
+
+	"github.com/aquasecurity/trivy/pkg/log"
+)
+
+func TestMain(m *testing.M) {
+	// the adapters log concurrently, which the deferred logger used until the logger is initialized doesn't support
+	log.InitLogger(false, true)
+	os.Exit(m.Run())
+}
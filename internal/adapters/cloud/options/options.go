@@ -1,8 +1,16 @@
 package options
 
 import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/smithy-go/middleware"
+
+	"github.com/aquasecurity/trivy-aws/pkg/audit"
 	"github.com/aquasecurity/trivy-aws/pkg/concurrency"
+	"github.com/aquasecurity/trivy-aws/pkg/errs"
 	"github.com/aquasecurity/trivy-aws/pkg/progress"
+	"github.com/aquasecurity/trivy-aws/pkg/stats"
 )
 
 type Options struct {
@@ -10,5 +18,21 @@ type Options struct {
 	Region              string
 	Endpoint            string
 	Services            []string
+	ARNs                []string
 	ConcurrencyStrategy concurrency.Strategy
+	ServiceStrategies   map[string]concurrency.Strategy
+	// APIRateLimiter limits the calls to each API if set
+	APIRateLimiter     *APIRateLimiter
+	Credentials        aws.CredentialsProvider
+	ServiceParallelism int
+	ServiceTimeout     time.Duration
+	ResourceTimeout    time.Duration
+	Errors             *errs.Collector
+	// NotFound collects the requested ARNs of resources which were confirmed not to exist
+	NotFound *ResourceSet
+	Stats    *stats.Collector
+	AuditLog *audit.Log
+	// APIOptions are added to the middleware stack of every AWS client. Middlewares of the serialize
+	// step and later run after the read-only guard, e.g. to answer the calls without AWS in tests.
+	APIOptions []func(*middleware.Stack) error
 }
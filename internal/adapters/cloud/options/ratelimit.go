@@ -0,0 +1,37 @@
+package options
+
+import (
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// APIRateLimiter limits the calls to each AWS API with a token bucket of its own. The rate limits of AWS
+// are shared by every client of the account, so a single limiter is shared by the scans of every region
+// of the account. It is safe for concurrent use.
+type APIRateLimiter struct {
+	requestsPerSecond int
+
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+func NewAPIRateLimiter(requestsPerSecond int) *APIRateLimiter {
+	return &APIRateLimiter{
+		requestsPerSecond: requestsPerSecond,
+		limiters:          make(map[string]*rate.Limiter),
+	}
+}
+
+// Limiter returns the token bucket of the API with the given service ID
+func (l *APIRateLimiter) Limiter(api string) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limiter, ok := l.limiters[api]
+	if !ok {
+		limiter = rate.NewLimiter(rate.Limit(l.requestsPerSecond), l.requestsPerSecond)
+		l.limiters[api] = limiter
+	}
+	return limiter
+}
@@ -0,0 +1,36 @@
+package options
+
+import (
+	"slices"
+	"sync"
+)
+
+// ResourceSet collects the ARNs of resources, it is safe for concurrent use
+type ResourceSet struct {
+	mu   sync.Mutex
+	arns map[string]struct{}
+}
+
+func NewResourceSet() *ResourceSet {
+	return &ResourceSet{
+		arns: make(map[string]struct{}),
+	}
+}
+
+func (s *ResourceSet) Add(arn string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.arns[arn] = struct{}{}
+}
+
+// List returns the collected ARNs sorted
+func (s *ResourceSet) List() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var arns []string
+	for arn := range s.arns {
+		arns = append(arns, arn)
+	}
+	slices.Sort(arns)
+	return arns
+}
@@ -0,0 +1,375 @@
+package cloud
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/trivy-aws/internal/adapters/cloud/aws"
+)
+
+const sdkServicePrefix = "github.com/aws/aws-sdk-go-v2/service/"
+
+// actionPrefixes maps the SDK packages to their IAM service prefix where they differ
+var actionPrefixes = map[string]string{
+	"accessanalyzer":         "access-analyzer",
+	"cloudwatchlogs":         "logs",
+	"docdb":                  "rds",
+	"efs":                    "elasticfilesystem",
+	"elasticloadbalancingv2": "elasticloadbalancing",
+	"elasticsearchservice":   "es",
+	"emr":                    "elasticmapreduce",
+	"neptune":                "rds",
+}
+
+// actionOverrides maps the operations whose IAM action is not named after the operation
+var actionOverrides = map[string]string{
+	"s3.GetBucketAccelerateConfiguration": "s3:GetAccelerateConfiguration",
+	"s3.GetBucketEncryption":              "s3:GetEncryptionConfiguration",
+	"s3.GetBucketLifecycleConfiguration":  "s3:GetLifecycleConfiguration",
+	"s3.GetPublicAccessBlock":             "s3:GetBucketPublicAccessBlock",
+	"s3.ListBuckets":                      "s3:ListAllMyBuckets",
+	"s3.ListObjects":                      "s3:ListBucket",
+}
+
+func iamAction(pkg, operation string) string {
+	// API Gateway authorizes read requests by HTTP method rather than by operation
+	if pkg == "apigateway" || pkg == "apigatewayv2" {
+		return "apigateway:GET"
+	}
+	if action, ok := actionOverrides[pkg+"."+operation]; ok {
+		return action
+	}
+	prefix, ok := actionPrefixes[pkg]
+	if !ok {
+		prefix = pkg
+	}
+	return prefix + ":" + operation
+}
+
+// adapterCalls parses the adapter in the given directory, returning its service name and the SDK
+// client calls it makes, in the form <package>.<operation>
+func adapterCalls(t *testing.T, dir string) (string, []string) {
+	fset := token.NewFileSet()
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	var files []*ast.File
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".go" || strings.HasSuffix(entry.Name(), "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, filepath.Join(dir, entry.Name()), nil, 0)
+		require.NoError(t, err)
+		files = append(files, file)
+	}
+
+	var name string
+	clients := make(map[string]string) // adapter field -> SDK package
+	for _, file := range files {
+		imports := make(map[string]string)
+		for _, spec := range file.Imports {
+			importPath, err := strconv.Unquote(spec.Path.Value)
+			require.NoError(t, err)
+			alias := path.Base(importPath)
+			if spec.Name != nil {
+				alias = spec.Name.Name
+			}
+			imports[alias] = importPath
+		}
+
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch node := n.(type) {
+			case *ast.TypeSpec:
+				structType, ok := node.Type.(*ast.StructType)
+				if node.Name.Name != "adapter" || !ok {
+					return false
+				}
+				for _, field := range structType.Fields.List {
+					star, ok := field.Type.(*ast.StarExpr)
+					if !ok {
+						continue
+					}
+					sel, ok := star.X.(*ast.SelectorExpr)
+					if !ok || sel.Sel.Name != "Client" {
+						continue
+					}
+					pkg, ok := sel.X.(*ast.Ident)
+					if !ok || !strings.HasPrefix(imports[pkg.Name], sdkServicePrefix) {
+						continue
+					}
+					for _, fieldName := range field.Names {
+						clients[fieldName.Name] = strings.TrimPrefix(imports[pkg.Name], sdkServicePrefix)
+					}
+				}
+			case *ast.FuncDecl:
+				if node.Name.Name == "Name" && node.Recv != nil && len(node.Body.List) == 1 {
+					if ret, ok := node.Body.List[0].(*ast.ReturnStmt); ok && len(ret.Results) == 1 {
+						if lit, ok := ret.Results[0].(*ast.BasicLit); ok {
+							name, err = strconv.Unquote(lit.Value)
+							require.NoError(t, err)
+						}
+					}
+				}
+			}
+			return true
+		})
+	}
+
+	var calls []string
+	for _, file := range files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			// a.<client>.<Operation>(...)
+			operation, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			client, ok := operation.X.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			if pkg, ok := clients[client.Sel.Name]; ok {
+				calls = append(calls, pkg+"."+operation.Sel.Name)
+			}
+			return true
+		})
+	}
+
+	slices.Sort(calls)
+	return name, slices.Compact(calls)
+}
+
+func TestAdapterPermissions(t *testing.T) {
+	dirs, err := os.ReadDir("aws")
+	require.NoError(t, err)
+
+	var found []string
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		name, calls := adapterCalls(t, filepath.Join("aws", dir.Name()))
+		if name == "" {
+			continue
+		}
+		found = append(found, name)
+
+		t.Run(name, func(t *testing.T) {
+			require.NotEmpty(t, calls, "no API calls found for the adapter")
+			// the caller identity and resource tags are looked up for every scan
+			expected := []string{"sts:GetCallerIdentity", "tag:GetResources"}
+			for _, call := range calls {
+				pkg, operation, _ := strings.Cut(call, ".")
+				expected = append(expected, iamAction(pkg, operation))
+			}
+			slices.Sort(expected)
+			assert.Equal(t, slices.Compact(expected), aws.Permissions([]string{name}),
+				"the permissions of the adapter must match the API calls it makes")
+		})
+	}
+
+	assert.ElementsMatch(t, aws.AllServices(), found, "every registered adapter must be checked")
+}
+
+// commandSources are the sources which call AWS outside the adapters
+var commandSources = []string{
+	"../../../pkg/commands",
+	"../../../pkg/cache/s3.go",
+	"aws/preflight.go",
+}
+
+// commandCalls parses the given files, or the non-test files of the given directories, returning the SDK
+// client calls they make in the form <package>.<operation>. The clients are found by the type of the
+// struct fields and parameters holding them, and by the constructor of the variables they are assigned to.
+func commandCalls(t *testing.T, sources ...string) []string {
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, source := range sources {
+		info, err := os.Stat(source)
+		require.NoError(t, err)
+		paths := []string{source}
+		if info.IsDir() {
+			paths, err = filepath.Glob(filepath.Join(source, "*.go"))
+			require.NoError(t, err)
+		}
+		for _, p := range paths {
+			if strings.HasSuffix(p, "_test.go") {
+				continue
+			}
+			file, err := parser.ParseFile(fset, p, nil, 0)
+			require.NoError(t, err)
+			files = append(files, file)
+		}
+	}
+
+	var calls []string
+	for _, file := range files {
+		sdkPackages := make(map[string]string) // import alias -> SDK package
+		for _, spec := range file.Imports {
+			importPath, err := strconv.Unquote(spec.Path.Value)
+			require.NoError(t, err)
+			alias := path.Base(importPath)
+			if spec.Name != nil {
+				alias = spec.Name.Name
+			}
+			if strings.HasPrefix(importPath, sdkServicePrefix) {
+				sdkPackages[alias] = strings.TrimPrefix(importPath, sdkServicePrefix)
+			}
+		}
+
+		// clientType returns the SDK package of a *<package>.Client type
+		clientType := func(expr ast.Expr) (string, bool) {
+			star, ok := expr.(*ast.StarExpr)
+			if !ok {
+				return "", false
+			}
+			sel, ok := star.X.(*ast.SelectorExpr)
+			if !ok || sel.Sel.Name != "Client" {
+				return "", false
+			}
+			pkg, ok := sel.X.(*ast.Ident)
+			if !ok {
+				return "", false
+			}
+			sdkPackage, ok := sdkPackages[pkg.Name]
+			return sdkPackage, ok
+		}
+
+		// newClient returns the SDK package of a <package>.NewFromConfig(...) call
+		newClient := func(expr ast.Expr) (string, bool) {
+			call, ok := expr.(*ast.CallExpr)
+			if !ok {
+				return "", false
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || sel.Sel.Name != "NewFromConfig" {
+				return "", false
+			}
+			pkg, ok := sel.X.(*ast.Ident)
+			if !ok {
+				return "", false
+			}
+			sdkPackage, ok := sdkPackages[pkg.Name]
+			return sdkPackage, ok
+		}
+
+		fields := make(map[string]string) // struct field -> SDK package
+		ast.Inspect(file, func(n ast.Node) bool {
+			if structType, ok := n.(*ast.StructType); ok {
+				for _, field := range structType.Fields.List {
+					if sdkPackage, ok := clientType(field.Type); ok {
+						for _, name := range field.Names {
+							fields[name.Name] = sdkPackage
+						}
+					}
+				}
+			}
+			return true
+		})
+
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+			locals := make(map[string]string) // parameter or variable -> SDK package
+			for _, param := range fn.Type.Params.List {
+				if sdkPackage, ok := clientType(param.Type); ok {
+					for _, name := range param.Names {
+						locals[name.Name] = sdkPackage
+					}
+				}
+			}
+			ast.Inspect(fn.Body, func(n ast.Node) bool {
+				switch node := n.(type) {
+				case *ast.AssignStmt:
+					for i, rhs := range node.Rhs {
+						if sdkPackage, ok := newClient(rhs); ok && i < len(node.Lhs) {
+							if ident, ok := node.Lhs[i].(*ast.Ident); ok {
+								locals[ident.Name] = sdkPackage
+							}
+						}
+					}
+				case *ast.CallExpr:
+					sel, ok := node.Fun.(*ast.SelectorExpr)
+					if !ok {
+						return true
+					}
+					operation := sel.Sel.Name
+					switch x := sel.X.(type) {
+					case *ast.Ident:
+						if sdkPackage, ok := locals[x.Name]; ok {
+							calls = append(calls, sdkPackage+"."+operation)
+						} else if sdkPackage, ok := sdkPackages[x.Name]; ok &&
+							strings.HasPrefix(operation, "New") && strings.HasSuffix(operation, "Paginator") {
+							// <package>.New<Operation>Paginator(client, ...)
+							calls = append(calls, sdkPackage+"."+strings.TrimSuffix(strings.TrimPrefix(operation, "New"), "Paginator"))
+						} else if x.Name == "stscreds" && operation == "NewAssumeRoleProvider" {
+							calls = append(calls, "sts.AssumeRole")
+						}
+					case *ast.SelectorExpr:
+						if sdkPackage, ok := fields[x.Sel.Name]; ok {
+							calls = append(calls, sdkPackage+"."+operation)
+						}
+					case *ast.CallExpr:
+						if sdkPackage, ok := newClient(x); ok {
+							calls = append(calls, sdkPackage+"."+operation)
+						}
+					}
+				}
+				return true
+			})
+		}
+	}
+
+	slices.Sort(calls)
+	return slices.Compact(calls)
+}
+
+func TestCommandPermissions(t *testing.T) {
+	calls := commandCalls(t, commandSources...)
+	assert.Equal(t, []string{
+		"ec2.DescribeRegions",
+		"iam.GetRole",
+		"iam.SimulatePrincipalPolicy",
+		"organizations.ListAccounts",
+		"s3.DeleteObject",
+		"s3.GetObject",
+		"s3.PutObject",
+		"sts.AssumeRole",
+		"sts.GetCallerIdentity",
+	}, calls, "the AWS calls of the commands must be known")
+
+	// every call is covered by the base permissions or by the permissions of a feature
+	features, err := aws.FeaturePermissions(aws.Features())
+	require.NoError(t, err)
+	// the caller identity and resource tags are looked up for every scan
+	permitted := append([]string{"sts:GetCallerIdentity", "tag:GetResources"}, features...)
+	var actions []string
+	for _, call := range calls {
+		pkg, operation, _ := strings.Cut(call, ".")
+		actions = append(actions, iamAction(pkg, operation))
+		assert.Contains(t, permitted, iamAction(pkg, operation), "the permission of %s must be documented by a feature", call)
+	}
+
+	// and every permission of a feature is required by a call
+	for _, feature := range aws.Features() {
+		permissions, err := aws.FeaturePermissions([]aws.Feature{feature})
+		require.NoError(t, err)
+		assert.Subset(t, actions, permissions, "the permissions of feature %s must be required by the commands", feature)
+	}
+}
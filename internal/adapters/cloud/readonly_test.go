@@ -0,0 +1,274 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/accessanalyzer"
+	"github.com/aws/aws-sdk-go-v2/service/apigateway"
+	"github.com/aws/aws-sdk-go-v2/service/apigatewayv2"
+	"github.com/aws/aws-sdk-go-v2/service/athena"
+	"github.com/aws/aws-sdk-go-v2/service/cloudfront"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/codebuild"
+	"github.com/aws/aws-sdk-go-v2/service/docdb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/efs"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/elasticache"
+	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+	"github.com/aws/aws-sdk-go-v2/service/elasticsearchservice"
+	"github.com/aws/aws-sdk-go-v2/service/emr"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/kafka"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/mq"
+	"github.com/aws/aws-sdk-go-v2/service/neptune"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/aws/aws-sdk-go-v2/service/redshift"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/aws-sdk-go-v2/service/workspaces"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/trivy-aws/internal/adapters/cloud/aws"
+	"github.com/aquasecurity/trivy-aws/internal/adapters/cloud/options"
+	"github.com/aquasecurity/trivy-aws/pkg/concurrency"
+	"github.com/aquasecurity/trivy-aws/pkg/errs"
+	"github.com/aquasecurity/trivy-aws/pkg/progress"
+)
+
+const getCallerIdentityResponse = `<GetCallerIdentityResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <GetCallerIdentityResult>
+    <Arn>arn:aws:iam::123456789012:user/scanner</Arn>
+    <UserId>AIDAEXAMPLE</UserId>
+    <Account>123456789012</Account>
+  </GetCallerIdentityResult>
+</GetCallerIdentityResponse>`
+
+// stubClients are the clients of every SDK package the adapters use, the operations of which are answered
+// by the stub
+var stubClients = []any{
+	&accessanalyzer.Client{}, &apigateway.Client{}, &apigatewayv2.Client{}, &athena.Client{}, &cloudfront.Client{},
+	&cloudtrail.Client{}, &cloudwatch.Client{}, &cloudwatchlogs.Client{}, &codebuild.Client{}, &docdb.Client{},
+	&dynamodb.Client{}, &ec2.Client{}, &ecr.Client{}, &ecs.Client{}, &efs.Client{}, &eks.Client{},
+	&elasticache.Client{}, &elasticloadbalancingv2.Client{}, &elasticsearchservice.Client{}, &emr.Client{},
+	&iam.Client{}, &kafka.Client{}, &kinesis.Client{}, &kms.Client{}, &lambda.Client{}, &mq.Client{},
+	&neptune.Client{}, &rds.Client{}, &redshift.Client{}, &s3.Client{}, &secretsmanager.Client{}, &sns.Client{},
+	&sqs.Client{}, &sts.Client{}, &workspaces.Client{},
+}
+
+// stubAWS answers the calls of the AWS clients in place of AWS, with outputs in which every field is set and
+// every list has a single element, so that each adapter finds a resource in every listing and walks its
+// whole call graph. It answers in the serialize step, which only the calls allowed by the read-only guard
+// reach, and records the operations it answered.
+type stubAWS struct {
+	outputs map[reflect.Type]reflect.Type
+
+	mu   sync.Mutex
+	seen []string
+}
+
+func newStubAWS() *stubAWS {
+	outputs := make(map[reflect.Type]reflect.Type)
+	for _, client := range stubClients {
+		clientType := reflect.TypeOf(client)
+		for i := range clientType.NumMethod() {
+			// func (c *Client) Operation(ctx context.Context, params *OperationInput, optFns ...func(*Options)) (*OperationOutput, error)
+			method := clientType.Method(i).Type
+			if method.NumIn() != 4 || method.NumOut() != 2 || method.Out(0).Kind() != reflect.Pointer {
+				continue
+			}
+			outputs[method.In(2)] = method.Out(0).Elem()
+		}
+	}
+	return &stubAWS{outputs: outputs}
+}
+
+func (s *stubAWS) apiOption(stack *middleware.Stack) error {
+	return stack.Serialize.Add(middleware.SerializeMiddlewareFunc("StubAWS",
+		func(ctx context.Context, in middleware.SerializeInput, _ middleware.SerializeHandler) (middleware.SerializeOutput, middleware.Metadata, error) {
+			inputType := reflect.TypeOf(in.Parameters)
+			outputType, ok := s.outputs[inputType]
+			if !ok {
+				return middleware.SerializeOutput{}, middleware.Metadata{}, fmt.Errorf("no stub for %s", inputType)
+			}
+
+			s.mu.Lock()
+			s.seen = append(s.seen, path.Base(inputType.Elem().PkgPath())+"."+awsmiddleware.GetOperationName(ctx))
+			s.mu.Unlock()
+
+			output := reflect.New(outputType)
+			fillStub(output.Elem(), "", 0)
+			return middleware.SerializeOutput{Result: output.Interface()}, middleware.Metadata{}, nil
+		}), middleware.Before)
+}
+
+// operations returns the sorted operations the stub answered, in the form <package>.<operation>
+func (s *stubAWS) operations() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	operations := slices.Sorted(slices.Values(s.seen))
+	return slices.Compact(operations)
+}
+
+// fillStub sets every exported field of the value, leaving out the pagination tokens so that every listing
+// ends after its first page
+func fillStub(v reflect.Value, name string, depth int) {
+	if depth > 12 {
+		return
+	}
+	switch v.Kind() {
+	case reflect.Pointer:
+		if strings.HasSuffix(name, "Token") || strings.HasSuffix(name, "Marker") ||
+			name == "Position" || strings.HasPrefix(name, "LastEvaluated") {
+			return
+		}
+		v.Set(reflect.New(v.Type().Elem()))
+		fillStub(v.Elem(), name, depth+1)
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if field := v.Type().Field(i); field.IsExported() {
+				fillStub(v.Field(i), field.Name, depth+1)
+			}
+		}
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			v.SetBytes([]byte(stubString(name)))
+			return
+		}
+		elements := reflect.MakeSlice(v.Type(), 1, 1)
+		fillStub(elements.Index(0), name, depth+1)
+		v.Set(elements)
+	case reflect.Map:
+		key := reflect.New(v.Type().Key()).Elem()
+		fillStub(key, name, depth+1)
+		value := reflect.New(v.Type().Elem()).Elem()
+		fillStub(value, name, depth+1)
+		entries := reflect.MakeMap(v.Type())
+		entries.SetMapIndex(key, value)
+		v.Set(entries)
+	case reflect.String:
+		v.SetString(stubString(name))
+	case reflect.Bool:
+		// the listings are not truncated, but every optional feature is enabled
+		v.SetBool(!strings.Contains(name, "Truncated") && !strings.Contains(name, "More"))
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		v.SetInt(1)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(1)
+	}
+}
+
+func stubString(name string) string {
+	switch {
+	case strings.Contains(name, "Arn") || strings.Contains(name, "ARN"):
+		return "arn:aws:stub:us-east-1:123456789012:stub/stub"
+	case strings.Contains(name, "Policy") || strings.Contains(name, "Document"):
+		return `{"Version":"2012-10-17","Statement":[]}`
+	case strings.Contains(name, "Region") || name == "LocationConstraint":
+		return "us-east-1"
+	case name == "Account":
+		return "123456789012"
+	default:
+		return "stub"
+	}
+}
+
+// Test_ReadOnly walks every registered adapter against a stub of AWS, so that the scan fails if any adapter
+// attempts a call which is not read-only, and checks that every call the adapters make was reached
+func Test_ReadOnly(t *testing.T) {
+	stub := newStubAWS()
+	scanErrors := errs.NewCollector()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	_, err := Adapt(ctx, options.Options{
+		Errors:              scanErrors,
+		ProgressTracker:     progress.NoProgress,
+		Region:              "us-east-1",
+		Credentials:         credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+		ConcurrencyStrategy: concurrency.DefaultStrategy,
+		ServiceParallelism:  len(aws.AllServices()),
+		APIOptions:          []func(*middleware.Stack) error{stub.apiOption},
+	})
+	require.NoError(t, err)
+
+	// some calls are only made to adapt individual resources
+	for _, resource := range []string{
+		"arn:aws:s3:::stub",
+		"arn:aws:sns:us-east-1:123456789012:stub",
+		"arn:aws:sqs:us-east-1:123456789012:stub",
+		"arn:aws:lambda:us-east-1:123456789012:function:stub",
+		"arn:aws:iam::123456789012:role/stub",
+		"arn:aws:iam::123456789012:user/stub",
+		"arn:aws:iam::123456789012:group/stub",
+	} {
+		_, err := Adapt(ctx, options.Options{
+			Errors:              scanErrors,
+			ProgressTracker:     progress.NoProgress,
+			Region:              "us-east-1",
+			Credentials:         credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+			ConcurrencyStrategy: concurrency.DefaultStrategy,
+			Services:            []string{strings.Split(resource, ":")[2]},
+			ARNs:                []string{resource},
+			APIOptions:          []func(*middleware.Stack) error{stub.apiOption},
+		})
+		require.NoError(t, err)
+	}
+	assert.Empty(t, scanErrors.List())
+
+	operations := stub.operations()
+	for _, operation := range operations {
+		_, name, _ := strings.Cut(operation, ".")
+		assert.True(t, isReadOnlyOperation(name), "the stub answered %s, which is not read-only", operation)
+	}
+
+	dirs, err := os.ReadDir("aws")
+	require.NoError(t, err)
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		name, calls := adapterCalls(t, filepath.Join("aws", dir.Name()))
+		if name == "" {
+			continue
+		}
+		t.Run(name, func(t *testing.T) {
+			assert.Subset(t, operations, calls, "every call of the adapter should reach the stub")
+		})
+	}
+}
+
+func isReadOnlyOperation(operation string) bool {
+	for _, prefix := range []string{"Describe", "List", "Get", "BatchGet"} {
+		if strings.HasPrefix(operation, prefix) {
+			return true
+		}
+	}
+	return operation == "GenerateCredentialReport"
+}
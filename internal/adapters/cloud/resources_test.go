@@ -0,0 +1,172 @@
+package cloud
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/trivy-aws/internal/adapters/cloud/options"
+	"github.com/aquasecurity/trivy-aws/pkg/concurrency"
+	"github.com/aquasecurity/trivy-aws/pkg/errs"
+	"github.com/aquasecurity/trivy-aws/pkg/progress"
+)
+
+// Test_AdaptResourcesFetchFailed requests a resource which no longer exists and one the fetch of which is
+// denied of each service, so that only the former is reported as not found and the latter is kept cached
+func Test_AdaptResourcesFetchFailed(t *testing.T) {
+	var mu sync.Mutex
+	var topicsRequested []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		switch {
+		case r.Form.Get("Action") == "GetCallerIdentity":
+			w.Header().Set("Content-Type", "text/xml")
+			_, _ = w.Write([]byte(getCallerIdentityResponse))
+		case r.Form.Get("Action") == "GetTopicAttributes":
+			topic := r.Form.Get("TopicArn")
+			mu.Lock()
+			topicsRequested = append(topicsRequested, topic)
+			mu.Unlock()
+			if strings.HasSuffix(topic, ":gone") {
+				writeXMLError(w, http.StatusNotFound, "NotFound")
+				return
+			}
+			writeXMLError(w, http.StatusForbidden, "AccessDenied")
+		case r.Form.Get("Action") == "DescribeInstances":
+			if r.Form.Get("InstanceId.1") == "i-gone" {
+				writeEC2Error(w, http.StatusBadRequest, "InvalidInstanceID.NotFound")
+				return
+			}
+			writeEC2Error(w, http.StatusForbidden, "UnauthorizedOperation")
+		case r.Form.Get("Action") == "GetRole":
+			if r.Form.Get("RoleName") == "gone" {
+				writeXMLError(w, http.StatusNotFound, "NoSuchEntity")
+				return
+			}
+			writeXMLError(w, http.StatusForbidden, "AccessDenied")
+		case r.Form.Get("Action") == "DescribeDBInstances":
+			if strings.HasSuffix(r.Form.Get("DBInstanceIdentifier"), ":gone") {
+				writeXMLError(w, http.StatusNotFound, "DBInstanceNotFound")
+				return
+			}
+			writeXMLError(w, http.StatusForbidden, "AccessDenied")
+		case strings.HasPrefix(r.URL.Path, "/2015-03-31/functions/"):
+			w.Header().Set("Content-Type", "application/json")
+			if strings.HasSuffix(r.URL.Path, ":gone") {
+				w.Header().Set("X-Amzn-Errortype", "ResourceNotFoundException")
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"message":"gone"}`))
+				return
+			}
+			w.Header().Set("X-Amzn-Errortype", "AccessDeniedException")
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"message":"denied"}`))
+		case r.Header.Get("X-Amz-Target") == "AmazonSQS.GetQueueUrl":
+			var input struct{ QueueName string }
+			_ = json.NewDecoder(r.Body).Decode(&input)
+			w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+			w.WriteHeader(http.StatusBadRequest)
+			if input.QueueName == "gone" {
+				_, _ = w.Write([]byte(`{"__type":"com.amazonaws.sqs#QueueDoesNotExist","message":"gone"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"__type":"com.amazon.coral.service#AccessDeniedException","message":"denied"}`))
+		case r.URL.Query().Has("location"):
+			if r.URL.Path == "/gone" {
+				writeXMLError(w, http.StatusNotFound, "NoSuchBucket")
+				return
+			}
+			writeXMLError(w, http.StatusForbidden, "AccessDenied")
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	// unlike the endpoint option, the endpoint of the environment lets S3 address the buckets by path
+	t.Setenv("AWS_ENDPOINT_URL", server.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	scanErrors := errs.NewCollector()
+	notFound := options.NewResourceSet()
+	_, err := Adapt(ctx, options.Options{
+		ProgressTracker:     progress.NoProgress,
+		Region:              "us-east-1",
+		Credentials:         credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+		ConcurrencyStrategy: concurrency.DefaultStrategy,
+		Services:            []string{"ec2", "iam", "lambda", "rds", "s3", "sns", "sqs"},
+		ARNs: []string{
+			"arn:aws:ec2:us-east-1:123456789012:instance/i-gone",
+			"arn:aws:ec2:us-east-1:123456789012:instance/i-denied",
+			"arn:aws:iam::123456789012:role/gone",
+			"arn:aws:iam::123456789012:role/denied",
+			"arn:aws:lambda:us-east-1:123456789012:function:gone",
+			"arn:aws:lambda:us-east-1:123456789012:function:denied",
+			"arn:aws:rds:us-east-1:123456789012:db:gone",
+			"arn:aws:rds:us-east-1:123456789012:db:denied",
+			"arn:aws:s3:::gone",
+			"arn:aws:s3:::denied",
+			"arn:aws:sns:us-east-1:123456789012:gone",
+			"arn:aws:sns:us-east-1:123456789012:denied",
+			"arn:aws:sqs:us-east-1:123456789012:gone",
+			"arn:aws:sqs:us-east-1:123456789012:denied",
+		},
+		Errors:   scanErrors,
+		NotFound: notFound,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"arn:aws:ec2:us-east-1:123456789012:instance/i-gone",
+		"arn:aws:iam::123456789012:role/gone",
+		"arn:aws:lambda:us-east-1:123456789012:function:gone",
+		"arn:aws:rds:us-east-1:123456789012:db:gone",
+		"arn:aws:s3:::gone",
+		"arn:aws:sns:us-east-1:123456789012:gone",
+		"arn:aws:sqs:us-east-1:123456789012:gone",
+	}, notFound.List())
+
+	// the resources which were denied are recorded as gaps rather than as deleted
+	denied := make(map[string]bool)
+	for _, scanErr := range scanErrors.List() {
+		if scanErr.Class == errs.ClassAccessDenied {
+			denied[scanErr.Service] = true
+		}
+	}
+	assert.Equal(t, map[string]bool{
+		"ec2": true, "iam": true, "lambda": true, "rds": true, "s3": true, "sns": true, "sqs": true,
+	}, denied)
+
+	// the topics are only looked up by ARNs of SNS
+	mu.Lock()
+	defer mu.Unlock()
+	assert.ElementsMatch(t, []string{
+		"arn:aws:sns:us-east-1:123456789012:gone",
+		"arn:aws:sns:us-east-1:123456789012:denied",
+	}, topicsRequested)
+}
+
+func writeXMLError(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "text/xml")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(`<ErrorResponse><Error><Code>` + code + `</Code><Message>` + code + `</Message></Error></ErrorResponse>`))
+}
+
+func writeEC2Error(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "text/xml")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(`<Response><Errors><Error><Code>` + code + `</Code><Message>` + code + `</Message></Error></Errors></Response>`))
+}
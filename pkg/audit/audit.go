@@ -0,0 +1,59 @@
+package audit
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// Status is the outcome of an API call
+type Status string
+
+const (
+	StatusSuccess Status = "success"
+	StatusError   Status = "error"
+)
+
+// The error codes recorded for the errors which are not API errors, so that neither the message of the
+// error, which may hold the URL of the request, nor anything else varying between calls is recorded
+const (
+	ErrorCodeCanceled         = "canceled"
+	ErrorCodeDeadlineExceeded = "deadline_exceeded"
+	ErrorCodeTransport        = "transport"
+)
+
+// Entry records a single attempt of an AWS API call. Neither the parameters nor the response of the
+// call are recorded, only what was called, where and with which outcome.
+type Entry struct {
+	Time      time.Time `json:"time"`
+	Service   string    `json:"service"`
+	Operation string    `json:"operation"`
+	Region    string    `json:"region,omitempty"`
+	Status    Status    `json:"status"`
+	// HTTPStatus is the status code of the response, which is 0 if no response was received
+	HTTPStatus int    `json:"http_status,omitempty"`
+	RequestID  string `json:"request_id,omitempty"`
+	// ErrorCode is the code of the API error, or one of the fixed ErrorCode values if the call failed
+	// without an API error, e.g. as it was canceled or the request could not be sent
+	ErrorCode string `json:"error_code,omitempty"`
+}
+
+// Log writes the entries as JSON lines, one entry per line. It is safe for concurrent use.
+type Log struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+func NewLog(w io.Writer) *Log {
+	return &Log{
+		encoder: json.NewEncoder(w),
+	}
+}
+
+// Record writes the entry to the log
+func (l *Log) Record(entry Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.encoder.Encode(entry)
+}
@@ -0,0 +1,89 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+type logKey struct{}
+
+// NewContext returns a copy of the context which carries the log, so that every AWS client created for
+// the command records its calls in the same log
+func NewContext(ctx context.Context, log *Log) context.Context {
+	return context.WithValue(ctx, logKey{}, log)
+}
+
+// FromContext returns the log carried by the context, or nil if the calls are not audited
+func FromContext(ctx context.Context) *Log {
+	log, _ := ctx.Value(logKey{}).(*Log)
+	return log
+}
+
+// WithLog returns a copy of the config which records every attempt of every API call in the log, retries
+// included, as each of them is a request AWS receives. The entry of an attempt is written once its outcome
+// is known, so AWS has received the request by then. A call whose entry cannot be written returns the
+// error of the log, and a call interrupted by the process exiting is not recorded. A nil log returns the
+// config unchanged.
+func WithLog(cfg aws.Config, log *Log) aws.Config {
+	if log == nil {
+		return cfg
+	}
+	cfg = cfg.Copy()
+	cfg.APIOptions = append(slices.Clone(cfg.APIOptions), func(stack *middleware.Stack) error {
+		return stack.Finalize.Insert(middleware.FinalizeMiddlewareFunc("AuditLog",
+			func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+				entry := Entry{
+					Time:      time.Now().UTC(),
+					Service:   awsmiddleware.GetServiceID(ctx),
+					Operation: awsmiddleware.GetOperationName(ctx),
+					Region:    awsmiddleware.GetRegion(ctx),
+					Status:    StatusSuccess,
+				}
+
+				out, metadata, err := next.HandleFinalize(ctx, in)
+
+				if resp, ok := awsmiddleware.GetRawResponse(metadata).(*smithyhttp.Response); ok {
+					entry.HTTPStatus = resp.StatusCode
+				} else if respErr := (*smithyhttp.ResponseError)(nil); errors.As(err, &respErr) {
+					entry.HTTPStatus = respErr.HTTPStatusCode()
+				}
+				if requestID, ok := awsmiddleware.GetRequestIDMetadata(metadata); ok {
+					entry.RequestID = requestID
+				}
+				if err != nil {
+					entry.Status = StatusError
+					entry.ErrorCode = errorCode(err)
+				}
+
+				if logErr := log.Record(entry); logErr != nil && err == nil {
+					err = fmt.Errorf("failed to write audit log: %w", logErr)
+				}
+				return out, metadata, err
+			}), "Retry", middleware.After)
+	})
+	return cfg
+}
+
+// errorCode returns the code of the API error, or the class of the error if it is not an API error
+func errorCode(err error) string {
+	var apiErr smithy.APIError
+	switch {
+	case errors.As(err, &apiErr):
+		return apiErr.ErrorCode()
+	case errors.Is(err, context.Canceled):
+		return ErrorCodeCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorCodeDeadlineExceeded
+	default:
+		return ErrorCodeTransport
+	}
+}
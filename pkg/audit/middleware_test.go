@@ -0,0 +1,146 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// stubConfig returns a config whose requests are answered by the handler rather than AWS
+func stubConfig(handler func(req *http.Request) (int, string)) aws.Config {
+	return aws.Config{
+		Region:      "us-east-1",
+		Credentials: credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+		Retryer:     func() aws.Retryer { return aws.NopRetryer{} },
+		HTTPClient: &http.Client{
+			Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				status, body := handler(req)
+				return &http.Response{
+					StatusCode: status,
+					Header:     http.Header{"Content-Type": []string{"text/xml"}},
+					Body:       io.NopCloser(strings.NewReader(body)),
+					Request:    req,
+				}, nil
+			}),
+		},
+	}
+}
+
+func Test_NewContext(t *testing.T) {
+	assert.Nil(t, FromContext(context.Background()))
+
+	log := NewLog(io.Discard)
+	assert.Same(t, log, FromContext(NewContext(context.Background(), log)))
+
+	// the calls are not audited without a log
+	cfg := stubConfig(nil)
+	assert.Len(t, WithLog(cfg, nil).APIOptions, len(cfg.APIOptions))
+}
+
+func Test_WithLog(t *testing.T) {
+	cfg := stubConfig(func(req *http.Request) (int, string) {
+		if req.URL.Query().Has("logging") {
+			return http.StatusForbidden, `<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`
+		}
+		return http.StatusOK, `<ListAllMyBucketsResult><Buckets><Bucket><Name>secret-bucket</Name></Bucket></Buckets></ListAllMyBucketsResult>`
+	})
+
+	var buf bytes.Buffer
+	client := s3.NewFromConfig(WithLog(cfg, NewLog(&buf)), func(o *s3.Options) {
+		o.UsePathStyle = true
+	})
+
+	_, err := client.ListBuckets(context.Background(), &s3.ListBucketsInput{})
+	require.NoError(t, err)
+	_, err = client.GetBucketLogging(context.Background(), &s3.GetBucketLoggingInput{Bucket: aws.String("secret-bucket")})
+	require.Error(t, err)
+
+	// no payloads are recorded
+	assert.NotContains(t, buf.String(), "secret-bucket")
+
+	var entries []Entry
+	decoder := json.NewDecoder(&buf)
+	for decoder.More() {
+		var entry Entry
+		require.NoError(t, decoder.Decode(&entry))
+		assert.False(t, entry.Time.IsZero())
+		entry.Time = time.Time{}
+		entries = append(entries, entry)
+	}
+
+	require.Len(t, entries, 2)
+	assert.Equal(t, Entry{
+		Service:    "S3",
+		Operation:  "ListBuckets",
+		Region:     "us-east-1",
+		Status:     StatusSuccess,
+		HTTPStatus: http.StatusOK,
+	}, entries[0])
+	assert.Equal(t, Entry{
+		Service:    "S3",
+		Operation:  "GetBucketLogging",
+		Region:     "us-east-1",
+		Status:     StatusError,
+		HTTPStatus: http.StatusForbidden,
+		ErrorCode:  "AccessDenied",
+	}, entries[1])
+}
+
+type cancelKey struct{}
+
+func Test_WithLogNonAPIError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := stubConfig(nil)
+	cfg.HTTPClient = &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Context().Value(cancelKey{}) == nil {
+				return nil, errors.New("dial tcp 10.0.0.1:443: connection refused")
+			}
+			// the call is canceled while the request is in flight
+			cancel()
+			return nil, req.Context().Err()
+		}),
+	}
+
+	var buf bytes.Buffer
+	client := s3.NewFromConfig(WithLog(cfg, NewLog(&buf)))
+
+	_, err := client.ListBuckets(context.Background(), &s3.ListBucketsInput{})
+	require.Error(t, err)
+	_, err = client.ListBuckets(context.WithValue(ctx, cancelKey{}, true), &s3.ListBucketsInput{})
+	require.Error(t, err)
+
+	// the message of the error is not recorded, only the class of the error
+	assert.NotContains(t, buf.String(), "connection refused")
+
+	var codes []string
+	decoder := json.NewDecoder(&buf)
+	for decoder.More() {
+		var entry Entry
+		require.NoError(t, decoder.Decode(&entry))
+		assert.Equal(t, StatusError, entry.Status)
+		assert.Zero(t, entry.HTTPStatus)
+		codes = append(codes, entry.ErrorCode)
+	}
+	assert.Equal(t, []string{ErrorCodeTransport, ErrorCodeCanceled}, codes)
+}
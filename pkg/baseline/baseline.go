@@ -0,0 +1,135 @@
+package baseline
+
+import (
+	"os"
+	"sort"
+	"time"
+
+	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// Baseline records accepted failures, so that only newly introduced misconfigurations fail a scan
+type Baseline struct {
+	Entries []Entry `yaml:"entries"`
+}
+
+// Entry accepts the failure of a check, identified by its AVD ID, for a resource
+type Entry struct {
+	Resource string    `yaml:"resource"`
+	AVDID    string    `yaml:"avd_id"`
+	Owner    string    `yaml:"owner,omitempty"`
+	Expires  time.Time `yaml:"expires,omitempty"`
+}
+
+// Expired returns whether the entry is no longer accepted at the given time
+func (e Entry) Expired(now time.Time) bool {
+	return !e.Expires.IsZero() && now.After(e.Expires)
+}
+
+type entryKey struct {
+	resource string
+	avdID    string
+}
+
+// Load reads a baseline file
+func Load(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, xerrors.Errorf("unable to read baseline: %w", err)
+	}
+
+	var b Baseline
+	if err := yaml.Unmarshal(data, &b); err != nil {
+		return nil, xerrors.Errorf("unable to decode baseline: %w", err)
+	}
+	return &b, nil
+}
+
+// Write writes the baseline file
+func Write(path string, b *Baseline) error {
+	data, err := yaml.Marshal(b)
+	if err != nil {
+		return xerrors.Errorf("unable to encode baseline: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return xerrors.Errorf("unable to write baseline: %w", err)
+	}
+	return nil
+}
+
+// FromResults creates a baseline accepting every failure of the results. The owner and expiry of
+// entries of the previous baseline, if any, are kept.
+func FromResults(results types.Results, previous *Baseline) *Baseline {
+	existing := make(map[entryKey]Entry)
+	if previous != nil {
+		for _, entry := range previous.Entries {
+			existing[entryKey{resource: entry.Resource, avdID: entry.AVDID}] = entry
+		}
+	}
+
+	seen := make(map[entryKey]struct{})
+	b := &Baseline{}
+	for _, result := range results {
+		for _, misconfiguration := range result.Misconfigurations {
+			if misconfiguration.Status != types.MisconfStatusFailure {
+				continue
+			}
+			key := entryKey{resource: misconfiguration.CauseMetadata.Resource, avdID: misconfiguration.AVDID}
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+
+			entry, ok := existing[key]
+			if !ok {
+				entry = Entry{Resource: key.resource, AVDID: key.avdID}
+			}
+			b.Entries = append(b.Entries, entry)
+		}
+	}
+
+	sort.Slice(b.Entries, func(i, j int) bool {
+		if b.Entries[i].Resource != b.Entries[j].Resource {
+			return b.Entries[i].Resource < b.Entries[j].Resource
+		}
+		return b.Entries[i].AVDID < b.Entries[j].AVDID
+	})
+	return b
+}
+
+// Apply marks the failures accepted by the unexpired entries of the baseline as exceptions.
+// The failures of expired entries are left as they are.
+func (b *Baseline) Apply(results types.Results, now time.Time) {
+	accepted := make(map[entryKey]bool)
+	for _, entry := range b.Entries {
+		if !entry.Expired(now) {
+			accepted[entryKey{resource: entry.Resource, avdID: entry.AVDID}] = true
+		}
+	}
+
+	for i := range results {
+		misconfigurations := results[i].Misconfigurations
+		for j := range misconfigurations {
+			if misconfigurations[j].Status != types.MisconfStatusFailure {
+				continue
+			}
+			if accepted[entryKey{resource: misconfigurations[j].CauseMetadata.Resource, avdID: misconfigurations[j].AVDID}] {
+				misconfigurations[j].Status = types.MisconfStatusException
+			}
+		}
+	}
+}
+
+// Expired returns the entries of the baseline which have expired at the given time
+func (b *Baseline) Expired(now time.Time) []Entry {
+	var expired []Entry
+	for _, entry := range b.Entries {
+		if entry.Expired(now) {
+			expired = append(expired, entry)
+		}
+	}
+	return expired
+}
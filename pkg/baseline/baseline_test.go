@@ -0,0 +1,123 @@
+package baseline
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ftypes "github.com/aquasecurity/trivy/pkg/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+func failure(resource, avdID string) types.DetectedMisconfiguration {
+	return types.DetectedMisconfiguration{
+		AVDID:  avdID,
+		Status: types.MisconfStatusFailure,
+		CauseMetadata: ftypes.CauseMetadata{
+			Resource: resource,
+		},
+	}
+}
+
+func TestFromResults(t *testing.T) {
+	expires := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	previous := &Baseline{
+		Entries: []Entry{
+			{Resource: "arn:aws:s3:::legacy", AVDID: "AVD-AWS-0086", Owner: "payments", Expires: expires},
+			{Resource: "arn:aws:s3:::fixed", AVDID: "AVD-AWS-0086", Owner: "search"},
+		},
+	}
+
+	passed := failure("arn:aws:s3:::legacy", "AVD-AWS-0088")
+	passed.Status = types.MisconfStatusPassed
+
+	results := types.Results{
+		{
+			Misconfigurations: []types.DetectedMisconfiguration{
+				failure("arn:aws:s3:::new", "AVD-AWS-0086"),
+				failure("arn:aws:s3:::legacy", "AVD-AWS-0086"),
+				passed,
+			},
+		},
+	}
+
+	b := FromResults(results, previous)
+	assert.Equal(t, []Entry{
+		{Resource: "arn:aws:s3:::legacy", AVDID: "AVD-AWS-0086", Owner: "payments", Expires: expires},
+		{Resource: "arn:aws:s3:::new", AVDID: "AVD-AWS-0086"},
+	}, b.Entries)
+}
+
+func TestBaseline_Apply(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	b := &Baseline{
+		Entries: []Entry{
+			{Resource: "arn:aws:s3:::accepted", AVDID: "AVD-AWS-0086"},
+			{Resource: "arn:aws:s3:::expired", AVDID: "AVD-AWS-0086", Expires: now.AddDate(0, 0, -1)},
+			{Resource: "arn:aws:s3:::future", AVDID: "AVD-AWS-0086", Expires: now.AddDate(0, 0, 1)},
+		},
+	}
+
+	results := types.Results{
+		{
+			Misconfigurations: []types.DetectedMisconfiguration{
+				failure("arn:aws:s3:::accepted", "AVD-AWS-0086"),
+				failure("arn:aws:s3:::accepted", "AVD-AWS-0088"),
+				failure("arn:aws:s3:::expired", "AVD-AWS-0086"),
+				failure("arn:aws:s3:::future", "AVD-AWS-0086"),
+			},
+		},
+	}
+
+	b.Apply(results, now)
+
+	var statuses []types.MisconfStatus
+	for _, misconfiguration := range results[0].Misconfigurations {
+		statuses = append(statuses, misconfiguration.Status)
+	}
+	assert.Equal(t, []types.MisconfStatus{
+		types.MisconfStatusException,
+		types.MisconfStatusFailure,
+		types.MisconfStatusFailure,
+		types.MisconfStatusException,
+	}, statuses)
+	assert.True(t, results.Failed())
+
+	expired := b.Expired(now)
+	require.Len(t, expired, 1)
+	assert.Equal(t, "arn:aws:s3:::expired", expired[0].Resource)
+}
+
+func TestWriteAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.yaml")
+	b := &Baseline{
+		Entries: []Entry{
+			{Resource: "arn:aws:s3:::legacy", AVDID: "AVD-AWS-0086", Owner: "payments", Expires: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)},
+			{Resource: "arn:aws:s3:::other", AVDID: "AVD-AWS-0088"},
+		},
+	}
+	require.NoError(t, Write(path, b))
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, b, loaded)
+}
+
+func TestLoad_DateOnlyExpiry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`entries:
+  - resource: arn:aws:s3:::legacy
+    avd_id: AVD-AWS-0086
+    owner: payments
+    expires: 2030-01-01
+`), 0600))
+
+	b, err := Load(path)
+	require.NoError(t, err)
+	require.Len(t, b.Entries, 1)
+	assert.Equal(t, time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), b.Entries[0].Expires)
+}
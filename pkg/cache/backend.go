@@ -0,0 +1,108 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gofrs/flock"
+)
+
+// Backend stores the files of the cache, which are named by slash separated paths relative to the root
+// of the cache, e.g. cloud/aws/123456789012/us-east-1/metadata.json
+type Backend interface {
+	// Read returns the content of the named file, or ErrCacheNotFound if there is no such file
+	Read(ctx context.Context, name string) ([]byte, error)
+	// Write replaces the content of the named file
+	Write(ctx context.Context, name string, content []byte) error
+	// Delete removes the named file, if there is such a file
+	Delete(ctx context.Context, name string) error
+}
+
+// Locker is implemented by the backends which can lock the cache against writers in other processes, such
+// as other pipelines scanning the same account and region
+type Locker interface {
+	// Lock waits until it holds the named lock, returning the function which releases it
+	Lock(ctx context.Context, name string) (func() error, error)
+}
+
+// NewBackend returns the backend of the given location, which is either "fs" or empty for the local cache
+// directory, or an s3:// URL of the bucket and key prefix to store the cache in, such as s3://bucket/trivy
+func NewBackend(ctx context.Context, location, cacheDir string, s3Options S3Options) (Backend, error) {
+	switch {
+	case location == "" || location == "fs":
+		return NewFSBackend(cacheDir), nil
+	case strings.HasPrefix(location, "s3://"):
+		return NewS3BackendFromURL(ctx, location, s3Options)
+	default:
+		return nil, errors.New("unsupported cache backend " + location + ", expected fs or an s3:// URL")
+	}
+}
+
+// FSBackend stores the cache in a local directory
+type FSBackend struct {
+	dir string
+}
+
+func NewFSBackend(dir string) *FSBackend {
+	return &FSBackend{dir: dir}
+}
+
+func (b *FSBackend) path(name string) string {
+	return filepath.Join(b.dir, filepath.FromSlash(name))
+}
+
+func (b *FSBackend) Read(_ context.Context, name string) ([]byte, error) {
+	content, err := os.ReadFile(b.path(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrCacheNotFound
+	}
+	return content, err
+}
+
+// Write replaces the file atomically by renaming a complete temporary file over it, so that readers
+// never see a partially written file
+func (b *FSBackend) Write(_ context.Context, name string, content []byte) error {
+	target := b.path(name)
+	if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(target), "."+filepath.Base(target)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(f.Name()) }()
+
+	if _, err := f.Write(content); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), target)
+}
+
+func (b *FSBackend) Delete(_ context.Context, name string) error {
+	if err := os.Remove(b.path(name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Lock locks the named file with an advisory lock of the operating system, which is released when the
+// process exits, so that a crashed scan cannot leave the cache locked
+func (b *FSBackend) Lock(ctx context.Context, name string) (func() error, error) {
+	if err := os.MkdirAll(filepath.Dir(b.path(name)), 0700); err != nil {
+		return nil, err
+	}
+	lock := flock.New(b.path(name), flock.SetPermissions(0600))
+	if _, err := lock.TryLockContext(ctx, 100*time.Millisecond); err != nil {
+		return nil, err
+	}
+	return lock.Unlock, nil
+}
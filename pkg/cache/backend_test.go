@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_FSBackend(t *testing.T) {
+	dir := t.TempDir()
+	backend := NewFSBackend(dir)
+
+	_, err := backend.Read(t.Context(), "cloud/metadata.json")
+	require.ErrorIs(t, err, ErrCacheNotFound)
+
+	require.NoError(t, backend.Write(t.Context(), "cloud/metadata.json", []byte("first")))
+	require.NoError(t, backend.Write(t.Context(), "cloud/metadata.json", []byte("second")))
+
+	content, err := backend.Read(t.Context(), "cloud/metadata.json")
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(content))
+
+	// the temporary files the content was written to are renamed away
+	entries, err := os.ReadDir(filepath.Join(dir, "cloud"))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "metadata.json", entries[0].Name())
+
+	info, err := entries[0].Info()
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	require.NoError(t, backend.Delete(t.Context(), "cloud/metadata.json"))
+	require.NoError(t, backend.Delete(t.Context(), "cloud/metadata.json"))
+	_, err = backend.Read(t.Context(), "cloud/metadata.json")
+	require.ErrorIs(t, err, ErrCacheNotFound)
+}
+
+func Test_FSBackendLock(t *testing.T) {
+	backend := NewFSBackend(t.TempDir())
+
+	unlock, err := backend.Lock(t.Context(), "cloud/.lock")
+	require.NoError(t, err)
+
+	// another holder has to wait until the lock is released
+	ctx, cancel := context.WithTimeout(t.Context(), 300*time.Millisecond)
+	defer cancel()
+	_, err = NewFSBackend(backend.dir).Lock(ctx, "cloud/.lock")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	require.NoError(t, unlock())
+
+	unlock, err = NewFSBackend(backend.dir).Lock(t.Context(), "cloud/.lock")
+	require.NoError(t, err)
+	require.NoError(t, unlock())
+}
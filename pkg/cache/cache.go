@@ -1,29 +1,59 @@
 package cache
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
+	"maps"
 	"path"
-	"path/filepath"
+	"reflect"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/aquasecurity/trivy-aws/pkg/errs"
 	"github.com/aquasecurity/trivy/pkg/iac/state"
+	iacTypes "github.com/aquasecurity/trivy/pkg/iac/types"
+	"github.com/aquasecurity/trivy/pkg/log"
 )
 
+var metadataType = reflect.TypeOf(iacTypes.Metadata{})
+
+// Cache stores the state of a region sharded by service, each service in a file of its own, so that
+// refreshing a service leaves the others untouched. The services are listed in the metadata file of the
+// region, along with the time they were last refreshed and the tags of their resources, so that each of
+// them expires on its own. The file of a service is only read and verified once its state is used.
 type Cache struct {
-	path      string
+	backend Backend
+	// dir is the directory of the region within the backend
+	dir       string
+	scope     string
 	accountID string
 	region    string
 	maxAge    time.Duration
+
+	encryption *Encryption
+	// states holds the state of the services loaded so far, keyed by service
+	states map[string]*state.State
 }
 
-const SchemaVersion = 2
+const SchemaVersion = 5
+
+const (
+	metadataFile = "metadata.json"
+	servicesDir  = "services"
+	// legacyFile is the cache of schema 2 and older, which held the state of every service in one file
+	legacyFile = "data.json"
+	// lockFile serialises the writers of the region, which may be in other processes
+	lockFile = ".lock"
+)
 
+// CacheData is the metadata of the cached services of a region
 type CacheData struct {
 	SchemaVersion int                        `json:"schema_version"`
-	State         *state.State               `json:"state"`
 	Services      map[string]ServiceMetadata `json:"service_metadata"`
 	Updated       time.Time                  `json:"updated"`
 }
@@ -31,31 +61,62 @@ type CacheData struct {
 type ServiceMetadata struct {
 	Name    string    `json:"name"`
 	Updated time.Time `json:"updated"`
+	// Digest is the SHA-256 of the file holding the state of the service, so that the file cannot be
+	// replaced with an older one without the metadata being replaced as well
+	Digest string `json:"digest"`
+
+	// ScanErrors holds the errors which left the service not fully checked
+	ScanErrors []errs.ScanError `json:"scan_errors,omitempty"`
+	// Tags holds the tags of the resources of the service, keyed by ARN
+	Tags map[string]map[string]string `json:"tags,omitempty"`
+}
+
+// ServiceData is the cached state of a single service
+type ServiceData struct {
+	SchemaVersion int          `json:"schema_version"`
+	Name          string       `json:"name"`
+	State         *state.State `json:"state"`
 }
 
 var ErrCacheNotFound = fmt.Errorf("cache record not found")
 var ErrCacheIncompatible = fmt.Errorf("cache record used incomatible schema")
 var ErrCacheExpired = fmt.Errorf("cache record expired")
+var ErrCacheCorrupt = fmt.Errorf("cache record is corrupt")
 
-func New(cacheDir string, maxCacheAge time.Duration, accountID, region string) *Cache {
+// unusable returns whether the error means the cache record cannot be used and should be replaced, rather
+// than that the backend failed to read it
+func unusable(err error) bool {
+	return errors.Is(err, ErrCacheNotFound) || errors.Is(err, ErrCacheIncompatible) ||
+		errors.Is(err, ErrCacheCorrupt) || errors.Is(err, ErrCacheTampered)
+}
+
+func New(backend Backend, maxCacheAge time.Duration, partition, accountID, region string) *Cache {
+	scope := path.Join(partition, accountID, strings.ToLower(region))
 	return &Cache{
-		path:      path.Join(cacheDir, "cloud", "aws", accountID, strings.ToLower(region), "data.json"),
+		backend:   backend,
+		dir:       path.Join("cloud", scope),
+		scope:     scope,
 		accountID: accountID,
 		region:    region,
 		maxAge:    maxCacheAge,
+		states:    make(map[string]*state.State),
 	}
 }
 
-func (c *Cache) load() (*CacheData, error) {
+// SetEncryption encrypts the files written to the cache from now on, and rejects the files read from it
+// which are not encrypted with the same key
+func (c *Cache) SetEncryption(encryption *Encryption) {
+	c.encryption = encryption
+}
 
-	m, err := os.Open(c.path)
-	if err != nil {
-		return nil, ErrCacheNotFound
-	}
-	defer func() { _ = m.Close() }()
+func serviceFile(service string) string {
+	return path.Join(servicesDir, service+".json")
+}
+
+func (c *Cache) load(ctx context.Context) (*CacheData, error) {
 
 	var data CacheData
-	if err := json.NewDecoder(m).Decode(&data); err != nil {
+	if _, err := c.readJSON(ctx, metadataFile, &data); err != nil {
 		return nil, err
 	}
 
@@ -63,27 +124,79 @@ func (c *Cache) load() (*CacheData, error) {
 		return nil, ErrCacheIncompatible
 	}
 
-	if time.Since(data.Updated) > c.maxAge {
-		return nil, ErrCacheExpired
+	return &data, nil
+}
+
+func (c *Cache) loadService(ctx context.Context, service string, metadata ServiceMetadata) (*state.State, error) {
+	if s, ok := c.states[service]; ok {
+		return s, nil
 	}
 
-	return &data, nil
+	var data ServiceData
+	digest, err := c.readJSON(ctx, serviceFile(service), &data)
+	if err != nil {
+		return nil, err
+	}
+	if digest != metadata.Digest {
+		return nil, fmt.Errorf("%w: %s does not match the metadata", ErrCacheTampered, serviceFile(service))
+	}
+	if data.SchemaVersion != SchemaVersion {
+		return nil, ErrCacheIncompatible
+	}
+	if data.State == nil {
+		data.State = &state.State{}
+	}
+	c.states[service] = data.State
+	return data.State, nil
+}
+
+// validServices returns the sorted names of the cached services which have not expired yet, as told by
+// the metadata alone. Whether the state of a service can be loaded is only checked once it is used.
+func (c *Cache) validServices(data *CacheData) []string {
+	var services []string
+	for name, metadata := range data.Services {
+		if time.Since(metadata.Updated) > c.maxAge || metadata.Digest == "" {
+			continue
+		}
+		services = append(services, name)
+	}
+	slices.Sort(services)
+	return services
+}
+
+// loadValidService loads the state of a service which has not expired yet. A state which cannot be loaded,
+// e.g. as it is corrupt or was tampered with, is reported as missing so that the service is refreshed.
+func (c *Cache) loadValidService(ctx context.Context, service string, metadata ServiceMetadata) (*state.State, bool) {
+	s, err := c.loadService(ctx, service, metadata)
+	if err != nil {
+		if !errors.Is(err, ErrCacheNotFound) {
+			log.WithPrefix("cache").Warn("Ignoring the cached state of a service",
+				log.String("service", service), log.Err(err))
+		}
+		return nil, false
+	}
+	return s, true
 }
 
-func (c *Cache) ListServices(required []string) (included, missing []string) {
+// ListServices splits the required services into those which can be loaded from the cache and those
+// which are missing from it. Only the state of the required services is read.
+func (c *Cache) ListServices(ctx context.Context, required []string) (included, missing []string) {
 
-	data, err := c.load()
+	data, err := c.load(ctx)
 	if err != nil {
+		if !errors.Is(err, ErrCacheNotFound) {
+			log.WithPrefix("cache").Warn("Ignoring the cache", log.Err(err))
+		}
 		return nil, required
 	}
 
+	valid := c.validServices(data)
 	for _, service := range required {
-		metadata, ok := data.Services[service]
-		if !ok {
+		if !slices.Contains(valid, service) {
 			missing = append(missing, service)
 			continue
 		}
-		if time.Since(metadata.Updated) > c.maxAge {
+		if _, ok := c.loadValidService(ctx, service, data.Services[service]); !ok {
 			missing = append(missing, service)
 			continue
 		}
@@ -93,40 +206,261 @@ func (c *Cache) ListServices(required []string) (included, missing []string) {
 	return included, missing
 }
 
-func (c *Cache) LoadState() (*state.State, error) {
-	data, err := c.load()
+// LoadState returns the state of every cached service which has not expired yet and can be loaded
+func (c *Cache) LoadState(ctx context.Context) (*state.State, error) {
+	data, err := c.load(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return data.State, nil
+
+	services := c.validServices(data)
+	if len(services) == 0 {
+		return nil, ErrCacheExpired
+	}
+
+	merged := &state.State{}
+	for _, service := range services {
+		serviceState, ok := c.loadValidService(ctx, service, data.Services[service])
+		if !ok {
+			continue
+		}
+		if merged, err = merged.Merge(serviceState); err != nil {
+			return nil, err
+		}
+	}
+	return merged, nil
+}
+
+// LoadTags returns the tags of the resources of every cached service which has not expired yet
+func (c *Cache) LoadTags(ctx context.Context) (map[string]map[string]string, error) {
+	data, err := c.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	services := c.validServices(data)
+	if len(services) == 0 {
+		return nil, ErrCacheExpired
+	}
+	tags := make(map[string]map[string]string)
+	for _, service := range services {
+		maps.Copy(tags, data.Services[service].Tags)
+	}
+	return tags, nil
+}
+
+// LoadUpdated returns the time each cached service which has not expired yet was last adapted in full,
+// keyed by service
+func (c *Cache) LoadUpdated(ctx context.Context) (map[string]time.Time, error) {
+	data, err := c.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	updated := make(map[string]time.Time)
+	for _, service := range c.validServices(data) {
+		updated[service] = data.Services[service].Updated
+	}
+	return updated, nil
+}
+
+// LoadScanErrors returns the errors of the adaptation of every cached service which has not expired yet
+func (c *Cache) LoadScanErrors(ctx context.Context) ([]errs.ScanError, error) {
+	data, err := c.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var scanErrors []errs.ScanError
+	for _, service := range c.validServices(data) {
+		scanErrors = append(scanErrors, data.Services[service].ScanErrors...)
+	}
+	errs.SortScanErrors(scanErrors)
+	return scanErrors, nil
+}
+
+// AddServices stores the state and errors of the services which were adapted in full, which are marked
+// as updated. The cached state of every other service is left untouched.
+func (c *Cache) AddServices(ctx context.Context, s *state.State, tags map[string]map[string]string, scanErrors []errs.ScanError, includedServices []string) error {
+	return c.write(ctx, s, tags, scanErrors, includedServices, true)
+}
+
+// UpdateServices stores the state and errors of cached services some resources of which were refreshed,
+// keeping the time the services were last adapted in full. Services which are not cached are skipped.
+func (c *Cache) UpdateServices(ctx context.Context, s *state.State, tags map[string]map[string]string, scanErrors []errs.ScanError, services []string) error {
+	return c.write(ctx, s, tags, scanErrors, services, false)
 }
 
-func (c *Cache) AddServices(s *state.State, includedServices []string) error {
-	data := &CacheData{
-		SchemaVersion: SchemaVersion,
-		State:         s,
-		Services:      make(map[string]ServiceMetadata),
-		Updated:       time.Now(),
+func (c *Cache) write(ctx context.Context, s *state.State, tags map[string]map[string]string, scanErrors []errs.ScanError, services []string, refreshed bool) error {
+	// the metadata is read and written under the lock, so that the services written by a concurrent scan
+	// of the region are kept
+	if locker, ok := c.backend.(Locker); ok {
+		unlock, err := locker.Lock(ctx, path.Join(c.dir, lockFile))
+		if err != nil {
+			return fmt.Errorf("failed to lock cache: %w", err)
+		}
+		defer func() { _ = unlock() }()
 	}
 
-	if previous, err := c.load(); err == nil {
-		data.Services = previous.Services
+	data, err := c.load(ctx)
+	switch {
+	case err == nil:
+	case unusable(err):
+		data = &CacheData{
+			Services: make(map[string]ServiceMetadata),
+		}
+	default:
+		return err
 	}
+	data.SchemaVersion = SchemaVersion
+	data.Updated = time.Now()
+
+	for _, service := range services {
+		metadata, ok := data.Services[service]
+		switch {
+		case refreshed:
+			metadata = ServiceMetadata{
+				Name:    service,
+				Updated: time.Now(),
+			}
+		case !ok:
+			continue
+		}
+		metadata.ScanErrors = serviceErrors(scanErrors, service)
 
-	for _, service := range includedServices {
-		data.Services[service] = ServiceMetadata{
-			Name:    service,
-			Updated: time.Now(),
+		filtered := serviceState(s, service)
+		metadata.Tags = serviceTags(filtered, tags)
+		metadata.Digest, err = c.writeJSON(ctx, serviceFile(service), ServiceData{
+			SchemaVersion: SchemaVersion,
+			Name:          service,
+			State:         filtered,
+		})
+		if err != nil {
+			return err
 		}
+		c.states[service] = filtered
+		data.Services[service] = metadata
 	}
 
-	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+	if _, err := c.writeJSON(ctx, metadataFile, data); err != nil {
 		return err
 	}
-	f, err := os.Create(c.path)
+
+	// the cache of the previous schema can no longer be used
+	_ = c.backend.Delete(ctx, path.Join(c.dir, legacyFile))
+	return nil
+}
+
+// readJSON decodes the named file of the cache, decrypting it first if the cache is encrypted, and returns
+// the digest of the file as stored
+func (c *Cache) readJSON(ctx context.Context, name string, v any) (string, error) {
+	content, err := c.backend.Read(ctx, path.Join(c.dir, name))
 	if err != nil {
-		return err
+		return "", err
+	}
+	digest := fileDigest(content)
+	if c.encryption != nil {
+		if content, err = c.encryption.open(path.Join(c.scope, name), content); err != nil {
+			return "", err
+		}
+	}
+	if err := json.Unmarshal(content, v); err != nil {
+		return "", fmt.Errorf("%w: failed to decode %s: %v", ErrCacheCorrupt, name, err)
+	}
+	return digest, nil
+}
+
+// writeJSON encodes the named file of the cache, encrypting it if the cache is encrypted, and returns the
+// digest of the file as stored. The name and scope of the file are bound to the encrypted content, so that
+// it cannot be moved within the cache.
+func (c *Cache) writeJSON(ctx context.Context, name string, v any) (string, error) {
+	content, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	if c.encryption != nil {
+		if content, err = c.encryption.seal(path.Join(c.scope, name), content); err != nil {
+			return "", err
+		}
+	}
+	if err := c.backend.Write(ctx, path.Join(c.dir, name), content); err != nil {
+		return "", err
+	}
+	return fileDigest(content), nil
+}
+
+func fileDigest(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// serviceTags returns the tags of the resources held by the state of a service
+func serviceTags(s *state.State, tags map[string]map[string]string) map[string]map[string]string {
+	if len(tags) == 0 {
+		return nil
+	}
+	filtered := make(map[string]map[string]string)
+	collectTags(reflect.ValueOf(s.AWS), tags, filtered)
+	if len(filtered) == 0 {
+		return nil
+	}
+	return filtered
+}
+
+// collectTags adds the tags of the resources referenced by the metadata found within v to filtered
+func collectTags(v reflect.Value, tags, filtered map[string]map[string]string) {
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.Type() == metadataType {
+			reference := v.Interface().(iacTypes.Metadata).Reference()
+			if resourceTags, ok := tags[reference]; ok {
+				filtered[reference] = resourceTags
+			}
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				collectTags(v.Field(i), tags, filtered)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			collectTags(v.Index(i), tags, filtered)
+		}
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			collectTags(v.Elem(), tags, filtered)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			collectTags(iter.Value(), tags, filtered)
+		}
+	}
+}
+
+func serviceErrors(scanErrors []errs.ScanError, service string) []errs.ScanError {
+	var filtered []errs.ScanError
+	for _, scanErr := range scanErrors {
+		if scanErr.Service == service {
+			filtered = append(filtered, scanErr)
+		}
+	}
+	return filtered
+}
+
+// serviceState returns a state holding only the resources of the service. The state of each service is
+// held by the field of the AWS state named after it, e.g. the state of api-gateway by APIGateway.
+func serviceState(s *state.State, service string) *state.State {
+	var filtered state.State
+	if s == nil {
+		return &filtered
+	}
+
+	name := strings.ReplaceAll(service, "-", "")
+	source := reflect.ValueOf(&s.AWS).Elem()
+	target := reflect.ValueOf(&filtered.AWS).Elem()
+	for i := 0; i < source.NumField(); i++ {
+		if strings.EqualFold(source.Type().Field(i).Name, name) {
+			target.Field(i).Set(source.Field(i))
+		}
 	}
-	defer func() { _ = f.Close() }()
-	return json.NewEncoder(f).Encode(data)
+	return &filtered
 }
@@ -0,0 +1,298 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/trivy-aws/pkg/errs"
+	v1 "github.com/aquasecurity/trivy/pkg/iac/providers/aws/apigateway/v1"
+	"github.com/aquasecurity/trivy/pkg/iac/providers/aws/cloudtrail"
+	"github.com/aquasecurity/trivy/pkg/iac/providers/aws/s3"
+	"github.com/aquasecurity/trivy/pkg/iac/state"
+	iacTypes "github.com/aquasecurity/trivy/pkg/iac/types"
+)
+
+func testState(bucket, trail string) *state.State {
+	var s state.State
+	if bucket != "" {
+		metadata := iacTypes.NewRemoteMetadata("arn:aws:s3:::" + bucket)
+		s.AWS.S3.Buckets = []s3.Bucket{{Metadata: metadata, Name: iacTypes.String(bucket, metadata)}}
+	}
+	if trail != "" {
+		metadata := iacTypes.NewRemoteMetadata("arn:aws:cloudtrail:us-east-1:123456789012:trail/" + trail)
+		s.AWS.CloudTrail.Trails = []cloudtrail.Trail{{Metadata: metadata, Name: iacTypes.String(trail, metadata)}}
+	}
+	return &s
+}
+
+func Test_Cache(t *testing.T) {
+	cacheDir := t.TempDir()
+	c := New(NewFSBackend(cacheDir), time.Hour, "aws", "123456789012", "us-east-1")
+
+	scanErrors := []errs.ScanError{
+		{Service: "cloudtrail", Region: "us-east-1", Class: errs.ClassAccessDenied, Count: 1},
+		{Service: "s3", Region: "us-east-1", Resource: "bucket", Class: errs.ClassOther, Count: 1},
+	}
+	require.NoError(t, c.AddServices(t.Context(), testState("bucket", "trail"), nil, scanErrors, []string{"s3", "cloudtrail"}))
+
+	// each service is stored in a file of its own
+	assert.FileExists(t, cacheFile(cacheDir, c, "services", "s3.json"))
+	assert.FileExists(t, cacheFile(cacheDir, c, "services", "cloudtrail.json"))
+
+	included, missing := c.ListServices(t.Context(), []string{"s3", "cloudtrail", "iam"})
+	assert.Equal(t, []string{"s3", "cloudtrail"}, included)
+	assert.Equal(t, []string{"iam"}, missing)
+
+	cached, err := c.LoadState(t.Context())
+	require.NoError(t, err)
+	require.Len(t, cached.AWS.S3.Buckets, 1)
+	require.Len(t, cached.AWS.CloudTrail.Trails, 1)
+
+	cachedErrors, err := c.LoadScanErrors(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, scanErrors, cachedErrors)
+
+	// refreshing a service leaves the others untouched
+	trailFile := cacheFile(cacheDir, c, "services", "cloudtrail.json")
+	trailData, err := os.ReadFile(trailFile)
+	require.NoError(t, err)
+
+	require.NoError(t, c.AddServices(t.Context(), testState("other", ""), nil, nil, []string{"s3"}))
+
+	refreshedTrailData, err := os.ReadFile(trailFile)
+	require.NoError(t, err)
+	assert.Equal(t, trailData, refreshedTrailData)
+
+	cached, err = c.LoadState(t.Context())
+	require.NoError(t, err)
+	require.Len(t, cached.AWS.S3.Buckets, 1)
+	assert.Equal(t, "other", cached.AWS.S3.Buckets[0].Name.Value())
+	require.Len(t, cached.AWS.CloudTrail.Trails, 1)
+
+	cachedErrors, err = c.LoadScanErrors(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, scanErrors[:1], cachedErrors)
+
+	// a service which expired is left out, while the others are still used
+	ageService(t, c, "cloudtrail", 2*time.Hour)
+
+	included, missing = c.ListServices(t.Context(), []string{"s3", "cloudtrail"})
+	assert.Equal(t, []string{"s3"}, included)
+	assert.Equal(t, []string{"cloudtrail"}, missing)
+
+	cached, err = c.LoadState(t.Context())
+	require.NoError(t, err)
+	assert.Len(t, cached.AWS.S3.Buckets, 1)
+	assert.Empty(t, cached.AWS.CloudTrail.Trails)
+
+	cachedErrors, err = c.LoadScanErrors(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, cachedErrors)
+}
+
+func Test_UpdateServices(t *testing.T) {
+	c := New(NewFSBackend(t.TempDir()), time.Hour, "aws", "123456789012", "us-east-1")
+
+	require.NoError(t, c.AddServices(t.Context(), testState("bucket", ""), nil, nil, []string{"s3"}))
+	ageService(t, c, "s3", 30*time.Minute)
+
+	data, err := c.load(t.Context())
+	require.NoError(t, err)
+	updated := data.Services["s3"].Updated
+
+	// only some resources were refreshed, so the time of the last full refresh is kept
+	require.NoError(t, c.UpdateServices(t.Context(), testState("other", "trail"), nil, nil, []string{"s3", "cloudtrail"}))
+
+	data, err = c.load(t.Context())
+	require.NoError(t, err)
+	assert.True(t, updated.Equal(data.Services["s3"].Updated))
+	assert.NotContains(t, data.Services, "cloudtrail")
+
+	cached, err := c.LoadState(t.Context())
+	require.NoError(t, err)
+	require.Len(t, cached.AWS.S3.Buckets, 1)
+	assert.Equal(t, "other", cached.AWS.S3.Buckets[0].Name.Value())
+	assert.Empty(t, cached.AWS.CloudTrail.Trails)
+}
+
+func Test_CorruptCache(t *testing.T) {
+	cacheDir := t.TempDir()
+	c := New(NewFSBackend(cacheDir), time.Hour, "aws", "123456789012", "us-east-1")
+	require.NoError(t, c.AddServices(t.Context(), testState("bucket", "trail"), nil, nil, []string{"s3", "cloudtrail"}))
+
+	// a truncated service is refreshed while the others are still loaded from the cache
+	require.NoError(t, os.WriteFile(cacheFile(cacheDir, c, "services", "s3.json"), []byte(`{"schema_version": 3, "sta`), 0600))
+
+	c = New(NewFSBackend(cacheDir), time.Hour, "aws", "123456789012", "us-east-1")
+	included, missing := c.ListServices(t.Context(), []string{"s3", "cloudtrail"})
+	assert.Equal(t, []string{"cloudtrail"}, included)
+	assert.Equal(t, []string{"s3"}, missing)
+
+	cached, err := c.LoadState(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, cached.AWS.S3.Buckets)
+	assert.Len(t, cached.AWS.CloudTrail.Trails, 1)
+
+	// corrupt metadata leaves every service to be refreshed, and is replaced by the next write
+	require.NoError(t, os.WriteFile(cacheFile(cacheDir, c, metadataFile), []byte("{"), 0600))
+
+	c = New(NewFSBackend(cacheDir), time.Hour, "aws", "123456789012", "us-east-1")
+	included, missing = c.ListServices(t.Context(), []string{"s3", "cloudtrail"})
+	assert.Empty(t, included)
+	assert.Equal(t, []string{"s3", "cloudtrail"}, missing)
+
+	_, err = c.LoadState(t.Context())
+	require.ErrorIs(t, err, ErrCacheCorrupt)
+
+	require.NoError(t, c.AddServices(t.Context(), testState("bucket", "trail"), nil, nil, []string{"s3", "cloudtrail"}))
+	included, _ = c.ListServices(t.Context(), []string{"s3", "cloudtrail"})
+	assert.Equal(t, []string{"s3", "cloudtrail"}, included)
+}
+
+func Test_CacheTags(t *testing.T) {
+	c := New(NewFSBackend(t.TempDir()), time.Hour, "aws", "123456789012", "us-east-1")
+
+	tags := map[string]map[string]string{
+		"arn:aws:s3:::bucket": {"team": "storage"},
+		"arn:aws:cloudtrail:us-east-1:123456789012:trail/trail": {"team": "audit"},
+		"arn:aws:sqs:us-east-1:123456789012:queue":              {"team": "messaging"},
+	}
+	require.NoError(t, c.AddServices(t.Context(), testState("bucket", "trail"), tags, nil, []string{"s3", "cloudtrail"}))
+
+	// the tags are stored with the services holding the resources, so the tags of other resources are left out
+	cached, err := c.LoadTags(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string]string{
+		"arn:aws:s3:::bucket": {"team": "storage"},
+		"arn:aws:cloudtrail:us-east-1:123456789012:trail/trail": {"team": "audit"},
+	}, cached)
+
+	// the tags of a service expire along with its state
+	ageService(t, c, "cloudtrail", 2*time.Hour)
+
+	cached, err = c.LoadTags(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string]string{
+		"arn:aws:s3:::bucket": {"team": "storage"},
+	}, cached)
+
+	ageService(t, c, "s3", 2*time.Hour)
+
+	_, err = c.LoadTags(t.Context())
+	require.ErrorIs(t, err, ErrCacheExpired)
+}
+
+func Test_CacheLoadsUsedServices(t *testing.T) {
+	cacheDir := t.TempDir()
+	c := New(NewFSBackend(cacheDir), time.Hour, "aws", "123456789012", "us-east-1")
+	require.NoError(t, c.AddServices(t.Context(), testState("bucket", "trail"), nil, nil, []string{"s3", "cloudtrail"}))
+	require.NoError(t, os.Remove(cacheFile(cacheDir, c, "services", "cloudtrail.json")))
+
+	// only the state of the required services is read, so the missing file of another service goes unnoticed
+	c = New(NewFSBackend(cacheDir), time.Hour, "aws", "123456789012", "us-east-1")
+	included, missing := c.ListServices(t.Context(), []string{"s3"})
+	assert.Equal(t, []string{"s3"}, included)
+	assert.Empty(t, missing)
+	assert.Contains(t, c.states, "s3")
+	assert.NotContains(t, c.states, "cloudtrail")
+
+	updated, err := c.LoadUpdated(t.Context())
+	require.NoError(t, err)
+	assert.Len(t, updated, 2)
+	assert.NotContains(t, c.states, "cloudtrail")
+
+	// the service is only found to be missing once its state is used
+	included, missing = c.ListServices(t.Context(), []string{"s3", "cloudtrail"})
+	assert.Equal(t, []string{"s3"}, included)
+	assert.Equal(t, []string{"cloudtrail"}, missing)
+
+	cached, err := c.LoadState(t.Context())
+	require.NoError(t, err)
+	assert.Len(t, cached.AWS.S3.Buckets, 1)
+	assert.Empty(t, cached.AWS.CloudTrail.Trails)
+}
+
+func Test_ConcurrentWrites(t *testing.T) {
+	cacheDir := t.TempDir()
+	services := []string{"s3", "cloudtrail", "iam", "ec2", "rds", "sns", "sqs", "kms"}
+
+	// each writer stands for a scan of the region in another process, so it has a cache of its own
+	var wg sync.WaitGroup
+	for _, service := range services {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c := New(NewFSBackend(cacheDir), time.Hour, "aws", "123456789012", "us-east-1")
+			for range 5 {
+				assert.NoError(t, c.AddServices(t.Context(), testState("bucket", "trail"), nil, nil, []string{service}))
+			}
+		}()
+	}
+	wg.Wait()
+
+	c := New(NewFSBackend(cacheDir), time.Hour, "aws", "123456789012", "us-east-1")
+	included, missing := c.ListServices(t.Context(), services)
+	assert.Equal(t, services, included)
+	assert.Empty(t, missing)
+}
+
+func Test_CachePartitions(t *testing.T) {
+	cacheDir := t.TempDir()
+
+	commercial := New(NewFSBackend(cacheDir), time.Hour, "aws", "123456789012", "us-east-1")
+	require.NoError(t, commercial.AddServices(t.Context(), testState("bucket", ""), nil, nil, []string{"s3"}))
+
+	// the same account ID and region in another partition is another account, so it has a cache of its own
+	govCloud := New(NewFSBackend(cacheDir), time.Hour, "aws-us-gov", "123456789012", "us-east-1")
+	_, missing := govCloud.ListServices(t.Context(), []string{"s3"})
+	assert.Equal(t, []string{"s3"}, missing)
+
+	require.NoError(t, govCloud.AddServices(t.Context(), testState("", "trail"), nil, nil, []string{"cloudtrail"}))
+	assert.FileExists(t, filepath.Join(cacheDir, "cloud", "aws", "123456789012", "us-east-1", "services", "s3.json"))
+	assert.FileExists(t, filepath.Join(cacheDir, "cloud", "aws-us-gov", "123456789012", "us-east-1", "services", "cloudtrail.json"))
+
+	china := New(NewFSBackend(cacheDir), time.Hour, "aws-cn", "123456789012", "cn-north-1")
+	assert.Equal(t, "cloud/aws-cn/123456789012/cn-north-1", china.dir)
+
+	included, _ := commercial.ListServices(t.Context(), []string{"s3", "cloudtrail"})
+	assert.Equal(t, []string{"s3"}, included)
+	included, _ = govCloud.ListServices(t.Context(), []string{"s3", "cloudtrail"})
+	assert.Equal(t, []string{"cloudtrail"}, included)
+}
+
+func Test_serviceState(t *testing.T) {
+	s := testState("bucket", "trail")
+
+	filtered := serviceState(s, "s3")
+	assert.Len(t, filtered.AWS.S3.Buckets, 1)
+	assert.Empty(t, filtered.AWS.CloudTrail.Trails)
+
+	s.AWS.APIGateway.V1.APIs = []v1.API{{Metadata: iacTypes.NewRemoteMetadata("arn:aws:apigateway:us-east-1::/restapis/api")}}
+	filtered = serviceState(s, "api-gateway")
+	assert.Len(t, filtered.AWS.APIGateway.V1.APIs, 1)
+	assert.Empty(t, filtered.AWS.S3.Buckets)
+}
+
+// ageService moves the time the service was last refreshed back by the given duration
+func ageService(t *testing.T, c *Cache, service string, age time.Duration) {
+	data, err := c.load(t.Context())
+	require.NoError(t, err)
+
+	metadata := data.Services[service]
+	metadata.Updated = metadata.Updated.Add(-age)
+	data.Services[service] = metadata
+
+	_, err = c.writeJSON(t.Context(), metadataFile, data)
+	require.NoError(t, err)
+}
+
+// cacheFile returns the path of the file of the cache, which is stored in the cache directory
+func cacheFile(cacheDir string, c *Cache, elem ...string) string {
+	return filepath.Join(append([]string{cacheDir, filepath.FromSlash(c.dir)}, elem...)...)
+}
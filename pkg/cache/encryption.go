@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// KeyEnv is the environment variable which holds the key of the cache if no key file is given
+const KeyEnv = "TRIVY_AWS_CACHE_KEY"
+
+// sealedPrefix marks the cache files which are encrypted, so that they are not mistaken for plain JSON
+var sealedPrefix = []byte("trivy-aws-gcm-v1:")
+
+var ErrCacheTampered = errors.New("cache record failed the integrity check")
+
+// Encryption encrypts the cache files with AES-GCM. As GCM authenticates the files as well, a file which
+// was tampered with, or moved from another part of the cache, fails to decrypt rather than being scanned.
+// The metadata of the region holds the digest of each service file, so that a service file cannot be
+// replaced with an older one either; only the files of the region as a whole can be rolled back, until
+// they expire.
+//
+// Without a key the cache is not authenticated at all: anyone able to write to it can change the state
+// which is scanned.
+type Encryption struct {
+	aead cipher.AEAD
+}
+
+// NewEncryption creates the encryption of the cache with a key of 16, 24 or 32 bytes
+func NewEncryption(key []byte) (*Encryption, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cache key: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Encryption{aead: aead}, nil
+}
+
+// LoadEncryption creates the encryption of the cache with the base64 encoded key in the key file, or in the
+// environment variable if no key file is given. No encryption is returned if neither holds a key.
+func LoadEncryption(keyFile string) (*Encryption, error) {
+	encoded := os.Getenv(KeyEnv)
+	if keyFile != "" {
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cache key file: %w", err)
+		}
+		encoded = string(data)
+	}
+	encoded = strings.TrimSpace(encoded)
+	if encoded == "" {
+		return nil, nil
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("cache key is not base64 encoded: %w", err)
+	}
+	return NewEncryption(key)
+}
+
+// seal encrypts the content of the named file, binding the name to it so that it cannot be moved
+func (e *Encryption) seal(name string, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, e.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed := append(bytes.Clone(sealedPrefix), nonce...)
+	return e.aead.Seal(sealed, nonce, plaintext, []byte(name)), nil
+}
+
+// open decrypts the content of the named file, failing if it is not encrypted or was tampered with
+func (e *Encryption) open(name string, sealed []byte) ([]byte, error) {
+	if !bytes.HasPrefix(sealed, sealedPrefix) {
+		return nil, fmt.Errorf("%w: %s is not encrypted", ErrCacheTampered, name)
+	}
+	sealed = sealed[len(sealedPrefix):]
+	if len(sealed) < e.aead.NonceSize() {
+		return nil, fmt.Errorf("%w: %s is truncated", ErrCacheTampered, name)
+	}
+	nonce, ciphertext := sealed[:e.aead.NonceSize()], sealed[e.aead.NonceSize():]
+	plaintext, err := e.aead.Open(nil, nonce, ciphertext, []byte(name))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCacheTampered, name)
+	}
+	return plaintext, nil
+}
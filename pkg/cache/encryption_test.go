@@ -0,0 +1,185 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEncryption(t *testing.T, fill byte) *Encryption {
+	encryption, err := NewEncryption(bytes.Repeat([]byte{fill}, 32))
+	require.NoError(t, err)
+	return encryption
+}
+
+func encryptedCache(cacheDir, accountID string, encryption *Encryption) *Cache {
+	c := New(NewFSBackend(cacheDir), time.Hour, "aws", accountID, "us-east-1")
+	c.SetEncryption(encryption)
+	return c
+}
+
+func Test_EncryptedCache(t *testing.T) {
+	cacheDir := t.TempDir()
+	encryption := testEncryption(t, 1)
+
+	c := encryptedCache(cacheDir, "123456789012", encryption)
+	require.NoError(t, c.AddServices(t.Context(), testState("secret-bucket", "trail"), nil, nil, []string{"s3", "cloudtrail"}))
+
+	for _, name := range []string{metadataFile, filepath.Join(servicesDir, "s3.json")} {
+		content, err := os.ReadFile(cacheFile(cacheDir, c, name))
+		require.NoError(t, err)
+		assert.NotContains(t, string(content), "secret-bucket")
+		assert.NotContains(t, string(content), "schema_version")
+	}
+
+	t.Run("same key", func(t *testing.T) {
+		c := encryptedCache(cacheDir, "123456789012", encryption)
+		included, missing := c.ListServices(t.Context(), []string{"s3", "cloudtrail"})
+		assert.Equal(t, []string{"s3", "cloudtrail"}, included)
+		assert.Empty(t, missing)
+
+		cached, err := c.LoadState(t.Context())
+		require.NoError(t, err)
+		require.Len(t, cached.AWS.S3.Buckets, 1)
+		assert.Equal(t, "secret-bucket", cached.AWS.S3.Buckets[0].Name.Value())
+	})
+
+	t.Run("other key", func(t *testing.T) {
+		c := encryptedCache(cacheDir, "123456789012", testEncryption(t, 2))
+		included, missing := c.ListServices(t.Context(), []string{"s3"})
+		assert.Empty(t, included)
+		assert.Equal(t, []string{"s3"}, missing)
+
+		_, err := c.LoadState(t.Context())
+		require.ErrorIs(t, err, ErrCacheTampered)
+	})
+
+	t.Run("no key", func(t *testing.T) {
+		c := New(NewFSBackend(cacheDir), time.Hour, "aws", "123456789012", "us-east-1")
+		_, missing := c.ListServices(t.Context(), []string{"s3"})
+		assert.Equal(t, []string{"s3"}, missing)
+	})
+}
+
+func Test_EncryptedCacheTampered(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(t *testing.T, cacheDir string, c *Cache)
+	}{
+		{
+			name: "flipped bit",
+			tamper: func(t *testing.T, cacheDir string, c *Cache) {
+				file := cacheFile(cacheDir, c, servicesDir, "s3.json")
+				content, err := os.ReadFile(file)
+				require.NoError(t, err)
+				content[len(content)-1] ^= 1
+				require.NoError(t, os.WriteFile(file, content, 0600))
+			},
+		},
+		{
+			name: "replaced with plaintext",
+			tamper: func(t *testing.T, cacheDir string, c *Cache) {
+				plain := New(NewFSBackend(cacheDir), time.Hour, "aws", "111111111111", "us-east-1")
+				require.NoError(t, plain.AddServices(t.Context(), testState("forged", ""), nil, nil, []string{"s3"}))
+				content, err := os.ReadFile(cacheFile(cacheDir, plain, servicesDir, "s3.json"))
+				require.NoError(t, err)
+				require.NoError(t, os.WriteFile(cacheFile(cacheDir, c, servicesDir, "s3.json"), content, 0600))
+			},
+		},
+		{
+			name: "moved from another service",
+			tamper: func(t *testing.T, cacheDir string, c *Cache) {
+				content, err := os.ReadFile(cacheFile(cacheDir, c, servicesDir, "cloudtrail.json"))
+				require.NoError(t, err)
+				require.NoError(t, os.WriteFile(cacheFile(cacheDir, c, servicesDir, "s3.json"), content, 0600))
+			},
+		},
+		{
+			name: "moved from another account",
+			tamper: func(t *testing.T, cacheDir string, c *Cache) {
+				other := encryptedCache(cacheDir, "111111111111", c.encryption)
+				require.NoError(t, other.AddServices(t.Context(), testState("forged", ""), nil, nil, []string{"s3"}))
+				content, err := os.ReadFile(cacheFile(cacheDir, other, servicesDir, "s3.json"))
+				require.NoError(t, err)
+				require.NoError(t, os.WriteFile(cacheFile(cacheDir, c, servicesDir, "s3.json"), content, 0600))
+			},
+		},
+		{
+			name: "rolled back",
+			tamper: func(t *testing.T, cacheDir string, c *Cache) {
+				file := cacheFile(cacheDir, c, servicesDir, "s3.json")
+				previous, err := os.ReadFile(file)
+				require.NoError(t, err)
+				require.NoError(t, c.AddServices(t.Context(), testState("newer", ""), nil, nil, []string{"s3"}))
+				require.NoError(t, os.WriteFile(file, previous, 0600))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacheDir := t.TempDir()
+			encryption := testEncryption(t, 1)
+
+			c := encryptedCache(cacheDir, "123456789012", encryption)
+			require.NoError(t, c.AddServices(t.Context(), testState("bucket", "trail"), nil, nil, []string{"s3", "cloudtrail"}))
+			tt.tamper(t, cacheDir, c)
+
+			// the tampered service is refreshed while the others are still loaded from the cache
+			c = encryptedCache(cacheDir, "123456789012", encryption)
+			included, missing := c.ListServices(t.Context(), []string{"s3", "cloudtrail"})
+			assert.Equal(t, []string{"cloudtrail"}, included)
+			assert.Equal(t, []string{"s3"}, missing)
+
+			cached, err := c.LoadState(t.Context())
+			require.NoError(t, err)
+			assert.Empty(t, cached.AWS.S3.Buckets)
+			assert.Len(t, cached.AWS.CloudTrail.Trails, 1)
+		})
+	}
+}
+
+func Test_LoadEncryption(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+
+	t.Run("no key", func(t *testing.T) {
+		t.Setenv(KeyEnv, "")
+		encryption, err := LoadEncryption("")
+		require.NoError(t, err)
+		assert.Nil(t, encryption)
+	})
+
+	t.Run("environment variable", func(t *testing.T) {
+		t.Setenv(KeyEnv, key)
+		encryption, err := LoadEncryption("")
+		require.NoError(t, err)
+		assert.NotNil(t, encryption)
+	})
+
+	t.Run("key file", func(t *testing.T) {
+		t.Setenv(KeyEnv, "")
+		keyFile := filepath.Join(t.TempDir(), "cache.key")
+		require.NoError(t, os.WriteFile(keyFile, []byte(key+"\n"), 0600))
+		encryption, err := LoadEncryption(keyFile)
+		require.NoError(t, err)
+		assert.NotNil(t, encryption)
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		t.Setenv(KeyEnv, base64.StdEncoding.EncodeToString([]byte("short")))
+		_, err := LoadEncryption("")
+		require.Error(t, err)
+	})
+
+	t.Run("not base64", func(t *testing.T) {
+		t.Setenv(KeyEnv, "not a key!")
+		_, err := LoadEncryption("")
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,120 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+
+	"github.com/aquasecurity/trivy-aws/pkg/audit"
+)
+
+// S3Options configures the client of the S3 backend
+type S3Options struct {
+	// Region is the region of the bucket, which defaults to the region of the AWS configuration
+	Region string
+	// Endpoint is the URL of an S3 compatible object store to use instead of AWS, such as MinIO
+	Endpoint string
+	// AuditLog records the calls to the bucket along with the calls of the scan, if set
+	AuditLog *audit.Log
+}
+
+// S3Backend stores the cache in an S3 bucket, so that it can be shared by several hosts, e.g. CI runners.
+// The cache is read and written with the credentials of the host rather than the credentials of the
+// scanned accounts. Objects are replaced atomically, but the bucket cannot be locked, so a service cached
+// by one of two concurrent scans of a region may be dropped from the metadata and adapted again later.
+type S3Backend struct {
+	client *s3.Client
+	bucket string
+	prefix string
+}
+
+func NewS3Backend(client *s3.Client, bucket, prefix string) *S3Backend {
+	return &S3Backend{
+		client: client,
+		bucket: bucket,
+		prefix: strings.Trim(prefix, "/"),
+	}
+}
+
+// NewS3BackendFromURL returns the backend of the bucket and key prefix of an s3:// URL, such as
+// s3://bucket/trivy, with a client configured from the default AWS configuration of the host
+func NewS3BackendFromURL(ctx context.Context, location string, opts S3Options) (*S3Backend, error) {
+	u, err := url.Parse(location)
+	if err != nil || u.Scheme != "s3" || u.Host == "" {
+		return nil, fmt.Errorf("invalid S3 cache location %q, expected s3://bucket/prefix", location)
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS configuration of the S3 cache: %w", err)
+	}
+	if opts.Region != "" {
+		cfg.Region = opts.Region
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+
+	client := s3.NewFromConfig(audit.WithLog(cfg, opts.AuditLog), func(o *s3.Options) {
+		if opts.Endpoint != "" {
+			o.BaseEndpoint = aws.String(opts.Endpoint)
+			// S3 compatible object stores seldom support virtual hosted buckets
+			o.UsePathStyle = true
+		}
+	})
+	return NewS3Backend(client, u.Host, u.Path), nil
+}
+
+func (b *S3Backend) key(name string) string {
+	return path.Join(b.prefix, name)
+}
+
+func (b *S3Backend) Read(ctx context.Context, name string) ([]byte, error) {
+	output, err := b.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(b.key(name)),
+	})
+	if err != nil {
+		var notFound *types.NoSuchKey
+		var responseErr *smithyhttp.ResponseError
+		if errors.As(err, &notFound) || (errors.As(err, &responseErr) && responseErr.HTTPStatusCode() == http.StatusNotFound) {
+			return nil, ErrCacheNotFound
+		}
+		return nil, fmt.Errorf("failed to read %s from S3 cache: %w", name, err)
+	}
+	defer func() { _ = output.Body.Close() }()
+	return io.ReadAll(output.Body)
+}
+
+func (b *S3Backend) Write(ctx context.Context, name string, content []byte) error {
+	if _, err := b.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(b.key(name)),
+		Body:   bytes.NewReader(content),
+	}); err != nil {
+		return fmt.Errorf("failed to write %s to S3 cache: %w", name, err)
+	}
+	return nil
+}
+
+func (b *S3Backend) Delete(ctx context.Context, name string) error {
+	if _, err := b.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(b.key(name)),
+	}); err != nil {
+		return fmt.Errorf("failed to delete %s from S3 cache: %w", name, err)
+	}
+	return nil
+}
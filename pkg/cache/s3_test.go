@@ -0,0 +1,153 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/trivy-aws/pkg/audit"
+)
+
+// fakeObjectStore is a minimal stand-in for an S3 compatible object store such as MinIO, which serves
+// the objects of path style requests from memory
+type fakeObjectStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (s *fakeObjectStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := strings.TrimPrefix(r.URL.Path, "/")
+	switch r.Method {
+	case http.MethodGet:
+		content, ok := s.objects[key]
+		if !ok {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, `<Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>`)
+			return
+		}
+		_, _ = w.Write(content)
+	case http.MethodPut:
+		content, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		s.objects[key] = content
+	case http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *fakeObjectStore) keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var keys []string
+	for key := range s.objects {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func newS3Backend(t *testing.T, server *httptest.Server) Backend {
+	return newAuditedS3Backend(t, server, nil)
+}
+
+func newAuditedS3Backend(t *testing.T, server *httptest.Server, auditLog *audit.Log) Backend {
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_CONFIG_FILE", "/dev/null")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "/dev/null")
+
+	backend, err := NewBackend(t.Context(), "s3://cache-bucket/trivy/", t.TempDir(), S3Options{
+		Region:   "eu-west-1",
+		Endpoint: server.URL,
+		AuditLog: auditLog,
+	})
+	require.NoError(t, err)
+	return backend
+}
+
+func Test_S3Backend(t *testing.T) {
+	store := &fakeObjectStore{objects: make(map[string][]byte)}
+	server := httptest.NewServer(store)
+	defer server.Close()
+
+	// one runner fills the cache
+	c := New(newS3Backend(t, server), time.Hour, "aws", "123456789012", "us-east-1")
+	_, missing := c.ListServices(t.Context(), []string{"s3"})
+	assert.Equal(t, []string{"s3"}, missing)
+	require.NoError(t, c.AddServices(t.Context(), testState("bucket", "trail"), nil, nil, []string{"s3", "cloudtrail"}))
+
+	assert.Equal(t, []string{
+		"cache-bucket/trivy/cloud/aws/123456789012/us-east-1/metadata.json",
+		"cache-bucket/trivy/cloud/aws/123456789012/us-east-1/services/cloudtrail.json",
+		"cache-bucket/trivy/cloud/aws/123456789012/us-east-1/services/s3.json",
+	}, store.keys())
+
+	// another runner uses it
+	c = New(newS3Backend(t, server), time.Hour, "aws", "123456789012", "us-east-1")
+	included, missing := c.ListServices(t.Context(), []string{"s3", "cloudtrail", "iam"})
+	assert.Equal(t, []string{"s3", "cloudtrail"}, included)
+	assert.Equal(t, []string{"iam"}, missing)
+
+	cached, err := c.LoadState(t.Context())
+	require.NoError(t, err)
+	require.Len(t, cached.AWS.S3.Buckets, 1)
+	assert.Equal(t, "bucket", cached.AWS.S3.Buckets[0].Name.Value())
+	assert.Len(t, cached.AWS.CloudTrail.Trails, 1)
+}
+
+func Test_S3BackendAuditLog(t *testing.T) {
+	server := httptest.NewServer(&fakeObjectStore{objects: make(map[string][]byte)})
+	defer server.Close()
+
+	var buf bytes.Buffer
+	backend := newAuditedS3Backend(t, server, audit.NewLog(&buf))
+	require.NoError(t, backend.Write(t.Context(), "cloud/metadata.json", []byte("{}")))
+	_, err := backend.Read(t.Context(), "cloud/metadata.json")
+	require.NoError(t, err)
+
+	// the calls of the cache are recorded along with the calls of the scan
+	var operations []string
+	decoder := json.NewDecoder(&buf)
+	for decoder.More() {
+		var entry audit.Entry
+		require.NoError(t, decoder.Decode(&entry))
+		operations = append(operations, entry.Operation)
+	}
+	assert.Equal(t, []string{"PutObject", "GetObject"}, operations)
+}
+
+func Test_NewBackend(t *testing.T) {
+	backend, err := NewBackend(t.Context(), "", t.TempDir(), S3Options{})
+	require.NoError(t, err)
+	assert.IsType(t, &FSBackend{}, backend)
+
+	backend, err = NewBackend(t.Context(), "fs", t.TempDir(), S3Options{})
+	require.NoError(t, err)
+	assert.IsType(t, &FSBackend{}, backend)
+
+	_, err = NewBackend(t.Context(), "gs://bucket", t.TempDir(), S3Options{})
+	require.Error(t, err)
+
+	_, err = NewBackend(t.Context(), "s3://", t.TempDir(), S3Options{})
+	require.Error(t, err)
+}
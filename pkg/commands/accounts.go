@@ -0,0 +1,126 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	orgtypes "github.com/aws/aws-sdk-go-v2/service/organizations/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy-aws/pkg/flag"
+	"github.com/aquasecurity/trivy/pkg/log"
+)
+
+func getOrganizationAccounts(ctx context.Context, region, endpoint string) ([]string, error) {
+	log.DebugContext(ctx, "Listing accounts of the AWS Organization...")
+
+	cfg, err := loadAWSConfig(ctx, region, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	var accounts []string
+	paginator := organizations.NewListAccountsPaginator(organizations.NewFromConfig(cfg), &organizations.ListAccountsInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to list organization accounts: %w", err)
+		}
+		for _, account := range page.Accounts {
+			if account.Id == nil || account.Status != orgtypes.AccountStatusActive {
+				continue
+			}
+			accounts = append(accounts, *account.Id)
+		}
+	}
+
+	log.DebugContext(ctx, "Found organization accounts", log.Int("count", len(accounts)))
+	return accounts, nil
+}
+
+var accountIDPattern = regexp.MustCompile(`^\d{12}$`)
+
+// readAccountList reads the IDs of the accounts to scan, one per line. Blank lines and lines starting
+// with # are skipped.
+func readAccountList(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, xerrors.Errorf("unable to open account list: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var (
+		accounts []string
+		lineNum  int
+	)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !accountIDPattern.MatchString(line) {
+			return nil, xerrors.Errorf("invalid account ID %q on line %d of the account list, expected 12 digits", line, lineNum)
+		}
+		accounts = append(accounts, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, xerrors.Errorf("unable to read account list: %w", err)
+	}
+	return accounts, nil
+}
+
+func resolveAccounts(ctx context.Context, opt flag.Options) ([]string, error) {
+	var (
+		accounts []string
+		err      error
+	)
+	switch {
+	case opt.OrgAccounts:
+		accounts, err = getOrganizationAccounts(ctx, opt.Region, opt.Endpoint)
+	case opt.AccountList != "":
+		accounts, err = readAccountList(opt.AccountList)
+	default:
+		return []string{opt.Account}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	slices.Sort(accounts)
+	accounts = slices.Compact(accounts)
+	if len(accounts) == 0 {
+		return nil, xerrors.Errorf("no accounts found to scan")
+	}
+	return accounts, nil
+}
+
+func roleARN(partition, account, role string) string {
+	return fmt.Sprintf("arn:%s:iam::%s:role/%s", partition, account, strings.TrimPrefix(role, "/"))
+}
+
+func assumeRoleCredentials(ctx context.Context, opt flag.Options) (aws.CredentialsProvider, error) {
+	cfg, err := loadAWSConfig(ctx, opt.Region, opt.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(cfg), roleARN(opt.Partition, opt.Account, opt.AssumeRole), func(o *stscreds.AssumeRoleOptions) {
+		o.RoleSessionName = opt.RoleSessionName
+		if opt.ExternalID != "" {
+			o.ExternalID = aws.String(opt.ExternalID)
+		}
+	})
+
+	return aws.NewCredentialsCache(provider), nil
+}
@@ -0,0 +1,186 @@
+package commands
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/trivy-aws/pkg/flag"
+)
+
+func writeAccountList(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "accounts.txt")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func Test_readAccountList(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+		wantErr string
+	}{
+		{
+			name:    "one account per line",
+			content: "111111111111\n222222222222\n",
+			want:    []string{"111111111111", "222222222222"},
+		},
+		{
+			name:    "comments and blank lines",
+			content: "# production\n111111111111\n\n   \n  # staging\n  222222222222  \n",
+			want:    []string{"111111111111", "222222222222"},
+		},
+		{
+			name:    "only comments",
+			content: "# nothing to scan\n",
+		},
+		{
+			name:    "too few digits",
+			content: "111111111111\n1111111111\n",
+			wantErr: `invalid account ID "1111111111" on line 2 of the account list`,
+		},
+		{
+			name:    "not a number",
+			content: "arn:aws:iam::111111111111:root\n",
+			wantErr: `invalid account ID "arn:aws:iam::111111111111:root" on line 1 of the account list`,
+		},
+		{
+			name:    "trailing comment",
+			content: "111111111111 # production\n",
+			wantErr: "invalid account ID",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accounts, err := readAccountList(writeAccountList(t, tt.content))
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, accounts)
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := readAccountList(filepath.Join(t.TempDir(), "missing.txt"))
+		require.ErrorContains(t, err, "unable to open account list")
+	})
+}
+
+func Test_roleARN(t *testing.T) {
+	tests := []struct {
+		name      string
+		partition string
+		role      string
+		want      string
+	}{
+		{
+			name:      "commercial",
+			partition: "aws",
+			role:      "scanner",
+			want:      "arn:aws:iam::111111111111:role/scanner",
+		},
+		{
+			name:      "GovCloud",
+			partition: "aws-us-gov",
+			role:      "scanner",
+			want:      "arn:aws-us-gov:iam::111111111111:role/scanner",
+		},
+		{
+			name:      "China",
+			partition: "aws-cn",
+			role:      "scanner",
+			want:      "arn:aws-cn:iam::111111111111:role/scanner",
+		},
+		{
+			name:      "role with a path",
+			partition: "aws",
+			role:      "/security/scanner",
+			want:      "arn:aws:iam::111111111111:role/security/scanner",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, roleARN(tt.partition, "111111111111", tt.role))
+		})
+	}
+}
+
+func Test_resolveAccounts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Target") != "AWSOrganizationsV20161128.ListAccounts" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		_, _ = w.Write([]byte(`{"Accounts":[
+			{"Id":"333333333333","Status":"ACTIVE"},
+			{"Id":"222222222222","Status":"SUSPENDED"},
+			{"Id":"111111111111","Status":"ACTIVE"}
+		]}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "SECRET")
+
+	tests := []struct {
+		name    string
+		account string
+		opt     flag.CloudOptions
+		want    []string
+		wantErr string
+	}{
+		{
+			name:    "single account",
+			account: "111111111111",
+			want:    []string{"111111111111"},
+		},
+		{
+			name: "account list sorted without duplicates",
+			opt:  flag.CloudOptions{AccountList: writeAccountList(t, "222222222222\n111111111111\n222222222222\n")},
+			want: []string{"111111111111", "222222222222"},
+		},
+		{
+			name:    "empty account list",
+			opt:     flag.CloudOptions{AccountList: writeAccountList(t, "# nothing to scan\n")},
+			wantErr: "no accounts found to scan",
+		},
+		{
+			name:    "invalid account list",
+			opt:     flag.CloudOptions{AccountList: writeAccountList(t, "1111\n")},
+			wantErr: "invalid account ID",
+		},
+		{
+			name: "active organization accounts",
+			opt:  flag.CloudOptions{OrgAccounts: true},
+			want: []string{"111111111111", "333333333333"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opt := flag.Options{CloudOptions: tt.opt}
+			opt.Region, opt.Endpoint, opt.Account = "us-east-1", server.URL, tt.account
+
+			accounts, err := resolveAccounts(context.Background(), opt)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, accounts)
+		})
+	}
+}
@@ -19,6 +19,14 @@ import (
 	trivyTypes "github.com/aquasecurity/trivy/pkg/types"
 )
 
+// newGlobalFlagGroup returns the global flags with a default timeout suited to adapting a whole account,
+// which takes longer than the default timeout of trivy. Services and resources can be limited separately.
+func newGlobalFlagGroup() *trivyflag.GlobalFlagGroup {
+	globalFlags := trivyflag.NewGlobalFlagGroup()
+	globalFlags.Timeout.Default = time.Hour
+	return globalFlags
+}
+
 func NewCmd() *cobra.Command {
 
 	trivyTypes.SupportedCompliances = []trivyTypes.Compliance{
@@ -32,11 +40,14 @@ func NewCmd() *cobra.Command {
 	reportFlagGroup.ExitOnEOL = nil          // disable '--exit-on-eol'
 	reportFlagGroup.ShowSuppressed = nil     // disable '--show-suppressed'
 
-	globalFlags := trivyflag.NewGlobalFlagGroup()
+	awsFlagGroup := trivyflag.NewAWSFlagGroup()
+	awsFlagGroup.ARN = nil // replaced by the repeatable '--arn' of the cloud flag group
+
+	globalFlags := newGlobalFlagGroup()
 	awsFlags := flag.Flags{
 		BaseFlags: trivyflag.Flags{
 			globalFlags,
-			trivyflag.NewAWSFlagGroup(),
+			awsFlagGroup,
 			trivyflag.NewMisconfFlagGroup(),
 			trivyflag.NewRegoFlagGroup(),
 			reportFlagGroup,
@@ -70,50 +81,84 @@ The following services are supported:
   # limit scan to multiple services:
   $ trivy aws --region us-east-1 --service s3 --service ec2
 
+  # re-scan individual resources, updating only their entries in the cache:
+  $ trivy aws --region us-east-1 --service s3 --arn arn:aws:s3:::my-bucket
+
   # force refresh of cache for fresh results
   $ trivy aws --region us-east-1 --update-cache
-`,
-		PreRunE: func(cmd *cobra.Command, args []string) error {
-			// viper.BindPFlag cannot be called in init().
-			// cf. https://github.com/spf13/cobra/issues/875
-			//     https://github.com/spf13/viper/issues/233
-			if err := globalFlags.Bind(cmd); err != nil {
-				return xerrors.Errorf("flag bind error: %w", err)
-			}
 
-			// The config path is needed for config initialization.
-			// It needs to be obtained before ToOptions().
-			configPath := viper.GetString(trivyflag.ConfigFileFlag.ConfigName)
+  # adapt several services concurrently:
+  $ trivy aws --region us-east-1 --service-parallelism 4
 
-			// Configure environment variables and config file
-			// It cannot be called in init() because it must be called after viper.BindPFlags.
-			if err := initConfig(configPath, cmd.Flags().Changed(trivyflag.ConfigFileFlag.ConfigName)); err != nil {
-				return err
-			}
+  # adapt 4 resources of a service at a time, 2 for iam, with at most 20 calls per second to each API:
+  $ trivy aws --region us-east-1 --parallel 4 --parallel-limit iam=2 --api-rate-limit 20
 
-			flags := trivyflag.Flags{globalFlags}
-			globalOptions, err := flags.ToOptions(args)
-			if err != nil {
-				return err
-			}
+  # stop adapting a service after 10 minutes and a resource after 30 seconds, scanning what was adapted:
+  $ trivy aws --region us-east-1 --service-timeout 10m --resource-timeout 30s
 
-			// Initialize logger
-			log.InitLogger(globalOptions.Debug, globalOptions.Quiet)
+  # write the progress as JSON lines, e.g. to follow a scan in CI:
+  $ trivy aws --region us-east-1 --progress-events progress.jsonl
 
-			if err := awsFlags.Bind(cmd); err != nil {
-				return xerrors.Errorf("flag bind error: %w", err)
-			}
-			return nil
-		},
+  # show how long each service took and how many API calls it made:
+  $ trivy aws --region us-east-1 --update-cache --show-stats
+
+  # trace the adapters and AWS API calls, exporting to a local OpenTelemetry collector or to a file:
+  $ trivy aws --region us-east-1 --update-cache --trace-endpoint http://localhost:4318
+  $ trivy aws --region us-east-1 --update-cache --trace-file traces.jsonl
+
+  # record every AWS API call made by the scan in an audit log:
+  $ trivy aws --region us-east-1 --update-cache --audit-log aws-calls.jsonl
+
+  # encrypt the cache at rest, rejecting cache files which were tampered with:
+  $ openssl rand -base64 32 > cache.key
+  $ trivy aws --region us-east-1 --cache-key-file cache.key
+
+  # share one cache between CI runners through an S3 bucket:
+  $ trivy aws --region us-east-1 --cache-backend s3://my-bucket/trivy-cache
+
+  # scan multiple regions:
+  $ trivy aws --regions us-east-1,eu-west-1
+
+  # scan every region enabled for the account:
+  $ trivy aws --regions all-enabled
+
+  # scan every account of the organization through an assumed role:
+  $ trivy aws --region us-east-1 --org-accounts --assume-role SecurityAudit --external-id my-external-id
+
+  # only scan the resources of a team, skipping sandbox resources:
+  $ trivy aws --region us-east-1 --include-tag team=payments --exclude-tag env=sandbox
+
+  # show what changed since a previous JSON report, failing only on new failures:
+  $ trivy aws --region us-east-1 --compare-to yesterday.json --exit-code 1
+
+  # accept the current failures, then only fail on new misconfigurations:
+  $ trivy aws --region us-east-1 --write-baseline baseline.yaml
+  $ trivy aws --region us-east-1 --baseline baseline.yaml --exit-code 1
+
+  # fail when resources could not be fully checked, e.g. because of missing permissions:
+  $ trivy aws --region us-east-1 --exit-on-gaps 2
+
+  # evaluate the checks against a previously exported state without access to AWS:
+  $ trivy aws --state-file snapshot.json
+
+  # dump the input of the rego checks to help with writing custom checks:
+  $ trivy aws dump-state --region us-east-1 --service s3 --dump-format rego --redact --output input.json
+
+  # print the least-privilege IAM policy required to scan s3 and iam:
+  $ trivy aws iam-policy --service s3 --service iam
+
+  # add the permissions of features which call AWS on top of the scanned services:
+  $ trivy aws iam-policy --feature all-enabled-regions --feature preflight
+
+  # simulate whether the caller is allowed every API call required to scan s3:
+  $ trivy aws preflight --region us-east-1 --service s3
+`,
+		PreRunE: preRun(globalFlags, awsFlags),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			opts, err := awsFlags.ToOptions(args)
 			if err != nil {
 				return xerrors.Errorf("flag error: %w", err)
 			}
-			if opts.Timeout < time.Hour {
-				opts.Timeout = time.Hour
-				log.Debug("Timeout is set to less than 1 hour - upgrading to 1 hour for this command.")
-			}
 			return Run(cmd.Context(), opts)
 		},
 		SilenceErrors: true,
@@ -123,9 +168,48 @@ The following services are supported:
 	globalFlags.AddFlags(cmd)
 	awsFlags.AddFlags(cmd)
 
+	cmd.AddCommand(newDumpStateCmd())
+	cmd.AddCommand(newIAMPolicyCmd())
+	cmd.AddCommand(newPreflightCmd())
+
 	return cmd
 }
 
+func preRun(globalFlags *trivyflag.GlobalFlagGroup, awsFlags flag.Flags) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		// viper.BindPFlag cannot be called in init().
+		// cf. https://github.com/spf13/cobra/issues/875
+		//     https://github.com/spf13/viper/issues/233
+		if err := globalFlags.Bind(cmd); err != nil {
+			return xerrors.Errorf("flag bind error: %w", err)
+		}
+
+		// The config path is needed for config initialization.
+		// It needs to be obtained before ToOptions().
+		configPath := viper.GetString(trivyflag.ConfigFileFlag.ConfigName)
+
+		// Configure environment variables and config file
+		// It cannot be called in init() because it must be called after viper.BindPFlags.
+		if err := initConfig(configPath, cmd.Flags().Changed(trivyflag.ConfigFileFlag.ConfigName)); err != nil {
+			return err
+		}
+
+		flags := trivyflag.Flags{globalFlags}
+		globalOptions, err := flags.ToOptions(args)
+		if err != nil {
+			return err
+		}
+
+		// Initialize logger
+		log.InitLogger(globalOptions.Debug, globalOptions.Quiet)
+
+		if err := awsFlags.Bind(cmd); err != nil {
+			return xerrors.Errorf("flag bind error: %w", err)
+		}
+		return nil
+	}
+}
+
 func initConfig(configFile string, pathChanged bool) error {
 	// Read from config
 	viper.SetConfigFile(configFile)
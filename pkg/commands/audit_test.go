@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/trivy-aws/pkg/audit"
+	"github.com/aquasecurity/trivy-aws/pkg/flag"
+)
+
+// Test_AuditLog checks that the calls made by the command itself, rather than by the adapters, are recorded
+// in the audit log of the context
+func Test_AuditLog(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		switch {
+		case r.Form.Get("Action") == "GetCallerIdentity":
+			w.Header().Set("Content-Type", "text/xml")
+			_, _ = w.Write([]byte(`<GetCallerIdentityResponse><GetCallerIdentityResult><Arn>arn:aws:iam::123456789012:user/scanner</Arn><Account>123456789012</Account></GetCallerIdentityResult></GetCallerIdentityResponse>`))
+		case r.Form.Get("Action") == "DescribeRegions":
+			w.Header().Set("Content-Type", "text/xml")
+			_, _ = w.Write([]byte(`<DescribeRegionsResponse><regionInfo><item><regionName>us-east-1</regionName></item></regionInfo></DescribeRegionsResponse>`))
+		case r.Form.Get("Action") == "AssumeRole":
+			w.Header().Set("Content-Type", "text/xml")
+			_, _ = w.Write([]byte(`<AssumeRoleResponse><AssumeRoleResult><Credentials><AccessKeyId>AKID</AccessKeyId><SecretAccessKey>SECRET</SecretAccessKey><SessionToken>TOKEN</SessionToken><Expiration>2100-01-01T00:00:00Z</Expiration></Credentials></AssumeRoleResult></AssumeRoleResponse>`))
+		case r.Header.Get("X-Amz-Target") == "AWSOrganizationsV20161128.ListAccounts":
+			w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+			_, _ = w.Write([]byte(`{"Accounts":[{"Id":"111111111111","Status":"ACTIVE"}]}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "SECRET")
+
+	var buf bytes.Buffer
+	ctx := audit.NewContext(context.Background(), audit.NewLog(&buf))
+
+	_, _, _, err := getCallerIdentity(ctx, "us-east-1", server.URL)
+	require.NoError(t, err)
+	_, err = getEnabledRegions(ctx, "us-east-1", server.URL)
+	require.NoError(t, err)
+	_, err = getOrganizationAccounts(ctx, "us-east-1", server.URL)
+	require.NoError(t, err)
+
+	opt := flag.Options{
+		CloudOptions: flag.CloudOptions{
+			AssumeRole:      "scanner",
+			RoleSessionName: "trivy",
+			Partition:       "aws",
+		},
+	}
+	opt.Region, opt.Endpoint, opt.Account = "us-east-1", server.URL, "111111111111"
+	credentials, err := assumeRoleCredentials(ctx, opt)
+	require.NoError(t, err)
+	_, err = credentials.Retrieve(ctx)
+	require.NoError(t, err)
+
+	var operations []string
+	decoder := json.NewDecoder(&buf)
+	for decoder.More() {
+		var entry audit.Entry
+		require.NoError(t, decoder.Decode(&entry))
+		operations = append(operations, entry.Service+"."+entry.Operation)
+	}
+	assert.Equal(t, []string{"STS.GetCallerIdentity", "EC2.DescribeRegions", "Organizations.ListAccounts", "STS.AssumeRole"}, operations)
+}
@@ -0,0 +1,133 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy-aws/pkg/flag"
+	awsScanner "github.com/aquasecurity/trivy-aws/pkg/scanner"
+	"github.com/aquasecurity/trivy-aws/pkg/statefile"
+	trivyflag "github.com/aquasecurity/trivy/pkg/flag"
+	"github.com/aquasecurity/trivy/pkg/log"
+)
+
+func newDumpStateCmd() *cobra.Command {
+	awsFlagGroup := trivyflag.NewAWSFlagGroup()
+	awsFlagGroup.ARN = nil // replaced by the repeatable '--arn' of the cloud flag group
+
+	// only the options which relate to a single account and region apply to the dumped state
+	cloudFlagGroup := flag.NewCloudFlagGroup()
+	cloudFlagGroup.Regions = nil
+	cloudFlagGroup.IncludeTags = nil
+	cloudFlagGroup.ExcludeTags = nil
+	cloudFlagGroup.StateFile = nil
+	cloudFlagGroup.CompareTo = nil
+	cloudFlagGroup.Baseline = nil
+	cloudFlagGroup.WriteBaseline = nil
+	cloudFlagGroup.ExitOnGaps = nil
+	cloudFlagGroup.ShowStats = nil
+	cloudFlagGroup.OrgAccounts = nil
+	cloudFlagGroup.AccountList = nil
+	cloudFlagGroup.AssumeRole = nil
+	cloudFlagGroup.ExternalID = nil
+	cloudFlagGroup.RoleSessionName = nil
+
+	globalFlags := newGlobalFlagGroup()
+	dumpFlags := flag.Flags{
+		BaseFlags: trivyflag.Flags{
+			globalFlags,
+			awsFlagGroup,
+			&trivyflag.DBFlagGroup{
+				NoProgress: trivyflag.NoProgressFlag.Clone(),
+			},
+		},
+		CloudFlagGroup: cloudFlagGroup,
+		DumpFlagGroup:  flag.NewDumpFlagGroup(),
+	}
+
+	cmd := &cobra.Command{
+		Use:   "dump-state [flags]",
+		Args:  cobra.ExactArgs(0),
+		Short: "Dump the adapted state of an AWS account",
+		Long: `Dump the state which the checks are evaluated against, to help with writing custom checks.
+The state is adapted and cached in the same way as for a scan, and can be scanned later with '--state-file'.`,
+		Example: `  # dump the state of a single service
+  $ trivy aws dump-state --region us-east-1 --service s3 --output state.json
+
+  # dump the input of the rego checks, redacting sensitive values
+  $ trivy aws dump-state --region us-east-1 --dump-format rego --redact --output input.json`,
+		PreRunE: preRun(globalFlags, dumpFlags),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := dumpFlags.ToOptions(args)
+			if err != nil {
+				return xerrors.Errorf("flag error: %w", err)
+			}
+			return DumpState(cmd.Context(), opts)
+		},
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+
+	globalFlags.AddFlags(cmd)
+	dumpFlags.AddFlags(cmd)
+
+	return cmd
+}
+
+// DumpState writes the adapted state of the account and region to the output
+func DumpState(ctx context.Context, opt flag.Options) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, opt.GlobalOptions.Timeout)
+	defer cancel()
+
+	ctx = log.WithContextPrefix(ctx, "aws")
+
+	ctx, endTracing, err := startTracing(ctx, opt, "dump-state")
+	if err != nil {
+		return err
+	}
+	defer func() { endTracing(err) }()
+
+	ctx, closeAuditLog, err := startAuditLog(ctx, opt)
+	if err != nil {
+		return err
+	}
+	defer closeAuditLog()
+
+	if err := processOptions(ctx, &opt); err != nil {
+		return err
+	}
+
+	f, _, err := awsScanner.NewScanner().LoadState(ctx, opt)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			log.Warn("Provide a higher timeout value, see https://aquasecurity.github.io/trivy/latest/docs/configuration/")
+		}
+		return xerrors.Errorf("unable to load state: %w", err)
+	}
+
+	if opt.Redact {
+		statefile.Redact(f.State)
+	}
+
+	var output io.Writer = os.Stdout
+	if opt.DumpOutput != "" {
+		file, err := os.Create(opt.DumpOutput)
+		if err != nil {
+			return xerrors.Errorf("failed to create output file: %w", err)
+		}
+		defer func() { _ = file.Close() }()
+		output = file
+	}
+
+	log.DebugContext(ctx, "Writing state to output...", log.String("format", opt.DumpFormat))
+
+	if opt.DumpFormat == flag.DumpFormatRego {
+		return statefile.WriteRego(output, f)
+	}
+	return statefile.Write(output, f)
+}
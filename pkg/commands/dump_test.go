@@ -0,0 +1,72 @@
+package commands_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/trivy-aws/pkg/statefile"
+)
+
+func Test_DumpState(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		check  func(t *testing.T, path string)
+	}{
+		{
+			name:   "raw state",
+			format: "state",
+			check: func(t *testing.T, path string) {
+				f, err := statefile.Load(path)
+				require.NoError(t, err)
+				assert.Equal(t, account, f.AccountID)
+				assert.Equal(t, region, f.Region)
+				require.NotNil(t, f.State)
+				assert.NotEmpty(t, f.State.AWS.S3.Buckets)
+			},
+		},
+		{
+			name:   "rego input",
+			format: "rego",
+			check: func(t *testing.T, path string) {
+				data, err := os.ReadFile(path)
+				require.NoError(t, err)
+
+				var input map[string]any
+				require.NoError(t, json.Unmarshal(data, &input))
+				assert.Contains(t, input, "aws")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacheDir := t.TempDir()
+			copyCache(t, cacheDir, "s3onlycache")
+
+			outputFile := filepath.Join(t.TempDir(), "output")
+
+			err := run([]string{
+				"dump-state",
+				"--region", region,
+				"--account", account,
+				"--service", "s3",
+				"--quiet",
+				"--timeout", time.Minute.String(),
+				"--cache-dir", cacheDir,
+				"--max-cache-age", "876000h",
+				"--dump-format", tt.format,
+				"--output", outputFile,
+			})
+			require.NoError(t, err)
+
+			tt.check(t, outputFile)
+		})
+	}
+}
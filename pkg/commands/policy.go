@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy-aws/pkg/flag"
+	awsScanner "github.com/aquasecurity/trivy-aws/pkg/scanner"
+	trivyflag "github.com/aquasecurity/trivy/pkg/flag"
+	"github.com/aquasecurity/trivy/pkg/log"
+)
+
+const policyVersion = "2012-10-17"
+
+type policyDocument struct {
+	Version   string            `json:"Version"`
+	Statement []policyStatement `json:"Statement"`
+}
+
+type policyStatement struct {
+	Effect   string   `json:"Effect"`
+	Action   []string `json:"Action"`
+	Resource string   `json:"Resource"`
+}
+
+func newIAMPolicyCmd() *cobra.Command {
+	// only the services are relevant, the policy does not depend on the account or region
+	awsFlagGroup := trivyflag.NewAWSFlagGroup()
+	awsFlagGroup.Region = nil
+	awsFlagGroup.Endpoint = nil
+	awsFlagGroup.Account = nil
+	awsFlagGroup.ARN = nil
+
+	globalFlags := trivyflag.NewGlobalFlagGroup()
+	policyFlags := flag.Flags{
+		BaseFlags: trivyflag.Flags{
+			globalFlags,
+			awsFlagGroup,
+		},
+		CloudFlagGroup: flag.NewIAMPolicyFlagGroup(),
+	}
+
+	cmd := &cobra.Command{
+		Use:   "iam-policy [flags]",
+		Args:  cobra.ExactArgs(0),
+		Short: "Print the least-privilege IAM policy required to scan AWS",
+		Long: `Print an IAM policy document which allows every API call made when scanning the selected services.
+The policy can be attached to the role or user the scan runs as.
+Features which call AWS on top of the scanned services, such as --regions all-enabled or the preflight command,
+need their permissions to be added with --feature.`,
+		Example: `  # print the policy required to scan every supported service
+  $ trivy aws iam-policy
+
+  # print the policy required to scan specific services
+  $ trivy aws iam-policy --service s3 --service iam > policy.json
+
+  # print the policy required to scan every enabled region and to run the preflight command
+  $ trivy aws iam-policy --feature all-enabled-regions --feature preflight`,
+		PreRunE: preRun(globalFlags, policyFlags),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := policyFlags.ToOptions(args)
+			if err != nil {
+				return xerrors.Errorf("flag error: %w", err)
+			}
+			return IAMPolicy(cmd.Context(), opts, os.Stdout)
+		},
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+
+	globalFlags.AddFlags(cmd)
+	policyFlags.AddFlags(cmd)
+
+	return cmd
+}
+
+// IAMPolicy writes the IAM policy document covering the API calls made when scanning the selected services
+func IAMPolicy(ctx context.Context, opt flag.Options, w io.Writer) error {
+	ctx = log.WithContextPrefix(ctx, "aws")
+
+	if err := processServiceOptions(ctx, &opt); err != nil {
+		return err
+	}
+
+	featureActions, err := awsScanner.FeaturePermissions(splitValues(opt.Features))
+	if err != nil {
+		return xerrors.Errorf("invalid --feature: %w", err)
+	}
+	actions := append(awsScanner.RequiredPermissions(opt.Services), featureActions...)
+	slices.Sort(actions)
+
+	policy := policyDocument{
+		Version: policyVersion,
+		Statement: []policyStatement{
+			{
+				Effect:   "Allow",
+				Action:   slices.Compact(actions),
+				Resource: "*",
+			},
+		},
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(policy); err != nil {
+		return xerrors.Errorf("failed to write policy: %w", err)
+	}
+	return nil
+}
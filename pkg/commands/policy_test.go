@@ -0,0 +1,111 @@
+package commands_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/trivy-aws/pkg/commands"
+	"github.com/aquasecurity/trivy-aws/pkg/flag"
+	trivyflag "github.com/aquasecurity/trivy/pkg/flag"
+)
+
+func Test_IAMPolicy(t *testing.T) {
+	tests := []struct {
+		name         string
+		services     []string
+		skipServices []string
+		features     []string
+		contains     []string
+		excludes     []string
+		wantErr      string
+	}{
+		{
+			name:     "single service",
+			services: []string{"s3"},
+			contains: []string{"sts:GetCallerIdentity", "tag:GetResources", "s3:GetBucketAcl", "s3:ListAllMyBuckets"},
+			excludes: []string{"iam:ListUsers"},
+		},
+		{
+			name:     "comma separated services",
+			services: []string{"s3,iam"},
+			contains: []string{"s3:GetBucketAcl", "iam:ListMFADevices"},
+			excludes: []string{"ec2:DescribeInstances"},
+		},
+		{
+			name:         "skipped services",
+			skipServices: []string{"s3"},
+			contains:     []string{"iam:ListMFADevices", "ec2:DescribeInstances"},
+			excludes:     []string{"s3:GetBucketAcl"},
+		},
+		{
+			name:     "features",
+			services: []string{"s3"},
+			features: []string{"all-enabled-regions,preflight"},
+			contains: []string{"s3:GetBucketAcl", "ec2:DescribeRegions", "iam:GetRole", "iam:SimulatePrincipalPolicy"},
+			excludes: []string{"organizations:ListAccounts", "sts:AssumeRole"},
+		},
+		{
+			name:     "no features",
+			services: []string{"s3"},
+			excludes: []string{"ec2:DescribeRegions", "iam:SimulatePrincipalPolicy"},
+		},
+		{
+			name:     "unknown feature",
+			features: []string{"nope"},
+			wantErr:  `invalid --feature: unknown feature "nope"`,
+		},
+		{
+			name:     "unsupported service",
+			services: []string{"nope"},
+			wantErr:  "service 'nope' is not currently supported",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opt := flag.Options{
+				Options: trivyflag.Options{
+					AWSOptions: trivyflag.AWSOptions{
+						Services:     test.services,
+						SkipServices: test.skipServices,
+					},
+				},
+				CloudOptions: flag.CloudOptions{
+					Features: test.features,
+				},
+			}
+
+			var buf bytes.Buffer
+			err := commands.IAMPolicy(context.Background(), opt, &buf)
+			if test.wantErr != "" {
+				require.ErrorContains(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			var policy struct {
+				Version   string
+				Statement []struct {
+					Effect   string
+					Action   []string
+					Resource string
+				}
+			}
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &policy))
+			assert.Equal(t, "2012-10-17", policy.Version)
+			require.Len(t, policy.Statement, 1)
+			assert.Equal(t, "Allow", policy.Statement[0].Effect)
+			assert.Equal(t, "*", policy.Statement[0].Resource)
+			assert.IsNonDecreasing(t, policy.Statement[0].Action)
+			assert.Subset(t, policy.Statement[0].Action, test.contains)
+			for _, action := range test.excludes {
+				assert.NotContains(t, policy.Statement[0].Action, action)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/table"
+	"github.com/aquasecurity/tml"
+	"github.com/aquasecurity/trivy-aws/internal/adapters/cloud/aws"
+	"github.com/aquasecurity/trivy-aws/pkg/audit"
+	"github.com/aquasecurity/trivy-aws/pkg/flag"
+	awsScanner "github.com/aquasecurity/trivy-aws/pkg/scanner"
+	"github.com/aquasecurity/trivy/pkg/commands/operation"
+	trivyflag "github.com/aquasecurity/trivy/pkg/flag"
+	"github.com/aquasecurity/trivy/pkg/iac/scanners/options"
+	"github.com/aquasecurity/trivy/pkg/log"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+func newPreflightCmd() *cobra.Command {
+	awsFlagGroup := trivyflag.NewAWSFlagGroup()
+	awsFlagGroup.Account = nil
+	awsFlagGroup.ARN = nil
+
+	format := trivyflag.FormatFlag.Clone()
+	format.Values = []string{string(types.FormatTable), string(types.FormatJSON)}
+
+	globalFlags := trivyflag.NewGlobalFlagGroup()
+	preflightFlags := flag.Flags{
+		BaseFlags: trivyflag.Flags{
+			globalFlags,
+			awsFlagGroup,
+			&trivyflag.ReportFlagGroup{
+				Format:   format,
+				Output:   trivyflag.OutputFlag.Clone(),
+				ExitCode: trivyflag.ExitCodeFlag.Clone(),
+			},
+		},
+		CloudFlagGroup: flag.NewPreflightFlagGroup(),
+	}
+
+	cmd := &cobra.Command{
+		Use:   "preflight [flags]",
+		Args:  cobra.ExactArgs(0),
+		Short: "Check the permissions required to scan AWS",
+		Long: `Check whether the caller is allowed every API call made when scanning the selected services, reporting each call as allowed or denied.
+The policies of the caller are evaluated with the IAM policy simulator, which requires iam:SimulatePrincipalPolicy, and iam:GetRole when running as an assumed role, as added by 'trivy aws iam-policy --feature preflight'.
+The decisions are simulated against every resource and no API call of the scan is made. The simulator evaluates the identity-based policies of the caller, its permissions boundary and the service control policies, but not resource-based policies such as S3 bucket policies or KMS key policies, nor conditions on the context of a request such as resource tags or the source IP. An allowed action may therefore still be denied for some resources, which the scan reports as gaps.`,
+		Example: `  # check the permissions required to scan every supported service
+  $ trivy aws preflight --region us-east-1
+
+  # fail if any of the permissions required to scan s3 are missing
+  $ trivy aws preflight --region us-east-1 --service s3 --exit-code 1`,
+		PreRunE: preRun(globalFlags, preflightFlags),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := preflightFlags.ToOptions(args)
+			if err != nil {
+				return xerrors.Errorf("flag error: %w", err)
+			}
+			return Preflight(cmd.Context(), opts)
+		},
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+
+	globalFlags.AddFlags(cmd)
+	preflightFlags.AddFlags(cmd)
+
+	return cmd
+}
+
+// Preflight checks the permissions required to scan the selected services and writes whether each was granted
+func Preflight(ctx context.Context, opt flag.Options) error {
+	ctx, cancel := context.WithTimeout(ctx, opt.GlobalOptions.Timeout)
+	defer cancel()
+
+	ctx = log.WithContextPrefix(ctx, "aws")
+
+	ctx, closeAuditLog, err := startAuditLog(ctx, opt)
+	if err != nil {
+		return err
+	}
+	defer closeAuditLog()
+
+	if err := processServiceOptions(ctx, &opt); err != nil {
+		return err
+	}
+
+	scannerOpts := []options.ScannerOption{
+		awsScanner.ScannerWithAWSServices(opt.Services...),
+	}
+	if opt.Region != "" {
+		scannerOpts = append(scannerOpts, awsScanner.ScannerWithAWSRegion(opt.Region))
+	}
+	if opt.Endpoint != "" {
+		scannerOpts = append(scannerOpts, awsScanner.ScannerWithAWSEndpoint(opt.Endpoint))
+	}
+	if auditLog := audit.FromContext(ctx); auditLog != nil {
+		scannerOpts = append(scannerOpts, awsScanner.ScannerWithAuditLog(auditLog))
+	}
+
+	checks, err := awsScanner.New(scannerOpts...).CheckPermissions(ctx)
+	if err != nil {
+		return xerrors.Errorf("unable to check permissions: %w", err)
+	}
+
+	output, cleanup, err := opt.OutputWriter(ctx)
+	if err != nil {
+		return xerrors.Errorf("failed to create output file: %w", err)
+	}
+	defer func() { _ = cleanup() }()
+
+	if opt.Format == types.FormatJSON {
+		encoder := json.NewEncoder(output)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(checks); err != nil {
+			return xerrors.Errorf("failed to write permission checks: %w", err)
+		}
+	} else {
+		writePermissionChecks(output, checks)
+	}
+
+	var denied bool
+	for _, check := range checks {
+		denied = denied || !check.Allowed
+	}
+	return operation.Exit(opt.Options, denied, types.Metadata{})
+}
+
+// simulationNote explains what the decisions of the IAM policy simulator don't take into account
+const simulationNote = "The decisions are simulated by the IAM policy simulator, which doesn't evaluate resource-based policies, such as S3 bucket policies or KMS key policies, nor conditions on the context of a request. Actions may still be denied for some resources."
+
+func writePermissionChecks(output io.Writer, checks []aws.PermissionCheck) {
+	// ensure color/formatting is disabled for pipes/non-pty
+	var useANSI bool
+	if output == os.Stdout {
+		if o, err := os.Stdout.Stat(); err == nil {
+			useANSI = (o.Mode() & os.ModeCharDevice) == os.ModeCharDevice
+		}
+	}
+	if !useANSI {
+		tml.DisableFormatting()
+	}
+
+	t := table.New(output)
+	t.SetHeaders("Service", "Action", "Simulated Decision")
+	t.SetRowLines(false)
+
+	var denied int
+	for _, check := range checks {
+		decision := tml.Sprintf("<green>%s</green>", check.Decision)
+		if !check.Allowed {
+			decision = tml.Sprintf("<red>%s</red>", check.Decision)
+			denied++
+		}
+		t.AddRow(check.Service, check.Action, decision)
+	}
+
+	_ = tml.Fprintf(output, "\n<bold>Permissions required to scan AWS (simulated)</bold>\n")
+	t.Render()
+	_ = tml.Fprintf(output, "\n<yellow>%s</yellow>\n", simulationNote)
+
+	if denied > 0 {
+		_ = tml.Fprintf(output, "\n<red>%d of %d actions are denied, the affected services will not be fully checked. Use 'trivy aws iam-policy' to print the required policy.</red>\n", denied, len(checks))
+	} else {
+		_ = tml.Fprintf(output, "\n<green>All %d actions are allowed.</green>\n", len(checks))
+	}
+}
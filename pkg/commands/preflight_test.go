@@ -0,0 +1,32 @@
+package commands
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aquasecurity/trivy-aws/internal/adapters/cloud/aws"
+)
+
+func Test_writePermissionChecks(t *testing.T) {
+	var buf bytes.Buffer
+	writePermissionChecks(&buf, []aws.PermissionCheck{
+		{Service: "s3", Action: "s3:GetBucketPolicy", Allowed: true, Decision: "allowed", Simulated: true},
+		{Service: "s3", Action: "s3:ListAllMyBuckets", Decision: "implicitDeny", Simulated: true},
+	})
+
+	assert.Equal(t, `
+Permissions required to scan AWS (simulated)
+┌─────────┬─────────────────────┬────────────────────┐
+│ Service │       Action        │ Simulated Decision │
+├─────────┼─────────────────────┼────────────────────┤
+│ s3      │ s3:GetBucketPolicy  │ allowed            │
+│ s3      │ s3:ListAllMyBuckets │ implicitDeny       │
+└─────────┴─────────────────────┴────────────────────┘
+
+`+simulationNote+`
+
+1 of 2 actions are denied, the affected services will not be fully checked. Use 'trivy aws iam-policy' to print the required policy.
+`, buf.String())
+}
@@ -3,35 +3,50 @@ package commands
 import (
 	"context"
 	"errors"
+	"os"
 	"slices"
 	"sort"
 	"strings"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"golang.org/x/xerrors"
 
+	"github.com/aquasecurity/trivy-aws/pkg/audit"
+	"github.com/aquasecurity/trivy-aws/pkg/baseline"
 	"github.com/aquasecurity/trivy-aws/pkg/errs"
 	"github.com/aquasecurity/trivy-aws/pkg/flag"
+	"github.com/aquasecurity/trivy-aws/pkg/partition"
 	"github.com/aquasecurity/trivy-aws/pkg/report"
 	awsScanner "github.com/aquasecurity/trivy-aws/pkg/scanner"
+	"github.com/aquasecurity/trivy-aws/pkg/statefile"
+	"github.com/aquasecurity/trivy-aws/pkg/tracing"
+	"github.com/aquasecurity/trivy/pkg/clock"
 	"github.com/aquasecurity/trivy/pkg/cloud/aws/config"
 	"github.com/aquasecurity/trivy/pkg/commands/operation"
+	"github.com/aquasecurity/trivy/pkg/iac/scan"
 	"github.com/aquasecurity/trivy/pkg/log"
 	"github.com/aquasecurity/trivy/pkg/types"
 )
 
 const (
 	ProviderAWS = "AWS"
+
+	// allEnabledRegions can be passed to --regions to scan every region enabled for the account
+	allEnabledRegions = "all-enabled"
 )
 
 var AllSupportedServicesFunc = awsScanner.AllSupportedServices
 
-func getAccountIDAndRegion(ctx context.Context, region, endpoint string) (string, string, error) {
+// getCallerIdentity returns the account ID, region and partition of the default credentials
+func getCallerIdentity(ctx context.Context, region, endpoint string) (string, string, string, error) {
 	log.DebugContext(ctx, "Looking for AWS credentials provider...")
 
-	cfg, err := config.LoadDefaultAWSConfig(ctx, region, endpoint)
+	cfg, err := loadAWSConfig(ctx, region, endpoint)
 	if err != nil {
-		return "", "", err
+		return "", "", "", err
 	}
 
 	svc := sts.NewFromConfig(cfg)
@@ -39,13 +54,39 @@ func getAccountIDAndRegion(ctx context.Context, region, endpoint string) (string
 	log.DebugContext(ctx, "Looking up AWS caller identity...")
 	result, err := svc.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
 	if err != nil {
-		return "", "", xerrors.Errorf("failed to discover AWS caller identity: %w", err)
+		return "", "", "", xerrors.Errorf("failed to discover AWS caller identity: %w", err)
 	}
 	if result.Account == nil {
-		return "", "", xerrors.Errorf("missing account id for aws account")
+		return "", "", "", xerrors.Errorf("missing account id for aws account")
 	}
 	log.DebugContext(ctx, "Verified AWS credentials for account!", log.String("account", *result.Account))
-	return *result.Account, cfg.Region, nil
+	return *result.Account, cfg.Region, partition.FromARN(aws.ToString(result.Arn), cfg.Region), nil
+}
+
+func getEnabledRegions(ctx context.Context, region, endpoint string) ([]string, error) {
+	log.DebugContext(ctx, "Discovering enabled AWS regions...")
+
+	cfg, err := loadAWSConfig(ctx, region, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	svc := ec2.NewFromConfig(cfg)
+
+	result, err := svc.DescribeRegions(ctx, &ec2.DescribeRegionsInput{})
+	if err != nil {
+		return nil, xerrors.Errorf("failed to discover enabled AWS regions: %w", err)
+	}
+
+	var regions []string
+	for _, r := range result.Regions {
+		if r.RegionName != nil {
+			regions = append(regions, *r.RegionName)
+		}
+	}
+	sort.Strings(regions)
+	log.DebugContext(ctx, "Discovered enabled AWS regions", log.Any("regions", regions))
+	return regions, nil
 }
 
 func validateServicesInput(services, skipServices []string) error {
@@ -59,32 +100,104 @@ func validateServicesInput(services, skipServices []string) error {
 	return nil
 }
 
+// splitValues splits comma separated values of a repeatable flag
+func splitValues(values []string) []string {
+	var split []string
+	for _, value := range values {
+		split = append(split, strings.Split(value, ",")...)
+	}
+	return split
+}
+
+// processServiceOptions resolves the services to scan for the commands which only depend on the services
+func processServiceOptions(ctx context.Context, opt *flag.Options) error {
+	if err := validateServicesInput(opt.Services, opt.SkipServices); err != nil {
+		return err
+	}
+	opt.Services = splitValues(opt.Services)
+	opt.SkipServices = splitValues(opt.SkipServices)
+	return filterServices(ctx, opt)
+}
+
 func processOptions(ctx context.Context, opt *flag.Options) error {
 	if err := validateServicesInput(opt.Services, opt.SkipServices); err != nil {
 		return err
 	}
 
 	// support comma separated services too
-	var splitServices []string
-	for _, service := range opt.Services {
-		splitServices = append(splitServices, strings.Split(service, ",")...)
-	}
-	opt.Services = splitServices
+	opt.Services = splitValues(opt.Services)
+	opt.SkipServices = splitValues(opt.SkipServices)
+	opt.Regions = splitValues(opt.Regions)
 
-	var splitSkipServices []string
-	for _, skipService := range opt.SkipServices {
-		splitSkipServices = append(splitSkipServices, strings.Split(skipService, ",")...)
+	if _, err := awsScanner.ParseTagFilters(opt.IncludeTags); err != nil {
+		return err
+	}
+	if _, err := awsScanner.ParseTagFilters(opt.ExcludeTags); err != nil {
+		return err
+	}
+	if _, err := awsScanner.ParseParallelLimits(opt.ParallelLimits); err != nil {
+		return err
 	}
-	opt.SkipServices = splitSkipServices
 
-	if len(opt.Services) != 1 && opt.ARN != "" {
+	if len(opt.Services) != 1 && len(opt.ARNs) > 0 {
 		return xerrors.Errorf("you must specify the single --service which the --arn relates to")
 	}
+	if len(opt.ARNs) > 0 && !slices.Contains(awsScanner.ResourceServices(), opt.Services[0]) {
+		return xerrors.Errorf("service '%s' does not support scanning individual resources with --arn, supported services: %s",
+			opt.Services[0], strings.Join(awsScanner.ResourceServices(), ", "))
+	}
 
-	if opt.Account == "" || opt.Region == "" {
-		var err error
-		opt.Account, opt.Region, err = getAccountIDAndRegion(ctx, opt.Region, opt.Endpoint)
-		if err != nil {
+	if opt.MultiAccount() {
+		switch {
+		case opt.OrgAccounts && opt.AccountList != "":
+			return xerrors.Errorf("--org-accounts and --account-list cannot be used together")
+		case opt.AssumeRole == "":
+			return xerrors.Errorf("--assume-role is required to scan multiple accounts")
+		case opt.Account != "":
+			return xerrors.Errorf("--account cannot be used when scanning multiple accounts")
+		case len(opt.ARNs) > 0:
+			return xerrors.Errorf("--arn cannot be used when scanning multiple accounts")
+		}
+	}
+
+	if opt.StateFile != "" {
+		switch {
+		case opt.MultiAccount():
+			return xerrors.Errorf("--state-file cannot be used when scanning multiple accounts")
+		case len(opt.Regions) > 0:
+			return xerrors.Errorf("--state-file cannot be used with --regions as the state relates to a single region")
+		case opt.UpdateCache:
+			return xerrors.Errorf("--update-cache cannot be used with --state-file")
+		}
+	}
+
+	if opt.CompareTo != "" && opt.Compliance.Spec.ID != "" {
+		return xerrors.Errorf("--compare-to cannot be used with --compliance")
+	}
+
+	if len(opt.Regions) > 0 && opt.Region != "" {
+		return xerrors.Errorf("--region and --regions cannot be used together")
+	}
+
+	if len(opt.Regions) > 0 && len(opt.ARNs) > 0 {
+		return xerrors.Errorf("--arn cannot be used with --regions as the ARN relates to a single region")
+	}
+
+	// an offline scan takes the account and region from the state file rather than from AWS
+	if opt.StateFile == "" {
+		if opt.Account == "" || (opt.Region == "" && len(opt.Regions) == 0) {
+			lookupRegion := opt.Region
+			if len(opt.Regions) > 0 && opt.Regions[0] != allEnabledRegions {
+				lookupRegion = opt.Regions[0]
+			}
+			var err error
+			opt.Account, opt.Region, opt.Partition, err = getCallerIdentity(ctx, lookupRegion, opt.Endpoint)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := processRegions(ctx, opt); err != nil {
 			return err
 		}
 	}
@@ -98,6 +211,36 @@ func processOptions(ctx context.Context, opt *flag.Options) error {
 	return nil
 }
 
+func processRegions(ctx context.Context, opt *flag.Options) error {
+	switch {
+	case len(opt.Regions) == 0:
+		opt.Regions = []string{opt.Region}
+	case slices.Contains(opt.Regions, allEnabledRegions):
+		if len(opt.Regions) > 1 {
+			return xerrors.Errorf("'%s' cannot be combined with other regions", allEnabledRegions)
+		}
+		regions, err := getEnabledRegions(ctx, opt.Region, opt.Endpoint)
+		if err != nil {
+			return err
+		}
+		if len(regions) == 0 {
+			return xerrors.Errorf("no enabled regions found for account %s", opt.Account)
+		}
+		opt.Regions = regions
+	default:
+		slices.Sort(opt.Regions)
+		opt.Regions = slices.Compact(opt.Regions)
+	}
+
+	// the first region is used for global services and single region reports
+	opt.Region = opt.Regions[0]
+	if opt.Partition == "" {
+		opt.Partition = partition.FromRegion(opt.Region)
+	}
+	log.DebugContext(ctx, "Scanning regions", log.Any("regions", opt.Regions))
+	return nil
+}
+
 func filterServices(ctx context.Context, opt *flag.Options) error {
 	switch {
 	case len(opt.Services) == 0 && len(opt.SkipServices) == 0:
@@ -133,50 +276,331 @@ func filterServices(ctx context.Context, opt *flag.Options) error {
 	return nil
 }
 
-func Run(ctx context.Context, opt flag.Options) error {
+func Run(ctx context.Context, opt flag.Options) (err error) {
 	ctx, cancel := context.WithTimeout(ctx, opt.GlobalOptions.Timeout)
 	defer cancel()
 
 	ctx = log.WithContextPrefix(ctx, "aws")
 
-	var err error
 	defer func() {
 		if errors.Is(err, context.DeadlineExceeded) {
 			log.Warn("Provide a higher timeout value, see https://aquasecurity.github.io/trivy/latest/docs/configuration/")
 		}
 	}()
 
+	ctx, endTracing, err := startTracing(ctx, opt, "scan")
+	if err != nil {
+		return err
+	}
+	defer func() { endTracing(err) }()
+
+	ctx, closeAuditLog, err := startAuditLog(ctx, opt)
+	if err != nil {
+		return err
+	}
+	defer closeAuditLog()
+
 	if err := processOptions(ctx, &opt); err != nil {
 		return err
 	}
 
-	results, cached, err := awsScanner.NewScanner().Scan(ctx, opt)
+	// the previous report and baseline are loaded upfront so that a bad path fails before the scan
+	var previous []types.Result
+	if opt.CompareTo != "" {
+		previous, err = report.LoadResults(opt.CompareTo)
+		if err != nil {
+			return err
+		}
+	}
+
+	var accepted *baseline.Baseline
+	if opt.Baseline != "" {
+		accepted, err = baseline.Load(opt.Baseline)
+		if err != nil {
+			return err
+		}
+	}
+
+	var (
+		reports   []*report.Report
+		fromCache bool
+	)
+
+	if opt.StateFile != "" {
+		r, err := scanStateFile(ctx, opt)
+		if err != nil {
+			return err
+		}
+		reports = append(reports, r)
+	} else {
+		reports, fromCache, err = scanAccounts(ctx, opt)
+		if err != nil {
+			return err
+		}
+	}
+
+	r := report.Combine(reports...)
+
+	if opt.WriteBaseline != "" {
+		return writeBaseline(ctx, r, accepted, opt.WriteBaseline)
+	}
+
+	if accepted != nil {
+		applyBaseline(ctx, r, accepted)
+		// the previous failures are excepted as the current ones are, so that they are not reported as resolved
+		accepted.Apply(previous, clock.Now(ctx))
+	}
+
+	if opt.CompareTo != "" {
+		log.DebugContext(ctx, "Writing comparison with the previous report to output...")
+
+		diff, err := report.WriteDiff(ctx, r, opt.Options, previous)
+		if err != nil {
+			return xerrors.Errorf("unable to write comparison: %w", err)
+		}
+		return exit(ctx, opt, r, diff.HasAdded())
+	}
+
+	log.DebugContext(ctx, "Writing report to output...")
+
+	if err := report.Write(ctx, r, opt.Options, fromCache, opt.ShowStats); err != nil {
+		return xerrors.Errorf("unable to write results: %w", err)
+	}
+
+	return exit(ctx, opt, r, r.Failed())
+}
+
+// exit returns the error carrying the exit code of the scan, incomplete coverage takes precedence over failures
+func exit(ctx context.Context, opt flag.Options, r *report.Report, failed bool) error {
+	if opt.ExitOnGaps != 0 && r.Incomplete() {
+		log.WarnContext(ctx, "Resources could not be fully checked, see the scan gaps of the report", log.Int("gaps", len(r.Gaps)))
+		return &types.ExitError{Code: opt.ExitOnGaps}
+	}
+	return operation.Exit(opt.Options, failed, types.Metadata{})
+}
+
+// writeBaseline writes a baseline accepting every failure of the report
+func writeBaseline(ctx context.Context, r *report.Report, previous *baseline.Baseline, path string) error {
+	var results types.Results
+	for _, resultsAtTime := range r.Results {
+		results = append(results, resultsAtTime.Results...)
+	}
+
+	b := baseline.FromResults(results, previous)
+	if err := baseline.Write(path, b); err != nil {
+		return err
+	}
+	log.InfoContext(ctx, "Baseline written", log.String("path", path), log.Int("entries", len(b.Entries)))
+	return nil
+}
+
+// applyBaseline reports the failures accepted by the baseline as exceptions and flags the expired entries
+func applyBaseline(ctx context.Context, r *report.Report, b *baseline.Baseline) {
+	now := clock.Now(ctx)
+	for _, rep := range append([]*report.Report{r}, r.Accounts...) {
+		for _, resultsAtTime := range rep.Results {
+			b.Apply(resultsAtTime.Results, now)
+		}
+	}
+
+	for _, entry := range b.Expired(now) {
+		log.WarnContext(ctx, "Baseline entry has expired, the failure is reported again",
+			log.String("resource", entry.Resource),
+			log.String("avd_id", entry.AVDID),
+			log.String("owner", entry.Owner),
+			log.String("expires", entry.Expires.Format(time.DateOnly)),
+		)
+	}
+}
+
+func scanAccounts(ctx context.Context, opt flag.Options) ([]*report.Report, bool, error) {
+	accounts, err := resolveAccounts(ctx, opt)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var (
+		reports   []*report.Report
+		fromCache bool
+	)
+
+	for _, account := range accounts {
+		accountOpt := opt
+		accountOpt.Account = account
+
+		r, cached, err := scanAccount(ctx, accountOpt)
+		if err != nil {
+			if !opt.MultiAccount() {
+				return nil, false, err
+			}
+			log.ErrorContext(ctx, "Failed to scan account", log.String("account", account), log.Err(err))
+			continue
+		}
+		reports = append(reports, r)
+		fromCache = fromCache || cached
+	}
+
+	if len(reports) == 0 {
+		return nil, false, xerrors.Errorf("none of the %d accounts could be scanned", len(accounts))
+	}
+	return reports, fromCache, nil
+}
+
+// scanStateFile evaluates the checks against a previously exported state without access to AWS
+func scanStateFile(ctx context.Context, opt flag.Options) (*report.Report, error) {
+	log.InfoContext(ctx, "Scanning state file", log.String("path", opt.StateFile))
+
+	f, err := statefile.Load(opt.StateFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// an offline scan must not download the checks bundle, so the cached bundle is used if there is
+	// one and the embedded checks otherwise
+	opt.SkipCheckUpdate = true
+
+	if opt.Account == "" {
+		opt.Account = f.AccountID
+	}
+	if opt.Region == "" {
+		opt.Region = f.Region
+	}
+	opt.Regions = []string{opt.Region}
+
+	r, _, err := scanRegion(ctx, awsScanner.NewScannerWithStateFile(f), opt)
+	return r, err
+}
+
+func scanAccount(ctx context.Context, opt flag.Options) (*report.Report, bool, error) {
+	scanner := awsScanner.NewScanner()
+	if opt.MultiAccount() {
+		log.InfoContext(ctx, "Scanning account", log.String("account", opt.Account))
+		credentials, err := assumeRoleCredentials(ctx, opt)
+		if err != nil {
+			return nil, false, err
+		}
+		scanner = awsScanner.NewScannerWithCredentials(credentials)
+	}
+
+	var (
+		reports   []*report.Report
+		fromCache bool
+	)
+
+	for i, region := range opt.Regions {
+		regionOpt := opt
+		regionOpt.Region = region
+		if i > 0 {
+			// global services are covered by the first region
+			regionOpt.Services = slices.DeleteFunc(slices.Clone(opt.Services), func(service string) bool {
+				return slices.Contains(awsScanner.GlobalServices(), service)
+			})
+			if len(regionOpt.Services) == 0 {
+				continue
+			}
+		}
+
+		r, cached, err := scanRegion(ctx, scanner, regionOpt)
+		if err != nil {
+			return nil, false, err
+		}
+		reports = append(reports, r)
+		fromCache = fromCache || cached
+	}
+
+	return report.Merge(reports...), fromCache, nil
+}
+
+func scanRegion(ctx context.Context, scanner *awsScanner.AWSScanner, opt flag.Options) (*report.Report, bool, error) {
+	if len(opt.Regions) > 1 {
+		log.InfoContext(ctx, "Scanning region", log.String("region", opt.Region))
+	}
+
+	results, f, cached, err := scanner.Scan(ctx, opt)
 	if err != nil {
 		var aerr errs.AdapterError
 		if errors.As(err, &aerr) {
 			for _, e := range aerr.Errors() {
-				log.WarnContext(ctx, "Adapter error", log.Err(e))
+				log.WarnContext(ctx, "Adapter error", log.String("region", opt.Region), log.Err(e))
 			}
 		} else {
-			return xerrors.Errorf("aws scan error: %w", err)
+			return nil, false, xerrors.Errorf("aws scan error: %w", err)
 		}
 	}
 
-	log.DebugContext(ctx, "Writing report to output...")
-
 	sort.Slice(results, func(i, j int) bool {
 		return results[i].Rule().AVDID < results[j].Rule().AVDID
 	})
 
+	if len(opt.ARNs) > 0 {
+		results = filterResultsByARN(results, opt.ARNs)
+	}
+
 	res := results.GetFailed()
 	if opt.MisconfOptions.IncludeNonFailures {
 		res = results
 	}
 
 	r := report.New(ProviderAWS, opt.Account, opt.Region, res, opt.Services)
-	if err := report.Write(ctx, r, opt.Options, cached); err != nil {
-		return xerrors.Errorf("unable to write results: %w", err)
+	if f != nil {
+		r.AddTags(f.Tags)
+		r.AddGaps(f.ScanErrors)
+		r.AddLastScanned(f.Updated)
+	}
+	r.AddStats(scanner.Stats())
+	r.AddResources(results)
+	return r, cached, nil
+}
+
+// filterResultsByARN keeps only the results of the given resources
+func filterResultsByARN(results scan.Results, arns []string) scan.Results {
+	var filtered scan.Results
+	for _, result := range results {
+		if slices.Contains(arns, result.Flatten().Resource) {
+			filtered = append(filtered, result)
+		}
+	}
+	return filtered
+}
+
+// loadAWSConfig loads the default AWS configuration, recording its calls in the audit log of the context
+// along with the calls of the scan
+func loadAWSConfig(ctx context.Context, region, endpoint string) (aws.Config, error) {
+	cfg, err := config.LoadDefaultAWSConfig(ctx, region, endpoint)
+	if err != nil {
+		return aws.Config{}, err
 	}
+	return audit.WithLog(cfg, audit.FromContext(ctx)), nil
+}
 
-	return operation.Exit(opt.Options, r.Failed(), types.Metadata{})
+// startAuditLog opens the audit log of the options, if any, returning a context which carries it to every
+// AWS client of the command and the function closing it
+func startAuditLog(ctx context.Context, opt flag.Options) (context.Context, func(), error) {
+	if opt.AuditLog == "" {
+		return ctx, func() {}, nil
+	}
+	// the log is appended to, as several runs may be audited in the same file
+	f, err := os.OpenFile(opt.AuditLog, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, nil, xerrors.Errorf("failed to open audit log: %w", err)
+	}
+	return audit.NewContext(ctx, audit.NewLog(f)), func() { _ = f.Close() }, nil
+}
+
+// startTracing exports the traces if requested and starts the span of the command. The returned function
+// ends the span with the error of the command and flushes the traces.
+func startTracing(ctx context.Context, opt flag.Options, name string) (context.Context, func(error), error) {
+	shutdown, err := tracing.Setup(ctx, opt.TraceEndpoint, opt.TraceFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, span := tracing.Tracer().Start(ctx, name)
+	return ctx, func(err error) {
+		tracing.End(span, err)
+		// the traces are flushed even if the command timed out
+		if err := shutdown(context.WithoutCancel(ctx)); err != nil {
+			log.WarnContext(ctx, "Failed to export traces", log.Err(err))
+		}
+	}, nil
 }
@@ -2,6 +2,7 @@ package commands_test
 
 import (
 	"context"
+	"encoding/json"
 	"io"
 	"os"
 	"path/filepath"
@@ -15,6 +16,7 @@ import (
 
 	"github.com/aquasecurity/trivy-aws/pkg/commands"
 	"github.com/aquasecurity/trivy/pkg/clock"
+	"github.com/aquasecurity/trivy/pkg/types"
 )
 
 const (
@@ -27,7 +29,7 @@ func Test_Run(t *testing.T) {
 	tests := []struct {
 		name              string
 		args              []string
-		cacheFile         string
+		cache             string
 		supportedServices []string
 		golden            string
 		wantErr           string
@@ -40,7 +42,7 @@ func Test_Run(t *testing.T) {
 				"--format", "json",
 			},
 			supportedServices: []string{"s3"},
-			cacheFile:         "s3onlycache.json",
+			cache:             "s3onlycache",
 			golden:            "s3-scan.json.golden",
 		},
 		{
@@ -54,7 +56,7 @@ func Test_Run(t *testing.T) {
 				"--format", "json",
 			},
 			supportedServices: []string{"s3"},
-			cacheFile:         "s3onlycache.json",
+			cache:             "s3onlycache",
 			golden:            "custom-scan.json.golden",
 		},
 		{
@@ -66,9 +68,31 @@ func Test_Run(t *testing.T) {
 				"--compliance", filepath.Join("@testdata", "example-spec.yaml"),
 			},
 			supportedServices: []string{"s3"},
-			cacheFile:         "s3onlycache.json",
+			cache:             "s3onlycache",
 			golden:            "compliance-report-summary.golden",
 		},
+		{
+			name: "invalid tag filter",
+			args: []string{
+				"--service", "s3",
+				"--include-tag", "=payments",
+				"--format", "json",
+			},
+			supportedServices: []string{"s3"},
+			cache:             "s3onlycache",
+			wantErr:           `invalid tag filter "=payments"`,
+		},
+		{
+			name: "arn of a service which can not scan individual resources",
+			args: []string{
+				"--service", "cloudtrail",
+				"--arn", "arn:aws:cloudtrail:us-east-1:12345678:trail/management",
+				"--format", "json",
+			},
+			supportedServices: []string{"s3", "cloudtrail"},
+			cache:             "s3andcloudtrailcache",
+			wantErr:           `service 'cloudtrail' does not support scanning individual resources with --arn`,
+		},
 		{
 			name: "scan an unsupported service",
 			args: []string{
@@ -84,7 +108,7 @@ func Test_Run(t *testing.T) {
 				"--format", "json",
 			},
 			supportedServices: []string{"s3", "cloudtrail"},
-			cacheFile:         "s3andcloudtrailcache.json",
+			cache:             "s3andcloudtrailcache",
 			golden:            "s3-cloud-trail-scan.json.golden",
 		},
 		{
@@ -96,7 +120,7 @@ func Test_Run(t *testing.T) {
 				"--format", "json",
 			},
 			supportedServices: []string{"s3", "cloudtrail"},
-			cacheFile:         "s3andcloudtrailcache.json",
+			cache:             "s3andcloudtrailcache",
 			// we skip cloudtrail but still expect results from it as it is cached
 			golden: "s3-cloud-trail-scan.json.golden",
 		},
@@ -108,7 +132,7 @@ func Test_Run(t *testing.T) {
 				"--format", "json",
 			},
 			supportedServices: []string{"s3", "cloudtrail", "iam"},
-			cacheFile:         "s3onlycache.json",
+			cache:             "s3onlycache",
 			golden:            "s3-scan.json.golden",
 		},
 		{
@@ -119,8 +143,58 @@ func Test_Run(t *testing.T) {
 				"--include-non-failures",
 				"--format", "json",
 			},
-			cacheFile: "s3andcloudtrailcache.json",
-			wantErr:   "service: s3 specified to both skip and include",
+			cache:   "s3andcloudtrailcache",
+			wantErr: "service: s3 specified to both skip and include",
+		},
+		{
+			name: "fail - both region and regions specified",
+			args: []string{
+				"--regions", "us-west-2,eu-west-1",
+				"--format", "json",
+			},
+			wantErr: "--region and --regions cannot be used together",
+		},
+		{
+			name: "fail - multiple accounts without a role to assume",
+			args: []string{
+				"--account-list", filepath.Join("testdata", "accounts.txt"),
+				"--format", "json",
+			},
+			wantErr: "--assume-role is required to scan multiple accounts",
+		},
+		{
+			name: "scan a state file without credentials",
+			args: []string{
+				"--service", "s3",
+				"--state-file", filepath.Join("testdata", "s3-state.json"),
+				"--include-non-failures",
+				"--format", "json",
+			},
+			supportedServices: []string{"s3"},
+			golden:            "s3-scan.json.golden",
+		},
+		{
+			name: "fail - state file with cache update",
+			args: []string{
+				"--state-file", filepath.Join("testdata", "s3-state.json"),
+				"--update-cache",
+				"--format", "json",
+			},
+			wantErr: "--update-cache cannot be used with --state-file",
+		},
+		{
+			name: "compare with a previous report",
+			args: []string{
+				"--service", "s3",
+				"--compare-to", filepath.Join("testdata", "s3-scan.json.golden"),
+				"--ignorefile", filepath.Join("testdata", ".trivyignore"),
+				// only newly added failures fail the scan
+				"--exit-code", "1",
+				"--format", "json",
+			},
+			supportedServices: []string{"s3"},
+			cache:             "s3onlycache",
+			golden:            "s3-compare.json.golden",
 		},
 		{
 			name: "ignore findings with .trivyignore",
@@ -130,7 +204,7 @@ func Test_Run(t *testing.T) {
 				"--ignorefile", filepath.Join("testdata", ".trivyignore"),
 			},
 			supportedServices: []string{"s3"},
-			cacheFile:         "s3onlycache.json",
+			cache:             "s3onlycache",
 			golden:            "s3-scan-with-ignores.json.golden",
 		},
 	}
@@ -164,13 +238,8 @@ func Test_Run(t *testing.T) {
 
 			args = append(args, tt.args...)
 
-			if tt.cacheFile != "" {
-				cacheFile := filepath.Join(cacheDir, "cloud", "aws", account, region, "data.json")
-				require.NoError(t, os.MkdirAll(filepath.Dir(cacheFile), 0700))
-
-				cacheData, err := os.ReadFile(filepath.Join("testdata", tt.cacheFile))
-				require.NoError(t, err)
-				require.NoError(t, os.WriteFile(cacheFile, cacheData, 0600))
+			if tt.cache != "" {
+				copyCache(t, cacheDir, tt.cache)
 			}
 
 			err := run(args)
@@ -205,6 +274,113 @@ func run(args []string) error {
 	return app.ExecuteContext(ctx)
 }
 
+// copyCache replaces the cache of the test account and region with the cache in the testdata directory
+func copyCache(t *testing.T, cacheDir, name string) {
+	dir := filepath.Join(cacheDir, "cloud", "aws", account, region)
+	require.NoError(t, os.RemoveAll(dir))
+	require.NoError(t, os.CopyFS(dir, os.DirFS(filepath.Join("testdata", name))))
+}
+
 func normalizeNewlines(input string) string {
 	return strings.ReplaceAll(input, "\r\n", "\n")
 }
+
+func Test_Baseline(t *testing.T) {
+	oldAllSupportedServicesFunc := commands.AllSupportedServicesFunc
+	commands.AllSupportedServicesFunc = func() []string {
+		return []string{"s3"}
+	}
+	defer func() {
+		commands.AllSupportedServicesFunc = oldAllSupportedServicesFunc
+	}()
+
+	cacheDir := t.TempDir()
+	copyCache(t, cacheDir, "s3onlycache")
+
+	baselineFile := filepath.Join(t.TempDir(), "baseline.yaml")
+
+	args := func(extra ...string) []string {
+		return append([]string{
+			"--region", region,
+			"--account", account,
+			"--skip-check-update",
+			"--quiet",
+			"--timeout", time.Minute.String(),
+			"--cache-dir", cacheDir,
+			"--max-cache-age", "876000h",
+			"--output", filepath.Join(t.TempDir(), "output"),
+			"--format", "json",
+			"--exit-code", "1",
+		}, extra...)
+	}
+
+	// the known failures fail the scan without a baseline
+	require.Error(t, run(args()))
+
+	require.NoError(t, run(args("--write-baseline", baselineFile)))
+	assert.FileExists(t, baselineFile)
+
+	require.NoError(t, run(args("--baseline", baselineFile)))
+}
+
+func Test_ExitOnGaps(t *testing.T) {
+	oldAllSupportedServicesFunc := commands.AllSupportedServicesFunc
+	commands.AllSupportedServicesFunc = func() []string {
+		return []string{"s3"}
+	}
+	defer func() {
+		commands.AllSupportedServicesFunc = oldAllSupportedServicesFunc
+	}()
+
+	cacheDir := t.TempDir()
+	copyCache(t, cacheDir, "s3onlycache")
+	metadataFile := filepath.Join(cacheDir, "cloud", "aws", account, region, "metadata.json")
+	cacheData, err := os.ReadFile(metadataFile)
+	require.NoError(t, err)
+
+	// the cached scan could not check the policy of the bucket, recorded with the keys of an older cache
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(cacheData, &record))
+	s3Metadata := record["service_metadata"].(map[string]any)["s3"].(map[string]any)
+	s3Metadata["scan_errors"] = []map[string]any{
+		{
+			"service":   "s3",
+			"region":    region,
+			"resource":  "examplebucket",
+			"operation": "GetBucketPolicy",
+			"class":     "AccessDenied",
+			"count":     1,
+		},
+	}
+	cacheData, err = json.Marshal(record)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(metadataFile, cacheData, 0600))
+
+	outputFile := filepath.Join(t.TempDir(), "output")
+	args := func(extra ...string) []string {
+		return append([]string{
+			"--region", region,
+			"--account", account,
+			"--skip-check-update",
+			"--quiet",
+			"--timeout", time.Minute.String(),
+			"--cache-dir", cacheDir,
+			"--max-cache-age", "876000h",
+			"--output", outputFile,
+			"--format", "json",
+		}, extra...)
+	}
+
+	// the gaps are reported, but only fail the scan when asked to
+	require.NoError(t, run(args()))
+
+	output, err := os.ReadFile(outputFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(output), `"Gaps": [`)
+	assert.Contains(t, string(output), `"Operation": "GetBucketPolicy"`)
+
+	err = run(args("--exit-on-gaps", "3"))
+	var exitErr *types.ExitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 3, exitErr.Code)
+}
@@ -1,26 +1,42 @@
 package concurrency
 
 import (
+	"context"
 	"sync"
+	"time"
 
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy-aws/pkg/errs"
 	"github.com/aquasecurity/trivy-aws/pkg/progress"
 	"github.com/aquasecurity/trivy/pkg/iac/state"
 	"github.com/aquasecurity/trivy/pkg/log"
 )
 
 type Context interface {
+	Context() context.Context
 	ConcurrencyStrategy() Strategy
+	// ResourceTimeout returns how long a single resource may take to adapt, zero means no limit
+	ResourceTimeout() time.Duration
 	Tracker() progress.ServiceTracker
+	RecordError(resource string, err error)
 }
 
-func Adapt[T any, S any](items []T, ctx Context, adapt func(T) (*S, error)) []S {
-	return AdaptWithState(items, nil, ctx, func(item T, _ *state.State) (*S, error) {
-		return adapt(item)
+// Adapt adapts the items concurrently, see AdaptWithState
+func Adapt[T any, S any](items []T, ctx Context, adapt func(context.Context, T) (*S, error)) []S {
+	return AdaptWithState(items, nil, ctx, func(resourceCtx context.Context, item T, _ *state.State) (*S, error) {
+		return adapt(resourceCtx, item)
 	})
 }
 
-func AdaptWithState[T any, S any](items []T, currentState *state.State, ctx Context, adapt func(T, *state.State) (*S, error)) []S {
+// AdaptWithState adapts the items concurrently. Once the context is done no further items are adapted,
+// the results adapted until then are returned and the remaining items are recorded as a *errs.TimeoutError.
+// Each item is adapted with a context of its own, which is cancelled once the resource timeout elapses, so
+// the calls of the adapt function must use it rather than the context of the service: an adapt function which
+// ignores it holds its worker until it returns.
+func AdaptWithState[T any, S any](items []T, currentState *state.State, ctx Context, adapt func(context.Context, T, *state.State) (*S, error)) []S {
 	processes := getProcessCount(ctx.ConcurrencyStrategy())
+	runCtx := ctx.Context()
 
 	// TODO: use InfoContext
 	log.Info("Start concurrent adapt",
@@ -33,23 +49,35 @@ func AdaptWithState[T any, S any](items []T, currentState *state.State, ctx Cont
 	wg.Add(processes)
 
 	var results []S
+	var processed int
 
 	for i := 0; i < processes; i++ {
 		go func() {
-			for {
-				in, ok := <-ch
-				if !ok {
-					wg.Done()
-					return
+			defer wg.Done()
+			for in := range ch {
+				// drain the items which were queued before the context was done
+				if runCtx.Err() != nil {
+					continue
 				}
-				out, err := adapt(in, currentState)
+				out, err := adaptResource(runCtx, ctx.ResourceTimeout(), in, currentState, adapt)
 				ctx.Tracker().IncrementResource()
+				if err != nil && runCtx.Err() != nil {
+					// the resource was cut short, it is reported along with the remaining resources
+					continue
+				}
+
+				mu.Lock()
+				processed++
+				mu.Unlock()
+
 				if err != nil {
 					// TODO: use ErrorContext
 					log.Error("Error to adapt resource", log.Any("resource", in), log.Err(err))
+					ctx.RecordError(resourceID(in), err)
 					continue
 				}
 
+				progress.ResourceAdapted(ctx.Tracker())
 				if out != nil {
 					mu.Lock()
 					results = append(results, *out)
@@ -59,12 +87,44 @@ func AdaptWithState[T any, S any](items []T, currentState *state.State, ctx Cont
 		}()
 	}
 
+produce:
 	for _, item := range items {
-		ch <- item
+		select {
+		case ch <- item:
+		case <-runCtx.Done():
+			break produce
+		}
 	}
 
 	close(ch)
 	wg.Wait()
 
+	if err := runCtx.Err(); err != nil && processed < len(items) {
+		timeoutErr := &errs.TimeoutError{Processed: processed, Total: len(items), Err: err}
+		log.Warn("Resources were not adapted", log.Err(timeoutErr))
+		ctx.RecordError("", timeoutErr)
+	}
+
 	return results
 }
+
+// adaptResource adapts the item with a context which is cancelled once the timeout elapses, so that its calls
+// in flight return. The adapt function is always waited for, so that it never touches the state after the
+// pool has moved on, and the result of an item which was not adapted in time is discarded.
+func adaptResource[T any, S any](ctx context.Context, timeout time.Duration, item T, currentState *state.State, adapt func(context.Context, T, *state.State) (*S, error)) (*S, error) {
+	if timeout <= 0 {
+		return adapt(ctx, item, currentState)
+	}
+
+	resourceCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	out, err := adapt(resourceCtx, item, currentState)
+	if resourceCtx.Err() == nil {
+		return out, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return nil, xerrors.Errorf("resource not adapted within %s: %w", timeout, context.DeadlineExceeded)
+}
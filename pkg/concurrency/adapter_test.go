@@ -0,0 +1,121 @@
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/trivy-aws/pkg/errs"
+	"github.com/aquasecurity/trivy-aws/pkg/progress"
+	"github.com/aquasecurity/trivy/pkg/iac/providers/aws/s3"
+	"github.com/aquasecurity/trivy/pkg/iac/state"
+)
+
+type testContext struct {
+	ctx             context.Context
+	resourceTimeout time.Duration
+
+	mu     sync.Mutex
+	errors map[string]error
+}
+
+func newTestContext(ctx context.Context, resourceTimeout time.Duration) *testContext {
+	return &testContext{
+		ctx:             ctx,
+		resourceTimeout: resourceTimeout,
+		errors:          make(map[string]error),
+	}
+}
+
+func (c *testContext) Context() context.Context         { return c.ctx }
+func (c *testContext) ConcurrencyStrategy() Strategy    { return OneAtATimeStrategy }
+func (c *testContext) ResourceTimeout() time.Duration   { return c.resourceTimeout }
+func (c *testContext) Tracker() progress.ServiceTracker { return progress.NoProgress }
+
+func (c *testContext) RecordError(resource string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.errors[resource] = err
+}
+
+func Test_AdaptWithCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	adaptCtx := newTestContext(ctx, 0)
+
+	results := Adapt([]string{"a", "b", "c", "d"}, adaptCtx, func(_ context.Context, item string) (*string, error) {
+		if item == "b" {
+			cancel()
+		}
+		return &item, nil
+	})
+
+	// the items adapted before the context was done are kept
+	assert.Equal(t, []string{"a", "b"}, results)
+
+	var timeoutErr *errs.TimeoutError
+	require.ErrorAs(t, adaptCtx.errors[""], &timeoutErr)
+	assert.Equal(t, 2, timeoutErr.Processed)
+	assert.Equal(t, 4, timeoutErr.Total)
+	assert.ErrorIs(t, timeoutErr, context.Canceled)
+}
+
+func Test_AdaptWithResourceTimeout(t *testing.T) {
+	adaptCtx := newTestContext(context.Background(), 10*time.Millisecond)
+
+	results := Adapt([]string{"fast", "slow"}, adaptCtx, func(ctx context.Context, item string) (*string, error) {
+		if item == "slow" {
+			// the result of a resource which finishes after the timeout is discarded
+			<-ctx.Done()
+		}
+		return &item, nil
+	})
+
+	assert.Equal(t, []string{"fast"}, results)
+	require.Len(t, adaptCtx.errors, 1)
+	assert.True(t, errors.Is(adaptCtx.errors["slow"], context.DeadlineExceeded))
+	assert.Equal(t, errs.ClassTimeout, errs.Classify(adaptCtx.errors["slow"]))
+}
+
+func Test_AdaptCancelsTimedOutResource(t *testing.T) {
+	adaptCtx := newTestContext(context.Background(), 10*time.Millisecond)
+
+	cancelled := make(chan error, 1)
+	results := Adapt([]string{"slow"}, adaptCtx, func(ctx context.Context, item string) (*string, error) {
+		<-ctx.Done()
+		cancelled <- ctx.Err()
+		return nil, ctx.Err()
+	})
+
+	assert.Empty(t, results)
+	assert.Equal(t, errs.ClassTimeout, errs.Classify(adaptCtx.errors["slow"]))
+
+	select {
+	case err := <-cancelled:
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	case <-time.After(time.Second):
+		t.Fatal("the context of the timed out resource was not cancelled")
+	}
+}
+
+func Test_AdaptWaitsForAdapterIgnoringContext(t *testing.T) {
+	adaptCtx := newTestContext(context.Background(), 10*time.Millisecond)
+
+	currentState := &state.State{}
+	results := AdaptWithState([]string{"slow"}, currentState, adaptCtx, func(_ context.Context, item string, s *state.State) (*string, error) {
+		// the adapter ignores the context and writes the state after the timeout
+		time.Sleep(50 * time.Millisecond)
+		s.AWS.S3.Buckets = append(s.AWS.S3.Buckets, s3.Bucket{})
+		return &item, nil
+	})
+
+	assert.Empty(t, results)
+	assert.Equal(t, errs.ClassTimeout, errs.Classify(adaptCtx.errors["slow"]))
+	// the adapter finished before AdaptWithState returned, so the state is not written concurrently
+	assert.Len(t, currentState.AWS.S3.Buckets, 1)
+}
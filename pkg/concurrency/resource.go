@@ -0,0 +1,62 @@
+package concurrency
+
+import (
+	"reflect"
+	"strings"
+)
+
+// resourceID returns an identifier of an item passed to an adapter, so that errors can be attributed to the
+// resource. Items are either identifiers already, or SDK types whose ARN, ID or name identifies them.
+func resourceID(item any) string {
+	v := reflect.ValueOf(item)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Struct:
+	default:
+		return ""
+	}
+
+	typeName := v.Type().Name()
+	fields := make(map[string]string)
+	var names []string
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		value := v.Field(i)
+		if value.Kind() == reflect.Pointer && !value.IsNil() {
+			value = value.Elem()
+		}
+		if value.Kind() != reflect.String || value.String() == "" {
+			continue
+		}
+		fields[field.Name] = value.String()
+		names = append(names, field.Name)
+	}
+
+	// the identifiers named after the type are preferred over those of related resources
+	for _, suffixes := range [][]string{{"Arn", "ARN"}, {"Id", "Name", "Url"}} {
+		for _, suffix := range suffixes {
+			if value, ok := fields[typeName+suffix]; ok {
+				return value
+			}
+		}
+		for _, name := range names {
+			for _, suffix := range suffixes {
+				if strings.HasSuffix(name, suffix) {
+					return fields[name]
+				}
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,71 @@
+package concurrency
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type Queue struct {
+	QueueUrl *string
+	QueueArn *string
+}
+
+type Instance struct {
+	ImageId    *string
+	InstanceId *string
+	SubnetId   *string
+}
+
+type Listener struct {
+	LoadBalancerArn *string
+	ListenerArn     *string
+	Port            *int32
+}
+
+func Test_resourceID(t *testing.T) {
+	str := func(s string) *string { return &s }
+
+	tests := []struct {
+		name string
+		item any
+		want string
+	}{
+		{
+			name: "identifier",
+			item: "my-bucket",
+			want: "my-bucket",
+		},
+		{
+			name: "arn preferred over url",
+			item: Queue{QueueUrl: str("https://sqs/queue"), QueueArn: str("arn:aws:sqs:us-east-1:123:queue")},
+			want: "arn:aws:sqs:us-east-1:123:queue",
+		},
+		{
+			name: "own id preferred over related ids",
+			item: &Instance{ImageId: str("ami-1"), InstanceId: str("i-1"), SubnetId: str("subnet-1")},
+			want: "i-1",
+		},
+		{
+			name: "own arn preferred over related arns",
+			item: Listener{LoadBalancerArn: str("arn:lb"), ListenerArn: str("arn:listener")},
+			want: "arn:listener",
+		},
+		{
+			name: "no identifier",
+			item: Listener{},
+			want: "",
+		},
+		{
+			name: "nil",
+			item: (*Queue)(nil),
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, resourceID(tt.item))
+		})
+	}
+}
@@ -2,20 +2,44 @@ package concurrency
 
 import "runtime"
 
-type Strategy int
+// Strategy determines how many resources of a service are adapted concurrently. The zero value is the
+// default strategy.
+type Strategy struct {
+	kind      strategyKind
+	processes int
+}
+
+type strategyKind int
 
 const (
-	DefaultStrategy Strategy = iota
-	CPUCountStrategy
-	OneAtATimeStrategy
+	defaultKind strategyKind = iota
+	cpuCountKind
+	fixedKind
+)
+
+var (
+	DefaultStrategy    = Strategy{}
+	CPUCountStrategy   = Strategy{kind: cpuCountKind}
+	OneAtATimeStrategy = FixedStrategy(1)
 )
 
+// defaultProcesses is independent of the machine, as the API rate limits of AWS rather than the CPU
+// bound how many resources can be adapted at a time
+const defaultProcesses = 10
+
+// FixedStrategy adapts the given number of resources at a time, at least one
+func FixedStrategy(processes int) Strategy {
+	return Strategy{kind: fixedKind, processes: max(processes, 1)}
+}
+
 func getProcessCount(strategy Strategy) int {
-	switch strategy {
-	case OneAtATimeStrategy:
-		return 1
-	case CPUCountStrategy, DefaultStrategy:
+	switch strategy.kind {
+	case defaultKind:
+		return defaultProcesses
+	case cpuCountKind:
 		return runtime.NumCPU()
+	case fixedKind:
+		return strategy.processes
 	default:
 		// this shouldn't be reached but at least we don't crash
 		return 1
@@ -0,0 +1,25 @@
+package concurrency
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_getProcessCount(t *testing.T) {
+	assert.Equal(t, defaultProcesses, getProcessCount(DefaultStrategy))
+	assert.Equal(t, defaultProcesses, getProcessCount(Strategy{}))
+	assert.Equal(t, runtime.NumCPU(), getProcessCount(CPUCountStrategy))
+	assert.Equal(t, 1, getProcessCount(OneAtATimeStrategy))
+	assert.Equal(t, 4, getProcessCount(FixedStrategy(4)))
+	assert.Equal(t, 1, getProcessCount(FixedStrategy(0)))
+	assert.Equal(t, 1, getProcessCount(FixedStrategy(-3)))
+}
+
+func Test_StrategyEqual(t *testing.T) {
+	// strategies are compared by value, so a fixed strategy of one process is the one at a time strategy
+	assert.Equal(t, OneAtATimeStrategy, FixedStrategy(1))
+	assert.NotEqual(t, DefaultStrategy, FixedStrategy(defaultProcesses))
+	assert.NotEqual(t, CPUCountStrategy, FixedStrategy(runtime.NumCPU()))
+}
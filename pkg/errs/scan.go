@@ -0,0 +1,184 @@
+package errs
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// ErrorClass classifies why a part of a scan could not be completed
+type ErrorClass string
+
+const (
+	ClassAccessDenied ErrorClass = "AccessDenied"
+	ClassThrottling   ErrorClass = "Throttling"
+	ClassNotFound     ErrorClass = "NotFound"
+	ClassTimeout      ErrorClass = "Timeout"
+	ClassOther        ErrorClass = "Other"
+)
+
+// accessDeniedCodes are the error codes returned by the AWS APIs when the caller lacks a permission
+var accessDeniedCodes = []string{
+	"AccessDenied",
+	"AccessDeniedException",
+	"AuthorizationError",
+	"AuthorizationErrorException",
+	"UnauthorizedOperation",
+}
+
+// ScanError is a part of a scan which could not be completed, either an API call which failed or a
+// resource which could not be adapted, so the affected resources were not fully checked. The resource
+// is empty when the error is not specific to a resource. The keys match the fields, as in the rest of
+// the JSON report, and the lower case keys written by older caches still decode into them.
+type ScanError struct {
+	Service   string
+	Region    string `json:",omitempty"`
+	Resource  string `json:",omitempty"`
+	Operation string `json:",omitempty"`
+	Class     ErrorClass
+	Message   string `json:",omitempty"`
+	Count     int
+}
+
+// NewScanError creates a scan error of the service, taking the API operation and class from the error
+func NewScanError(service, region, resource string, err error) ScanError {
+	scanErr := ScanError{
+		Service:  service,
+		Region:   region,
+		Resource: resource,
+		Class:    Classify(err),
+		Message:  err.Error(),
+		Count:    1,
+	}
+
+	var opErr *smithy.OperationError
+	if errors.As(err, &opErr) {
+		scanErr.Operation = opErr.Operation()
+	}
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		scanErr.Message = apiErr.ErrorMessage()
+	}
+	return scanErr
+}
+
+func (e ScanError) key() string {
+	return strings.Join([]string{e.Service, e.Region, e.Resource, e.Operation, string(e.Class)}, "/")
+}
+
+// Classify returns the class of an error returned by the AWS APIs
+func Classify(err error) ErrorClass {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ClassTimeout
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		code := apiErr.ErrorCode()
+		switch {
+		case slices.Contains(accessDeniedCodes, code):
+			return ClassAccessDenied
+		case isThrottlingCode(code):
+			return ClassThrottling
+		case strings.HasPrefix(code, "NoSuch") || strings.HasSuffix(code, "NotFound") || strings.HasSuffix(code, "NotFoundException") ||
+			strings.HasSuffix(code, "NotFoundFault") || strings.HasSuffix(code, "DoesNotExist"):
+			return ClassNotFound
+		}
+	}
+
+	var respErr *smithyhttp.ResponseError
+	if errors.As(err, &respErr) {
+		switch respErr.HTTPStatusCode() {
+		case http.StatusForbidden:
+			return ClassAccessDenied
+		case http.StatusTooManyRequests:
+			return ClassThrottling
+		case http.StatusNotFound:
+			return ClassNotFound
+		}
+	}
+	return ClassOther
+}
+
+func isThrottlingCode(code string) bool {
+	_, ok := retry.DefaultThrottleErrorCodes[code]
+	return ok
+}
+
+// IsAccessDenied returns whether the error was caused by a missing permission
+func IsAccessDenied(err error) bool {
+	return Classify(err) == ClassAccessDenied
+}
+
+// Collector collects the errors of a scan, it is safe for concurrent use
+type Collector struct {
+	mu     sync.Mutex
+	errors map[string]*ScanError
+}
+
+func NewCollector() *Collector {
+	return &Collector{
+		errors: make(map[string]*ScanError),
+	}
+}
+
+// Add records the error, repeated errors are counted rather than recorded again
+func (c *Collector) Add(scanErr ScanError) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if existing, ok := c.errors[scanErr.key()]; ok {
+		existing.Count += max(scanErr.Count, 1)
+		return
+	}
+	scanErr.Count = max(scanErr.Count, 1)
+	c.errors[scanErr.key()] = &scanErr
+}
+
+// List returns the collected errors in a stable order
+func (c *Collector) List() []ScanError {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var scanErrors []ScanError
+	for _, scanErr := range c.errors {
+		scanErrors = append(scanErrors, *scanErr)
+	}
+	SortScanErrors(scanErrors)
+	return scanErrors
+}
+
+// MergeScanErrors replaces the errors of the refreshed services with the fresh ones, keeping the
+// previous errors of every other service
+func MergeScanErrors(previous, fresh []ScanError, refreshed []string) []ScanError {
+	merged := make(map[string]ScanError)
+	for _, scanErr := range previous {
+		if !slices.Contains(refreshed, scanErr.Service) {
+			merged[scanErr.key()] = scanErr
+		}
+	}
+	for _, scanErr := range fresh {
+		merged[scanErr.key()] = scanErr
+	}
+
+	var scanErrors []ScanError
+	for _, scanErr := range merged {
+		scanErrors = append(scanErrors, scanErr)
+	}
+	SortScanErrors(scanErrors)
+	return scanErrors
+}
+
+// SortScanErrors sorts the errors by service, region, resource, operation and class
+func SortScanErrors(scanErrors []ScanError) {
+	slices.SortFunc(scanErrors, func(a, b ScanError) int {
+		return strings.Compare(a.key(), b.key())
+	})
+}
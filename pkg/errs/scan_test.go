@@ -0,0 +1,136 @@
+package errs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Classify(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorClass
+	}{
+		{
+			name: "access denied",
+			err:  &smithy.GenericAPIError{Code: "AccessDenied"},
+			want: ClassAccessDenied,
+		},
+		{
+			name: "wrapped unauthorized operation",
+			err:  fmt.Errorf("wrapped: %w", &smithy.GenericAPIError{Code: "UnauthorizedOperation"}),
+			want: ClassAccessDenied,
+		},
+		{
+			name: "throttling",
+			err:  &smithy.GenericAPIError{Code: "ThrottlingException"},
+			want: ClassThrottling,
+		},
+		{
+			name: "no such bucket",
+			err:  &smithy.GenericAPIError{Code: "NoSuchBucket"},
+			want: ClassNotFound,
+		},
+		{
+			name: "resource not found",
+			err:  &smithy.GenericAPIError{Code: "ResourceNotFoundException"},
+			want: ClassNotFound,
+		},
+		{
+			name: "db cluster not found",
+			err:  &smithy.GenericAPIError{Code: "DBClusterNotFoundFault"},
+			want: ClassNotFound,
+		},
+		{
+			name: "queue does not exist",
+			err:  &smithy.GenericAPIError{Code: "QueueDoesNotExist"},
+			want: ClassNotFound,
+		},
+		{
+			name: "http status without error code",
+			err: &smithyhttp.ResponseError{
+				Response: &smithyhttp.Response{Response: &http.Response{StatusCode: http.StatusTooManyRequests}},
+				Err:      errors.New("too many requests"),
+			},
+			want: ClassThrottling,
+		},
+		{
+			name: "timed out resources",
+			err:  &TimeoutError{Processed: 1, Total: 3, Err: context.DeadlineExceeded},
+			want: ClassTimeout,
+		},
+		{
+			name: "other",
+			err:  errors.New("access denied"),
+			want: ClassOther,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Classify(tt.err))
+		})
+	}
+}
+
+func Test_NewScanError(t *testing.T) {
+	err := &smithy.OperationError{
+		ServiceID:     "S3",
+		OperationName: "GetBucketPolicy",
+		Err:           &smithy.GenericAPIError{Code: "AccessDenied", Message: "not authorized"},
+	}
+
+	assert.Equal(t, ScanError{
+		Service:   "s3",
+		Region:    "us-east-1",
+		Resource:  "bucket",
+		Operation: "GetBucketPolicy",
+		Class:     ClassAccessDenied,
+		Message:   "not authorized",
+		Count:     1,
+	}, NewScanError("s3", "us-east-1", "bucket", err))
+
+	assert.Equal(t, ScanError{
+		Service: "s3",
+		Class:   ClassOther,
+		Message: "failed",
+		Count:   1,
+	}, NewScanError("s3", "", "", errors.New("failed")))
+}
+
+func Test_Collector(t *testing.T) {
+	collector := NewCollector()
+
+	denied := ScanError{Service: "s3", Region: "us-east-1", Operation: "GetBucketLogging", Class: ClassAccessDenied}
+	collector.Add(denied)
+	collector.Add(denied)
+	collector.Add(ScanError{Service: "iam", Region: "us-east-1", Resource: "user", Class: ClassOther})
+
+	assert.Equal(t, []ScanError{
+		{Service: "iam", Region: "us-east-1", Resource: "user", Class: ClassOther, Count: 1},
+		{Service: "s3", Region: "us-east-1", Operation: "GetBucketLogging", Class: ClassAccessDenied, Count: 2},
+	}, collector.List())
+}
+
+func Test_MergeScanErrors(t *testing.T) {
+	previous := []ScanError{
+		{Service: "iam", Operation: "GetAccountPasswordPolicy", Class: ClassAccessDenied, Count: 1},
+		{Service: "s3", Operation: "GetBucketLogging", Class: ClassAccessDenied, Count: 3},
+	}
+	fresh := []ScanError{
+		{Service: "s3", Operation: "GetBucketPolicy", Class: ClassThrottling, Count: 1},
+	}
+
+	// s3 was adapted again, so its previous errors are replaced, while iam comes from the cache
+	assert.Equal(t, []ScanError{
+		{Service: "iam", Operation: "GetAccountPasswordPolicy", Class: ClassAccessDenied, Count: 1},
+		{Service: "s3", Operation: "GetBucketPolicy", Class: ClassThrottling, Count: 1},
+	}, MergeScanErrors(previous, fresh, []string{"s3"}))
+}
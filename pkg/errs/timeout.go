@@ -0,0 +1,26 @@
+package errs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// TimeoutError is returned when the resources of a service could not all be adapted before the
+// context was done. The resources adapted until then are still part of the state.
+type TimeoutError struct {
+	Processed int
+	Total     int
+	Err       error
+}
+
+func (e *TimeoutError) Error() string {
+	if errors.Is(e.Err, context.DeadlineExceeded) {
+		return fmt.Sprintf("timed out after %d of %d resources", e.Processed, e.Total)
+	}
+	return fmt.Sprintf("cancelled after %d of %d resources: %v", e.Processed, e.Total, e.Err)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,78 @@
+package flag
+
+import (
+	trivyflag "github.com/aquasecurity/trivy/pkg/flag"
+)
+
+const (
+	// DumpFormatState dumps the raw state, which can be scanned later with --state-file
+	DumpFormatState = "state"
+	// DumpFormatRego dumps the state in the shape of the input of the rego checks
+	DumpFormatRego = "rego"
+)
+
+var (
+	dumpFormatFlag = trivyflag.Flag[string]{
+		Name:       "dump-format",
+		ConfigName: "cloud.dump.format",
+		Default:    DumpFormatState,
+		Values:     []string{DumpFormatState, DumpFormatRego},
+		Usage:      "Format of the dumped state, either the raw state or the input of the rego checks.",
+	}
+	dumpOutputFlag = trivyflag.Flag[string]{
+		Name:       "output",
+		ConfigName: "output",
+		Shorthand:  "o",
+		Usage:      "output file name",
+	}
+	dumpRedactFlag = trivyflag.Flag[bool]{
+		Name:       "redact",
+		ConfigName: "cloud.dump.redact",
+		Usage:      "Redact sensitive values such as ECS container environment variables and EC2 user data.",
+	}
+)
+
+type DumpFlagGroup struct {
+	Format *trivyflag.Flag[string]
+	Output *trivyflag.Flag[string]
+	Redact *trivyflag.Flag[bool]
+}
+
+type DumpOptions struct {
+	DumpFormat string
+	DumpOutput string
+	Redact     bool
+}
+
+func NewDumpFlagGroup() *DumpFlagGroup {
+	return &DumpFlagGroup{
+		Format: dumpFormatFlag.Clone(),
+		Output: dumpOutputFlag.Clone(),
+		Redact: dumpRedactFlag.Clone(),
+	}
+}
+
+func (f *DumpFlagGroup) Name() string {
+	return "Dump"
+}
+
+func (f *DumpFlagGroup) Flags() []trivyflag.Flagger {
+	return []trivyflag.Flagger{
+		f.Format,
+		f.Output,
+		f.Redact,
+	}
+}
+
+func (f *DumpFlagGroup) ToOptions(opts *trivyflag.Options) error {
+	return nil
+}
+
+func (f *DumpFlagGroup) ToPluginOptions(opts *Options) error {
+	opts.DumpOptions = DumpOptions{
+		DumpFormat: f.Format.Value(),
+		DumpOutput: f.Output.Value(),
+		Redact:     f.Redact.Value(),
+	}
+	return nil
+}
@@ -18,24 +18,292 @@ var (
 		Name:       "max-cache-age",
 		ConfigName: "cloud.max-cache-age",
 		Default:    time.Hour * 24,
-		Usage:      "The maximum age of the cloud cache. Cached data will be required from the cloud provider if it is older than this.",
+		Usage:      "The maximum age of the cloud cache. Each service is cached on its own, and its data will be required from the cloud provider if it is older than this.",
+	}
+	cloudCacheKeyFileFlag = trivyflag.Flag[string]{
+		Name:       "cache-key-file",
+		ConfigName: "cloud.cache-key-file",
+		Usage:      "Encrypt the cloud cache with AES-GCM using the base64 encoded key of 16, 24 or 32 bytes in the given file, e.g. created with 'openssl rand -base64 32'. The key can be given in the TRIVY_AWS_CACHE_KEY environment variable instead. Cache files which are not encrypted with the key, or were tampered with, are ignored and refreshed. Without a key the cache is not checked for integrity.",
+	}
+	cloudCacheBackendFlag = trivyflag.Flag[string]{
+		Name:       "cache-backend",
+		ConfigName: "cloud.cache-backend",
+		Default:    "fs",
+		Usage:      "Where to store the cloud cache: 'fs' for the cache directory, or an s3://bucket/prefix URL to share the cache between hosts, e.g. ephemeral CI runners. The bucket is accessed with the default AWS credentials of the host.",
+	}
+	cloudCacheS3RegionFlag = trivyflag.Flag[string]{
+		Name:       "cache-s3-region",
+		ConfigName: "cloud.cache-s3-region",
+		Usage:      "The region of the bucket of the S3 cache backend. Defaults to the region of the AWS configuration.",
+	}
+	cloudCacheS3EndpointFlag = trivyflag.Flag[string]{
+		Name:       "cache-s3-endpoint",
+		ConfigName: "cloud.cache-s3-endpoint",
+		Usage:      "The URL of an S3 compatible object store to use for the S3 cache backend instead of AWS, e.g. a MinIO server.",
+	}
+	cloudRegionsFlag = trivyflag.Flag[[]string]{
+		Name:       "regions",
+		ConfigName: "cloud.regions",
+		Usage:      "Scan multiple AWS regions in a single run. Can specify multiple regions using --regions A --regions B etc., or 'all-enabled' to scan every region enabled for the account.",
+	}
+	cloudARNsFlag = trivyflag.Flag[[]string]{
+		Name:       "arn",
+		ConfigName: "cloud.aws.arn",
+		Usage:      "The AWS ARN(s) to scan. Only the matching resources are fetched and updated in the cache. Supported for the ec2, iam, lambda, rds, s3, sns and sqs services. Can specify multiple ARNs using --arn A --arn B etc.",
+	}
+	cloudServiceParallelismFlag = trivyflag.Flag[int]{
+		Name:       "service-parallelism",
+		ConfigName: "cloud.service-parallelism",
+		Default:    1,
+		Usage:      "The number of services to adapt concurrently.",
+	}
+	cloudParallelFlag = trivyflag.Flag[int]{
+		Name:       "parallel",
+		ConfigName: "cloud.parallel",
+		Default:    10,
+		Usage:      "The number of resources of a service to adapt concurrently.",
+	}
+	cloudParallelLimitsFlag = trivyflag.Flag[[]string]{
+		Name:       "parallel-limit",
+		ConfigName: "cloud.parallel-limits",
+		Usage:      "Limit the number of resources of a service to adapt concurrently, in the form service=N, e.g. for services with low API rate limits. Can specify multiple limits using --parallel-limit A --parallel-limit B etc.",
+	}
+	cloudAPIRateLimitFlag = trivyflag.Flag[int]{
+		Name:       "api-rate-limit",
+		ConfigName: "cloud.api-rate-limit",
+		Usage:      "The maximum number of calls per second to each AWS API, shared by every scanned region of an account. Throttled calls are retried with an adaptive backoff either way. 0 means no limit.",
+	}
+	cloudProgressEventsFlag = trivyflag.Flag[string]{
+		Name:       "progress-events",
+		ConfigName: "cloud.progress-events",
+		Usage:      "Write the progress as JSON lines to 'stderr' or to the given file, which is appended to, instead of rendering a progress bar.",
+	}
+	cloudShowStatsFlag = trivyflag.Flag[bool]{
+		Name:       "show-stats",
+		ConfigName: "cloud.show-stats",
+		Usage:      "Show the time, resources and API calls each adapted service took in a table after the results.",
+	}
+	cloudTraceEndpointFlag = trivyflag.Flag[string]{
+		Name:       "trace-endpoint",
+		ConfigName: "cloud.trace-endpoint",
+		Usage:      "Export OpenTelemetry traces of the adapters and AWS API calls via OTLP over HTTP to the given endpoint, e.g. http://localhost:4318.",
+	}
+	cloudTraceFileFlag = trivyflag.Flag[string]{
+		Name:       "trace-file",
+		ConfigName: "cloud.trace-file",
+		Usage:      "Write OpenTelemetry traces of the adapters and AWS API calls as JSON to the given file for offline analysis.",
+	}
+	cloudAuditLogFlag = trivyflag.Flag[string]{
+		Name:       "audit-log",
+		ConfigName: "cloud.audit-log",
+		Usage:      "Append a JSON line to the given file for every AWS API call made by the scan, with the service, operation, region, time, status and request ID of the call but no payloads.",
+	}
+	cloudServiceTimeoutFlag = trivyflag.Flag[time.Duration]{
+		Name:       "service-timeout",
+		ConfigName: "cloud.service-timeout",
+		Usage:      "The maximum time to adapt a single service. The resources adapted until then are still scanned and the rest are reported as scan gaps. 0 means no limit.",
+	}
+	cloudResourceTimeoutFlag = trivyflag.Flag[time.Duration]{
+		Name:       "resource-timeout",
+		ConfigName: "cloud.resource-timeout",
+		Usage:      "The maximum time to adapt a single resource, after which it is reported as a scan gap. 0 means no limit.",
+	}
+	cloudIncludeTagsFlag = trivyflag.Flag[[]string]{
+		Name:       "include-tag",
+		ConfigName: "cloud.include-tags",
+		Usage:      "Only scan resources with the given tag, in the form key=value, or key to match any value. key= matches an empty value. Can specify multiple tags using --include-tag A --include-tag B etc.",
+	}
+	cloudExcludeTagsFlag = trivyflag.Flag[[]string]{
+		Name:       "exclude-tag",
+		ConfigName: "cloud.exclude-tags",
+		Usage:      "Skip resources with the given tag, in the form key=value, or key to match any value. key= matches an empty value. Can specify multiple tags using --exclude-tag A --exclude-tag B etc.",
+	}
+	cloudStateFileFlag = trivyflag.Flag[string]{
+		Name:       "state-file",
+		ConfigName: "cloud.state-file",
+		Usage:      "Evaluate the checks against a previously exported state file instead of the live account. No access to AWS is required, and the checks bundle is not updated, as with --skip-check-update.",
+	}
+	cloudCompareToFlag = trivyflag.Flag[string]{
+		Name:       "compare-to",
+		ConfigName: "cloud.compare-to",
+		Usage:      "Compare the results with a previous JSON report and output the added, resolved and unchanged failures. The exit code only reflects newly added failures.",
+	}
+	cloudBaselineFlag = trivyflag.Flag[string]{
+		Name:       "baseline",
+		ConfigName: "cloud.baseline",
+		Usage:      "Baseline file of accepted failures, which are reported as exceptions rather than failures until they expire.",
+	}
+	cloudWriteBaselineFlag = trivyflag.Flag[string]{
+		Name:       "write-baseline",
+		ConfigName: "cloud.write-baseline",
+		Usage:      "Write a baseline file accepting all current failures instead of a report. Owners and expiry dates of the entries of --baseline are kept.",
+	}
+	cloudExitOnGapsFlag = trivyflag.Flag[int]{
+		Name:       "exit-on-gaps",
+		ConfigName: "cloud.exit-on-gaps",
+		Usage:      "Exit code when resources could not be fully checked, e.g. because of missing permissions",
+	}
+	cloudFeaturesFlag = trivyflag.Flag[[]string]{
+		Name:       "feature",
+		ConfigName: "cloud.feature",
+		Usage:      "Add the permissions of a feature which calls AWS on top of the scanned services: 'all-enabled-regions' for --regions all-enabled, 'preflight' for the preflight command, 'org-accounts' for --org-accounts, 'assume-role' for --assume-role, and 's3-cache' for an S3 --cache-backend. Can specify multiple features using --feature A --feature B etc.",
+	}
+	cloudOrgAccountsFlag = trivyflag.Flag[bool]{
+		Name:       "org-accounts",
+		ConfigName: "cloud.org-accounts",
+		Usage:      "Scan every active account of the AWS Organization. Requires --assume-role.",
+	}
+	cloudAccountListFlag = trivyflag.Flag[string]{
+		Name:       "account-list",
+		ConfigName: "cloud.account-list",
+		Usage:      "Scan the accounts listed in the given file, one 12-digit account ID per line. Lines starting with # are skipped. Requires --assume-role.",
+	}
+	cloudAssumeRoleFlag = trivyflag.Flag[string]{
+		Name:       "assume-role",
+		ConfigName: "cloud.assume-role",
+		Usage:      "Name of the IAM role to assume in each scanned account.",
+	}
+	cloudExternalIDFlag = trivyflag.Flag[string]{
+		Name:       "external-id",
+		ConfigName: "cloud.external-id",
+		Usage:      "External ID to use when assuming the role in each scanned account.",
+	}
+	cloudRoleSessionNameFlag = trivyflag.Flag[string]{
+		Name:       "role-session-name",
+		ConfigName: "cloud.role-session-name",
+		Default:    "trivy-aws",
+		Usage:      "Session name to use when assuming the role in each scanned account.",
 	}
 )
 
 type CloudFlagGroup struct {
-	UpdateCache *trivyflag.Flag[bool]
-	MaxCacheAge *trivyflag.Flag[time.Duration]
+	UpdateCache        *trivyflag.Flag[bool]
+	MaxCacheAge        *trivyflag.Flag[time.Duration]
+	CacheKeyFile       *trivyflag.Flag[string]
+	CacheBackend       *trivyflag.Flag[string]
+	CacheS3Region      *trivyflag.Flag[string]
+	CacheS3Endpoint    *trivyflag.Flag[string]
+	Regions            *trivyflag.Flag[[]string]
+	ARNs               *trivyflag.Flag[[]string]
+	ServiceParallelism *trivyflag.Flag[int]
+	Parallel           *trivyflag.Flag[int]
+	ParallelLimits     *trivyflag.Flag[[]string]
+	APIRateLimit       *trivyflag.Flag[int]
+	ProgressEvents     *trivyflag.Flag[string]
+	ShowStats          *trivyflag.Flag[bool]
+	TraceEndpoint      *trivyflag.Flag[string]
+	TraceFile          *trivyflag.Flag[string]
+	AuditLog           *trivyflag.Flag[string]
+	ServiceTimeout     *trivyflag.Flag[time.Duration]
+	ResourceTimeout    *trivyflag.Flag[time.Duration]
+	IncludeTags        *trivyflag.Flag[[]string]
+	ExcludeTags        *trivyflag.Flag[[]string]
+	StateFile          *trivyflag.Flag[string]
+	CompareTo          *trivyflag.Flag[string]
+	Baseline           *trivyflag.Flag[string]
+	WriteBaseline      *trivyflag.Flag[string]
+	ExitOnGaps         *trivyflag.Flag[int]
+	Features           *trivyflag.Flag[[]string]
+
+	OrgAccounts     *trivyflag.Flag[bool]
+	AccountList     *trivyflag.Flag[string]
+	AssumeRole      *trivyflag.Flag[string]
+	ExternalID      *trivyflag.Flag[string]
+	RoleSessionName *trivyflag.Flag[string]
 }
 
 type CloudOptions struct {
-	MaxCacheAge time.Duration
-	UpdateCache bool
+	MaxCacheAge        time.Duration
+	UpdateCache        bool
+	CacheKeyFile       string
+	CacheBackend       string
+	CacheS3Region      string
+	CacheS3Endpoint    string
+	Regions            []string
+	ARNs               []string
+	ServiceParallelism int
+	Parallel           int
+	ParallelLimits     []string
+	APIRateLimit       int
+	ProgressEvents     string
+	ShowStats          bool
+	TraceEndpoint      string
+	TraceFile          string
+	AuditLog           string
+	ServiceTimeout     time.Duration
+	ResourceTimeout    time.Duration
+	IncludeTags        []string
+	ExcludeTags        []string
+	StateFile          string
+	CompareTo          string
+	Baseline           string
+	WriteBaseline      string
+	ExitOnGaps         int
+	Features           []string
+
+	OrgAccounts     bool
+	AccountList     string
+	AssumeRole      string
+	ExternalID      string
+	RoleSessionName string
+
+	// Partition is derived from the caller identity or the region rather than set by a flag
+	Partition string
+}
+
+// MultiAccount returns whether the scan covers several accounts through an assumed role
+func (o CloudOptions) MultiAccount() bool {
+	return o.OrgAccounts || o.AccountList != ""
 }
 
 func NewCloudFlagGroup() *CloudFlagGroup {
 	return &CloudFlagGroup{
-		UpdateCache: cloudUpdateCacheFlag.Clone(),
-		MaxCacheAge: cloudMaxCacheAgeFlag.Clone(),
+		UpdateCache:        cloudUpdateCacheFlag.Clone(),
+		MaxCacheAge:        cloudMaxCacheAgeFlag.Clone(),
+		CacheKeyFile:       cloudCacheKeyFileFlag.Clone(),
+		CacheBackend:       cloudCacheBackendFlag.Clone(),
+		CacheS3Region:      cloudCacheS3RegionFlag.Clone(),
+		CacheS3Endpoint:    cloudCacheS3EndpointFlag.Clone(),
+		Regions:            cloudRegionsFlag.Clone(),
+		ARNs:               cloudARNsFlag.Clone(),
+		ServiceParallelism: cloudServiceParallelismFlag.Clone(),
+		Parallel:           cloudParallelFlag.Clone(),
+		ParallelLimits:     cloudParallelLimitsFlag.Clone(),
+		APIRateLimit:       cloudAPIRateLimitFlag.Clone(),
+		ProgressEvents:     cloudProgressEventsFlag.Clone(),
+		ShowStats:          cloudShowStatsFlag.Clone(),
+		TraceEndpoint:      cloudTraceEndpointFlag.Clone(),
+		TraceFile:          cloudTraceFileFlag.Clone(),
+		AuditLog:           cloudAuditLogFlag.Clone(),
+		ServiceTimeout:     cloudServiceTimeoutFlag.Clone(),
+		ResourceTimeout:    cloudResourceTimeoutFlag.Clone(),
+		IncludeTags:        cloudIncludeTagsFlag.Clone(),
+		ExcludeTags:        cloudExcludeTagsFlag.Clone(),
+		StateFile:          cloudStateFileFlag.Clone(),
+		CompareTo:          cloudCompareToFlag.Clone(),
+		Baseline:           cloudBaselineFlag.Clone(),
+		WriteBaseline:      cloudWriteBaselineFlag.Clone(),
+		ExitOnGaps:         cloudExitOnGapsFlag.Clone(),
+
+		OrgAccounts:     cloudOrgAccountsFlag.Clone(),
+		AccountList:     cloudAccountListFlag.Clone(),
+		AssumeRole:      cloudAssumeRoleFlag.Clone(),
+		ExternalID:      cloudExternalIDFlag.Clone(),
+		RoleSessionName: cloudRoleSessionNameFlag.Clone(),
+	}
+}
+
+// NewIAMPolicyFlagGroup returns the cloud flags of the iam-policy command, which doesn't call AWS
+func NewIAMPolicyFlagGroup() *CloudFlagGroup {
+	return &CloudFlagGroup{
+		Features: cloudFeaturesFlag.Clone(),
+	}
+}
+
+// NewPreflightFlagGroup returns the cloud flags of the preflight command, which calls AWS without scanning
+func NewPreflightFlagGroup() *CloudFlagGroup {
+	return &CloudFlagGroup{
+		AuditLog: cloudAuditLogFlag.Clone(),
 	}
 }
 
@@ -47,6 +315,36 @@ func (f *CloudFlagGroup) Flags() []trivyflag.Flagger {
 	return []trivyflag.Flagger{
 		f.UpdateCache,
 		f.MaxCacheAge,
+		f.CacheKeyFile,
+		f.CacheBackend,
+		f.CacheS3Region,
+		f.CacheS3Endpoint,
+		f.Regions,
+		f.ARNs,
+		f.ServiceParallelism,
+		f.Parallel,
+		f.ParallelLimits,
+		f.APIRateLimit,
+		f.ProgressEvents,
+		f.ShowStats,
+		f.TraceEndpoint,
+		f.TraceFile,
+		f.AuditLog,
+		f.ServiceTimeout,
+		f.ResourceTimeout,
+		f.IncludeTags,
+		f.ExcludeTags,
+		f.StateFile,
+		f.CompareTo,
+		f.Baseline,
+		f.WriteBaseline,
+		f.ExitOnGaps,
+		f.Features,
+		f.OrgAccounts,
+		f.AccountList,
+		f.AssumeRole,
+		f.ExternalID,
+		f.RoleSessionName,
 	}
 }
 
@@ -56,8 +354,43 @@ func (f *CloudFlagGroup) ToOptions(opts *trivyflag.Options) error {
 
 func (f *CloudFlagGroup) ToPluginOptions(opts *Options) error {
 	opts.CloudOptions = CloudOptions{
-		UpdateCache: f.UpdateCache.Value(),
-		MaxCacheAge: f.MaxCacheAge.Value(),
+		UpdateCache:        f.UpdateCache.Value(),
+		MaxCacheAge:        f.MaxCacheAge.Value(),
+		CacheKeyFile:       f.CacheKeyFile.Value(),
+		CacheBackend:       f.CacheBackend.Value(),
+		CacheS3Region:      f.CacheS3Region.Value(),
+		CacheS3Endpoint:    f.CacheS3Endpoint.Value(),
+		Regions:            f.Regions.Value(),
+		ARNs:               f.ARNs.Value(),
+		ServiceParallelism: f.ServiceParallelism.Value(),
+		Parallel:           f.Parallel.Value(),
+		ParallelLimits:     f.ParallelLimits.Value(),
+		APIRateLimit:       f.APIRateLimit.Value(),
+		ProgressEvents:     f.ProgressEvents.Value(),
+		ShowStats:          f.ShowStats.Value(),
+		TraceEndpoint:      f.TraceEndpoint.Value(),
+		TraceFile:          f.TraceFile.Value(),
+		AuditLog:           f.AuditLog.Value(),
+		ServiceTimeout:     f.ServiceTimeout.Value(),
+		ResourceTimeout:    f.ResourceTimeout.Value(),
+		IncludeTags:        f.IncludeTags.Value(),
+		ExcludeTags:        f.ExcludeTags.Value(),
+		StateFile:          f.StateFile.Value(),
+		CompareTo:          f.CompareTo.Value(),
+		Baseline:           f.Baseline.Value(),
+		WriteBaseline:      f.WriteBaseline.Value(),
+		ExitOnGaps:         f.ExitOnGaps.Value(),
+		Features:           f.Features.Value(),
+
+		OrgAccounts:     f.OrgAccounts.Value(),
+		AccountList:     f.AccountList.Value(),
+		AssumeRole:      f.AssumeRole.Value(),
+		ExternalID:      f.ExternalID.Value(),
+		RoleSessionName: f.RoleSessionName.Value(),
+	}
+	// the detailed view of a single resource relies on the ARN of the base options
+	if len(opts.ARNs) == 1 {
+		opts.ARN = opts.ARNs[0]
 	}
 	return nil
 }
@@ -59,6 +59,7 @@ func TestCloudFlagGroup_ToOptions(t *testing.T) {
 	group := flag.NewCloudFlagGroup()
 	viper.Set(group.MaxCacheAge.ConfigName, "48h")
 	viper.Set(group.UpdateCache.ConfigName, true)
+	viper.Set(group.ARNs.ConfigName, []string{"arn:aws:s3:::examplebucket"})
 
 	flags := flag.Flags{
 		CloudFlagGroup: group,
@@ -69,7 +70,9 @@ func TestCloudFlagGroup_ToOptions(t *testing.T) {
 	expected := flag.CloudOptions{
 		MaxCacheAge: time.Duration(48) * time.Hour,
 		UpdateCache: true,
+		ARNs:        []string{"arn:aws:s3:::examplebucket"},
 	}
 
 	assert.Equal(t, expected, got.CloudOptions)
+	assert.Equal(t, "arn:aws:s3:::examplebucket", got.ARN)
 }
@@ -10,11 +10,13 @@ import (
 type Flags struct {
 	BaseFlags      trivyFlag.Flags
 	CloudFlagGroup *CloudFlagGroup
+	DumpFlagGroup  *DumpFlagGroup
 }
 
 type Options struct {
 	trivyFlag.Options
 	CloudOptions
+	DumpOptions
 }
 
 func (f *Flags) Bind(cmd *cobra.Command) error {
@@ -23,7 +25,7 @@ func (f *Flags) Bind(cmd *cobra.Command) error {
 		return xerrors.Errorf("%w", err)
 	}
 
-	for _, ff := range f.CloudFlagGroup.Flags() {
+	for _, ff := range f.pluginFlags() {
 		if err := ff.Bind(cmd); err != nil {
 			return err
 		}
@@ -52,12 +54,29 @@ func (f *Flags) ToOptions(args []string) (Options, error) {
 		}
 	}
 
+	if f.DumpFlagGroup != nil {
+		if err := parseFlags(f.DumpFlagGroup); err != nil {
+			return Options{}, xerrors.Errorf("unable to parse dump flag group: %w", err)
+		}
+		if err := f.DumpFlagGroup.ToPluginOptions(&opts); err != nil {
+			return Options{}, xerrors.Errorf("dump flag error: %w", err)
+		}
+	}
+
 	return opts, nil
 }
 
 func (f *Flags) AddFlags(cmd *cobra.Command) {
 	f.BaseFlags.AddFlags(cmd)
-	for _, flag := range f.CloudFlagGroup.Flags() {
+	for _, flag := range f.pluginFlags() {
 		flag.Add(cmd)
 	}
 }
+
+func (f *Flags) pluginFlags() []trivyFlag.Flagger {
+	flags := f.CloudFlagGroup.Flags()
+	if f.DumpFlagGroup != nil {
+		flags = append(flags, f.DumpFlagGroup.Flags()...)
+	}
+	return flags
+}
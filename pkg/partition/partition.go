@@ -0,0 +1,45 @@
+package partition
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+)
+
+const (
+	AWS      = "aws"
+	China    = "aws-cn"
+	GovCloud = "aws-us-gov"
+)
+
+// FromRegion returns the partition the given region belongs to, defaulting to the commercial partition
+func FromRegion(region string) string {
+	switch {
+	case strings.HasPrefix(region, "us-gov-"):
+		return GovCloud
+	case strings.HasPrefix(region, "cn-"):
+		return China
+	default:
+		return AWS
+	}
+}
+
+// FromARN returns the partition of the given ARN, falling back to the partition of the region if the ARN is invalid
+func FromARN(s, region string) string {
+	if parsed, err := arn.Parse(s); err == nil && parsed.Partition != "" {
+		return parsed.Partition
+	}
+	return FromRegion(region)
+}
+
+// DefaultRegion returns the region that S3 reports as an empty location constraint within the partition
+func DefaultRegion(partition string) string {
+	switch partition {
+	case GovCloud:
+		return "us-gov-west-1"
+	case China:
+		return "cn-north-1"
+	default:
+		return "us-east-1"
+	}
+}
@@ -0,0 +1,42 @@
+package partition
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromRegion(t *testing.T) {
+	tests := []struct {
+		region   string
+		expected string
+	}{
+		{region: "us-east-1", expected: AWS},
+		{region: "eu-west-2", expected: AWS},
+		{region: "", expected: AWS},
+		{region: "us-gov-west-1", expected: GovCloud},
+		{region: "us-gov-east-1", expected: GovCloud},
+		{region: "cn-north-1", expected: China},
+		{region: "cn-northwest-1", expected: China},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.region, func(t *testing.T) {
+			assert.Equal(t, tt.expected, FromRegion(tt.region))
+		})
+	}
+}
+
+func TestFromARN(t *testing.T) {
+	assert.Equal(t, GovCloud, FromARN("arn:aws-us-gov:sts::123456789012:assumed-role/audit/trivy", "us-east-1"))
+	assert.Equal(t, China, FromARN("arn:aws-cn:iam::123456789012:user/admin", ""))
+	assert.Equal(t, GovCloud, FromARN("", "us-gov-west-1"))
+	assert.Equal(t, AWS, FromARN("not-an-arn", "eu-west-1"))
+}
+
+func TestDefaultRegion(t *testing.T) {
+	assert.Equal(t, "us-east-1", DefaultRegion(AWS))
+	assert.Equal(t, "us-gov-west-1", DefaultRegion(GovCloud))
+	assert.Equal(t, "cn-north-1", DefaultRegion(China))
+	assert.Equal(t, "us-east-1", DefaultRegion(""))
+}
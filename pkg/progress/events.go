@@ -0,0 +1,127 @@
+package progress
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// EventType is the kind of a progress event
+type EventType string
+
+const (
+	EventServicesTotal   EventType = "services_total"
+	EventServiceStarted  EventType = "service_started"
+	EventServiceLabel    EventType = "service_label"
+	EventResourcesTotal  EventType = "resources_total"
+	EventResourceAdapted EventType = "resource_adapted"
+	EventError           EventType = "error"
+	EventServiceFinished EventType = "service_finished"
+)
+
+// Event is a single line written by the EventTracker
+type Event struct {
+	Time     time.Time `json:"time"`
+	Type     EventType `json:"type"`
+	Account  string    `json:"account,omitempty"`
+	Region   string    `json:"region,omitempty"`
+	Service  string    `json:"service,omitempty"`
+	Label    string    `json:"label,omitempty"`
+	Resource string    `json:"resource,omitempty"`
+	Total    int       `json:"total,omitempty"`
+	Current  int       `json:"current,omitempty"`
+	Error    string    `json:"error,omitempty"`
+	// Duration is the number of seconds the service took to adapt
+	Duration float64 `json:"duration,omitempty"`
+}
+
+// EventTracker writes the progress as JSON lines, one event per line, so that it can be followed by
+// machines rather than people, e.g. in CI logs. Every event names the account and region being scanned,
+// so that the events of several scans can be told apart.
+type EventTracker struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+	account string
+	region  string
+	now     func() time.Time
+	started map[string]time.Time
+}
+
+func NewEventTracker(w io.Writer, account, region string) *EventTracker {
+	return &EventTracker{
+		encoder: json.NewEncoder(w),
+		account: account,
+		region:  region,
+		now:     time.Now,
+		started: make(map[string]time.Time),
+	}
+}
+
+func (t *EventTracker) SetTotalServices(i int) {
+	t.write(Event{Type: EventServicesTotal, Total: i})
+}
+
+func (t *EventTracker) StartService(name string) ServiceTracker {
+	t.mu.Lock()
+	t.started[name] = t.now()
+	t.mu.Unlock()
+
+	t.write(Event{Type: EventServiceStarted, Service: name})
+	return &serviceEvents{parent: t, name: name}
+}
+
+func (t *EventTracker) FinishService(name string) {
+	t.mu.Lock()
+	duration := t.now().Sub(t.started[name])
+	delete(t.started, name)
+	t.mu.Unlock()
+
+	t.write(Event{Type: EventServiceFinished, Service: name, Duration: duration.Seconds()})
+}
+
+func (t *EventTracker) write(event Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	event.Time = t.now()
+	event.Account = t.account
+	event.Region = t.region
+	// progress is best effort, a failure to write it must not fail the scan
+	_ = t.encoder.Encode(event)
+}
+
+// serviceEvents tracks the resources of a single service, several of which can be in progress at the same time
+type serviceEvents struct {
+	parent *EventTracker
+	name   string
+
+	mu      sync.Mutex
+	total   int
+	current int
+}
+
+func (s *serviceEvents) SetServiceLabel(label string) {
+	s.mu.Lock()
+	s.current = 0
+	s.mu.Unlock()
+	s.parent.write(Event{Type: EventServiceLabel, Service: s.name, Label: label})
+}
+
+func (s *serviceEvents) SetTotalResources(i int) {
+	s.mu.Lock()
+	s.total = i
+	s.mu.Unlock()
+	s.parent.write(Event{Type: EventResourcesTotal, Service: s.name, Total: i})
+}
+
+func (s *serviceEvents) IncrementResource() {
+	s.mu.Lock()
+	s.current++
+	event := Event{Type: EventResourceAdapted, Service: s.name, Total: s.total, Current: s.current}
+	s.mu.Unlock()
+	s.parent.write(event)
+}
+
+func (s *serviceEvents) ReportError(resource string, err error) {
+	s.parent.write(Event{Type: EventError, Service: s.name, Resource: resource, Error: err.Error()})
+}
@@ -0,0 +1,40 @@
+package progress
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_EventTracker(t *testing.T) {
+	var buf bytes.Buffer
+	tracker := NewEventTracker(&buf, "123456789012", "us-east-1")
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracker.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+
+	tracker.SetTotalServices(1)
+	service := tracker.StartService("s3")
+	service.SetServiceLabel("Discovering buckets...")
+	service.SetTotalResources(2)
+	service.IncrementResource()
+	service.ReportError("examplebucket", errors.New("access denied"))
+	service.IncrementResource()
+	tracker.FinishService("s3")
+
+	assert.Equal(t, `{"time":"2024-01-01T00:00:01Z","type":"services_total","account":"123456789012","region":"us-east-1","total":1}
+{"time":"2024-01-01T00:00:03Z","type":"service_started","account":"123456789012","region":"us-east-1","service":"s3"}
+{"time":"2024-01-01T00:00:04Z","type":"service_label","account":"123456789012","region":"us-east-1","service":"s3","label":"Discovering buckets..."}
+{"time":"2024-01-01T00:00:05Z","type":"resources_total","account":"123456789012","region":"us-east-1","service":"s3","total":2}
+{"time":"2024-01-01T00:00:06Z","type":"resource_adapted","account":"123456789012","region":"us-east-1","service":"s3","total":2,"current":1}
+{"time":"2024-01-01T00:00:07Z","type":"error","account":"123456789012","region":"us-east-1","service":"s3","resource":"examplebucket","error":"access denied"}
+{"time":"2024-01-01T00:00:08Z","type":"resource_adapted","account":"123456789012","region":"us-east-1","service":"s3","total":2,"current":2}
+{"time":"2024-01-01T00:00:10Z","type":"service_finished","account":"123456789012","region":"us-east-1","service":"s3","duration":7}
+`, buf.String())
+}
@@ -2,24 +2,42 @@ package progress
 
 type Tracker interface {
 	SetTotalServices(i int)
-	StartService(name string)
-	FinishService()
-	ServiceTracker
+	// StartService marks the service as in progress and returns the tracker for its resources.
+	// Several services can be in progress at the same time.
+	StartService(name string) ServiceTracker
+	FinishService(name string)
 }
 
 type ServiceTracker interface {
 	SetServiceLabel(label string)
 	SetTotalResources(i int)
 	IncrementResource()
+	// ReportError reports an error which left the resource, or the whole service if no resource is given,
+	// not fully checked
+	ReportError(resource string, err error)
+}
+
+// AdaptedTracker is implemented by the service trackers which count the resources adapted without error,
+// as IncrementResource counts every resource the adapter finished with
+type AdaptedTracker interface {
+	ResourceAdapted()
+}
+
+// ResourceAdapted marks a resource of the service as adapted without error, if the tracker counts them
+func ResourceAdapted(t ServiceTracker) {
+	if adapted, ok := t.(AdaptedTracker); ok {
+		adapted.ResourceAdapted()
+	}
 }
 
 var NoProgress = nilTracker{}
 
 type nilTracker struct{}
 
-func (n nilTracker) SetTotalServices(_ int)   {}
-func (n nilTracker) SetTotalResources(_ int)  {}
-func (n nilTracker) IncrementResource()       {}
-func (n nilTracker) StartService(_ string)    {}
-func (n nilTracker) FinishService()           {}
-func (n nilTracker) SetServiceLabel(_ string) {}
+func (n nilTracker) SetTotalServices(_ int)               {}
+func (n nilTracker) SetTotalResources(_ int)              {}
+func (n nilTracker) IncrementResource()                   {}
+func (n nilTracker) StartService(_ string) ServiceTracker { return n }
+func (n nilTracker) FinishService(_ string)               {}
+func (n nilTracker) SetServiceLabel(_ string)             {}
+func (n nilTracker) ReportError(_ string, _ error)        {}
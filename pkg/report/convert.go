@@ -3,7 +3,6 @@ package report
 import (
 	"fmt"
 	"strings"
-	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws/arn"
 
@@ -98,9 +97,9 @@ func ConvertResults(results scan.Results, provider string, scoped []string) map[
 
 			convertedArnResults = append(convertedArnResults, arnResult)
 		}
+		// the time of the scan is unknown until the state of the service is known to be fresh or cached
 		convertedResults[service] = ResultsAtTime{
-			Results:      convertedArnResults,
-			CreationTime: time.Now(),
+			Results: convertedArnResults,
 		}
 	}
 	return convertedResults
@@ -0,0 +1,242 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/table"
+	"github.com/aquasecurity/tml"
+	"github.com/aquasecurity/trivy/pkg/flag"
+	"github.com/aquasecurity/trivy/pkg/result"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+// Finding is a failed check of a resource, identified by the ARN of the resource and the AVD ID of the check
+type Finding struct {
+	Resource string
+	AVDID    string
+	Title    string `json:",omitempty"`
+	Severity string `json:",omitempty"`
+}
+
+// ServiceDiff holds the changes of the failed checks of a service between two reports
+type ServiceDiff struct {
+	Added     []Finding `json:",omitempty"`
+	Resolved  []Finding `json:",omitempty"`
+	Unchanged []Finding `json:",omitempty"`
+
+	// RemovedResources holds the resources of resolved findings which no longer exist
+	RemovedResources []string `json:",omitempty"`
+}
+
+// Diff holds the changes of the failed checks between a previous report and the current one, per service
+type Diff struct {
+	Services map[string]*ServiceDiff
+}
+
+// HasAdded returns whether any failures were introduced since the previous report
+func (d *Diff) HasAdded() bool {
+	for _, service := range d.Services {
+		if len(service.Added) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadResults loads the results of a JSON report written by Write
+func LoadResults(path string) ([]types.Result, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, xerrors.Errorf("unable to open previous report: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var previous types.Report
+	if err := json.NewDecoder(f).Decode(&previous); err != nil {
+		return nil, xerrors.Errorf("unable to decode previous report: %w", err)
+	}
+	return previous.Results, nil
+}
+
+// Compare compares the failures of the previous and current results of the given services.
+// The resources are all the resources which were evaluated for the current results, and are
+// used to tell resolved findings apart from findings of resources which no longer exist.
+func Compare(previous, current []types.Result, services, resources []string) *Diff {
+	diff := &Diff{
+		Services: make(map[string]*ServiceDiff),
+	}
+	for _, service := range services {
+		diff.Services[service] = &ServiceDiff{}
+	}
+
+	previousFailures := failures(previous, services)
+	currentFailures := failures(current, services)
+
+	for key, finding := range currentFailures {
+		service := diff.Services[key.service]
+		if _, ok := previousFailures[key]; ok {
+			service.Unchanged = append(service.Unchanged, finding)
+		} else {
+			service.Added = append(service.Added, finding)
+		}
+	}
+
+	for key, finding := range previousFailures {
+		if _, ok := currentFailures[key]; ok {
+			continue
+		}
+		service := diff.Services[key.service]
+		service.Resolved = append(service.Resolved, finding)
+		if !slices.Contains(resources, finding.Resource) && !slices.Contains(service.RemovedResources, finding.Resource) {
+			service.RemovedResources = append(service.RemovedResources, finding.Resource)
+		}
+	}
+
+	for _, service := range diff.Services {
+		sortFindings(service.Added)
+		sortFindings(service.Resolved)
+		sortFindings(service.Unchanged)
+		sort.Strings(service.RemovedResources)
+	}
+
+	return diff
+}
+
+type findingKey struct {
+	service  string
+	resource string
+	avdID    string
+}
+
+func failures(results []types.Result, services []string) map[findingKey]Finding {
+	found := make(map[findingKey]Finding)
+	for _, res := range results {
+		for _, misconfiguration := range res.Misconfigurations {
+			if misconfiguration.Status != types.MisconfStatusFailure {
+				continue
+			}
+			service := misconfiguration.CauseMetadata.Service
+			if !slices.Contains(services, service) {
+				continue
+			}
+			found[findingKey{
+				service:  service,
+				resource: misconfiguration.CauseMetadata.Resource,
+				avdID:    misconfiguration.AVDID,
+			}] = Finding{
+				Resource: misconfiguration.CauseMetadata.Resource,
+				AVDID:    misconfiguration.AVDID,
+				Title:    misconfiguration.Title,
+				Severity: misconfiguration.Severity,
+			}
+		}
+	}
+	return found
+}
+
+func sortFindings(findings []Finding) {
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].Resource != findings[j].Resource {
+			return findings[i].Resource < findings[j].Resource
+		}
+		return findings[i].AVDID < findings[j].AVDID
+	})
+}
+
+// WriteDiff compares the report with the results of a previous report and writes the changes in the given format.
+// The previous results are filtered with the ignore file as the current ones are, so that an ignored finding
+// is not reported as resolved.
+func WriteDiff(ctx context.Context, rep *Report, opt flag.Options, previous []types.Result) (*Diff, error) {
+	output, cleanup, err := opt.OutputWriter(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create output file: %w", err)
+	}
+	defer func() { _ = cleanup() }()
+
+	ignoreConf, err := result.ParseIgnoreFile(ctx, opt.IgnoreFile)
+	if err != nil {
+		return nil, xerrors.Errorf("%s error: %w", opt.IgnoreFile, err)
+	}
+
+	current, err := filterResults(ctx, rep, opt, ignoreConf, nil)
+	if err != nil {
+		return nil, err
+	}
+	previous, err = filterResultList(ctx, previous, opt, ignoreConf, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	services := slices.Clone(rep.ServicesInScope)
+	for service := range rep.Results {
+		if !slices.Contains(services, service) {
+			services = append(services, service)
+		}
+	}
+
+	diff := Compare(previous, current, services, rep.Resources)
+
+	switch opt.Format {
+	case tableFormat:
+		writeDiffTable(diff, output)
+		return diff, nil
+	case "json":
+		encoder := json.NewEncoder(output)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(diff); err != nil {
+			return nil, xerrors.Errorf("unable to write diff: %w", err)
+		}
+		return diff, nil
+	default:
+		return nil, xerrors.Errorf("format %q is not supported when comparing reports, use table or json", opt.Format)
+	}
+}
+
+func writeDiffTable(diff *Diff, output io.Writer) {
+	var services []string
+	for service := range diff.Services {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+
+	t := table.New(output)
+	t.SetHeaders("Service", "Added", "Resolved", "Removed Resources", "Unchanged")
+	t.SetRowLines(false)
+	t.SetHeaderAlignment(table.AlignLeft, table.AlignCenter, table.AlignCenter, table.AlignCenter, table.AlignCenter)
+	t.SetAlignment(table.AlignLeft, table.AlignRight, table.AlignRight, table.AlignRight, table.AlignRight)
+	for _, name := range services {
+		service := diff.Services[name]
+		t.AddRow(
+			name,
+			strconv.Itoa(len(service.Added)),
+			strconv.Itoa(len(service.Resolved)),
+			strconv.Itoa(len(service.RemovedResources)),
+			strconv.Itoa(len(service.Unchanged)),
+		)
+	}
+
+	_ = tml.Fprintf(output, "\n<bold>Changes Since Previous Report</bold>\n")
+	t.Render()
+
+	for _, name := range services {
+		service := diff.Services[name]
+		for _, finding := range service.Added {
+			_ = tml.Fprintf(output, "<red>+ %s %s %s</red> %s\n", finding.Severity, finding.AVDID, finding.Resource, finding.Title)
+		}
+		for _, finding := range service.Resolved {
+			suffix := ""
+			if slices.Contains(service.RemovedResources, finding.Resource) {
+				suffix = " (resource removed)"
+			}
+			_ = tml.Fprintf(output, "<green>- %s %s %s</green> %s%s\n", finding.Severity, finding.AVDID, finding.Resource, finding.Title, suffix)
+		}
+	}
+}
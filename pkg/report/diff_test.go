@@ -0,0 +1,162 @@
+package report
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	dbTypes "github.com/aquasecurity/trivy-db/pkg/types"
+	ftypes "github.com/aquasecurity/trivy/pkg/fanal/types"
+	"github.com/aquasecurity/trivy/pkg/flag"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+func Test_Compare(t *testing.T) {
+	failure := func(resource, avdID string) types.DetectedMisconfiguration {
+		return types.DetectedMisconfiguration{
+			AVDID:    avdID,
+			Severity: "HIGH",
+			Status:   types.MisconfStatusFailure,
+			CauseMetadata: ftypes.CauseMetadata{
+				Resource: resource,
+				Service:  "s3",
+			},
+		}
+	}
+
+	previous := []types.Result{
+		{
+			Target: "arn:aws:s3:::kept",
+			Misconfigurations: []types.DetectedMisconfiguration{
+				failure("arn:aws:s3:::kept", "AVD-AWS-0086"),
+				failure("arn:aws:s3:::kept", "AVD-AWS-0088"),
+			},
+		},
+		{
+			Target: "arn:aws:s3:::deleted",
+			Misconfigurations: []types.DetectedMisconfiguration{
+				failure("arn:aws:s3:::deleted", "AVD-AWS-0086"),
+			},
+		},
+		{
+			Target: "arn:aws:ec2:us-east-1:123456789012:instance/i-1",
+			Misconfigurations: []types.DetectedMisconfiguration{
+				{
+					AVDID:  "AVD-AWS-0028",
+					Status: types.MisconfStatusFailure,
+					CauseMetadata: ftypes.CauseMetadata{
+						Resource: "arn:aws:ec2:us-east-1:123456789012:instance/i-1",
+						Service:  "ec2",
+					},
+				},
+			},
+		},
+	}
+
+	passed := failure("arn:aws:s3:::kept", "AVD-AWS-0088")
+	passed.Status = types.MisconfStatusPassed
+
+	current := []types.Result{
+		{
+			Target: "arn:aws:s3:::kept",
+			Misconfigurations: []types.DetectedMisconfiguration{
+				failure("arn:aws:s3:::kept", "AVD-AWS-0086"),
+				passed,
+			},
+		},
+		{
+			Target: "arn:aws:s3:::new",
+			Misconfigurations: []types.DetectedMisconfiguration{
+				failure("arn:aws:s3:::new", "AVD-AWS-0086"),
+			},
+		},
+	}
+
+	diff := Compare(previous, current, []string{"s3"}, []string{"arn:aws:s3:::kept", "arn:aws:s3:::new"})
+
+	// ec2 is not in scope, so its failures are not reported as resolved
+	assert.Equal(t, map[string]*ServiceDiff{
+		"s3": {
+			Added: []Finding{
+				{Resource: "arn:aws:s3:::new", AVDID: "AVD-AWS-0086", Severity: "HIGH"},
+			},
+			Resolved: []Finding{
+				{Resource: "arn:aws:s3:::deleted", AVDID: "AVD-AWS-0086", Severity: "HIGH"},
+				{Resource: "arn:aws:s3:::kept", AVDID: "AVD-AWS-0088", Severity: "HIGH"},
+			},
+			Unchanged: []Finding{
+				{Resource: "arn:aws:s3:::kept", AVDID: "AVD-AWS-0086", Severity: "HIGH"},
+			},
+			RemovedResources: []string{"arn:aws:s3:::deleted"},
+		},
+	}, diff.Services)
+	assert.True(t, diff.HasAdded())
+}
+
+func Test_WriteDiff(t *testing.T) {
+	failure := func(avdID string) types.DetectedMisconfiguration {
+		return types.DetectedMisconfiguration{
+			AVDID:    avdID,
+			Severity: "HIGH",
+			Status:   types.MisconfStatusFailure,
+			CauseMetadata: ftypes.CauseMetadata{
+				Resource: "arn:aws:s3:::examplebucket",
+				Service:  "s3",
+			},
+		}
+	}
+
+	previous := []types.Result{
+		{
+			Target: "arn:aws:s3:::examplebucket",
+			Misconfigurations: []types.DetectedMisconfiguration{
+				failure("AVD-AWS-0086"),
+				failure("AVD-AWS-0088"),
+				failure("AVD-AWS-0089"),
+			},
+		},
+	}
+
+	// AVD-AWS-0088 was fixed, while AVD-AWS-0086 is ignored in both reports
+	rep := New("AWS", "123456789012", "us-east-1", nil, []string{"s3"})
+	rep.Results["s3"] = ResultsAtTime{
+		Results: types.Results{
+			{
+				Target: "arn:aws:s3:::examplebucket",
+				Misconfigurations: []types.DetectedMisconfiguration{
+					failure("AVD-AWS-0086"),
+					failure("AVD-AWS-0089"),
+				},
+			},
+		},
+	}
+	rep.Resources = []string{"arn:aws:s3:::examplebucket"}
+
+	dir := t.TempDir()
+	ignoreFile := filepath.Join(dir, ".trivyignore")
+	require.NoError(t, os.WriteFile(ignoreFile, []byte("AVD-AWS-0086\n"), 0600))
+
+	var opt flag.Options
+	opt.Format = "json"
+	opt.Output = filepath.Join(dir, "diff.json")
+	opt.IgnoreFile = ignoreFile
+	opt.Severities = []dbTypes.Severity{dbTypes.SeverityHigh}
+
+	diff, err := WriteDiff(context.Background(), rep, opt, previous)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]*ServiceDiff{
+		"s3": {
+			Resolved: []Finding{
+				{Resource: "arn:aws:s3:::examplebucket", AVDID: "AVD-AWS-0088", Severity: "HIGH"},
+			},
+			Unchanged: []Finding{
+				{Resource: "arn:aws:s3:::examplebucket", AVDID: "AVD-AWS-0089", Severity: "HIGH"},
+			},
+		},
+	}, diff.Services)
+}
@@ -0,0 +1,68 @@
+package report
+
+import (
+	"io"
+	"slices"
+	"strconv"
+
+	"github.com/aquasecurity/table"
+	"github.com/aquasecurity/tml"
+	"github.com/aquasecurity/trivy-aws/pkg/errs"
+)
+
+// AddGaps records the errors of the services in scope which left resources not fully checked
+func (r *Report) AddGaps(scanErrors []errs.ScanError) {
+	for _, scanErr := range scanErrors {
+		if !slices.Contains(r.ServicesInScope, scanErr.Service) {
+			continue
+		}
+		r.Gaps = append(r.Gaps, scanErr)
+	}
+	errs.SortScanErrors(r.Gaps)
+}
+
+// Incomplete returns whether any resources were not fully checked
+func (r *Report) Incomplete() bool {
+	return len(r.Gaps) > 0
+}
+
+// gaps returns the scan gaps of the service, or of every service if none is given
+func (r *Report) gaps(service string) []errs.ScanError {
+	if service == "" {
+		return r.Gaps
+	}
+	var gaps []errs.ScanError
+	for _, scanErr := range r.Gaps {
+		if scanErr.Service == service {
+			gaps = append(gaps, scanErr)
+		}
+	}
+	return gaps
+}
+
+func writeGaps(report *Report, output io.Writer, service string) {
+	gaps := report.gaps(service)
+	if len(gaps) == 0 {
+		return
+	}
+
+	_ = tml.Fprintf(output, "\n<yellow><bold>Scan Gaps for %s Account %s</bold></yellow>\n", report.Provider, report.AccountID)
+	_ = tml.Fprintf(output, "<yellow>The following errors left resources not fully checked. Run 'trivy aws preflight' to find missing permissions.</yellow>\n")
+
+	t := table.New(output)
+	t.SetHeaders("Service", "Region", "Resource", "Operation", "Error", "Count")
+	t.SetRowLines(false)
+	t.SetAlignment(table.AlignLeft, table.AlignLeft, table.AlignLeft, table.AlignLeft, table.AlignLeft, table.AlignRight)
+	for _, scanErr := range gaps {
+		resource := scanErr.Resource
+		if resource == "" {
+			resource = "-"
+		}
+		operation := scanErr.Operation
+		if operation == "" {
+			operation = "-"
+		}
+		t.AddRow(scanErr.Service, scanErr.Region, resource, operation, string(scanErr.Class), strconv.Itoa(scanErr.Count))
+	}
+	t.Render()
+}
@@ -0,0 +1,57 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/tml"
+	"github.com/aquasecurity/trivy-aws/pkg/errs"
+)
+
+func Test_AddGaps(t *testing.T) {
+	rep := New("AWS", "1234567890", "us-east-1", nil, []string{"s3"})
+
+	gap := errs.ScanError{Service: "s3", Region: "us-east-1", Resource: "bucket", Operation: "GetBucketLogging", Class: errs.ClassAccessDenied, Count: 2}
+	rep.AddGaps([]errs.ScanError{
+		gap,
+		// errors of services which are out of scope are not reported
+		{Service: "iam", Region: "us-east-1", Operation: "GetAccountPasswordPolicy", Class: errs.ClassAccessDenied, Count: 1},
+	})
+
+	assert.Equal(t, []errs.ScanError{gap}, rep.Gaps)
+	assert.True(t, rep.Incomplete())
+	assert.Empty(t, rep.Results["s3"].Results)
+	assert.False(t, rep.Failed())
+}
+
+func Test_ResourceReportWithGaps(t *testing.T) {
+	tml.DisableFormatting()
+
+	rep := New("AWS", "1234567890", "us-east-1", nil, []string{"s3"})
+	rep.AddGaps([]errs.ScanError{
+		{Service: "s3", Region: "us-east-1", Operation: "GetBucketLogging", Class: errs.ClassAccessDenied, Count: 2},
+		{Service: "s3", Region: "us-east-1", Resource: "bucket", Class: errs.ClassOther, Count: 1},
+	})
+
+	var buf bytes.Buffer
+	require.NoError(t, writeResourceTable(rep, nil, &buf, "s3"))
+	writeGaps(rep, &buf, "s3")
+
+	assert.Equal(t, `
+Resource Summary for Service 's3' (AWS Account 1234567890)
+
+No problems detected in the resources which could be checked.
+
+Scan Gaps for AWS Account 1234567890
+The following errors left resources not fully checked. Run 'trivy aws preflight' to find missing permissions.
+┌─────────┬───────────┬──────────┬──────────────────┬──────────────┬───────┐
+│ Service │  Region   │ Resource │    Operation     │    Error     │ Count │
+├─────────┼───────────┼──────────┼──────────────────┼──────────────┼───────┤
+│ s3      │ us-east-1 │ -        │ GetBucketLogging │ AccessDenied │     2 │
+│ s3      │ us-east-1 │ bucket   │ -                │ Other        │     1 │
+└─────────┴───────────┴──────────┴──────────────────┴──────────────┴───────┘
+`, buf.String())
+}
@@ -0,0 +1,60 @@
+package report
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// LastScanned is the time the state of a service was adapted from AWS, either by this scan or by a
+// previous one whose state was loaded from the cache
+type LastScanned struct {
+	Service     string
+	Region      string `json:",omitempty"`
+	LastScanned time.Time
+}
+
+// AddLastScanned records the time each service of the report was adapted from AWS, keyed by service
+func (r *Report) AddLastScanned(updated map[string]time.Time) {
+	for service, resultsAtTime := range r.Results {
+		scanned, ok := updated[service]
+		if !ok {
+			continue
+		}
+		resultsAtTime.CreationTime = scanned
+		r.Results[service] = resultsAtTime
+		r.LastScanned = append(r.LastScanned, LastScanned{
+			Service:     service,
+			Region:      r.Region,
+			LastScanned: scanned,
+		})
+	}
+	sortLastScanned(r.LastScanned)
+}
+
+func sortLastScanned(lastScanned []LastScanned) {
+	slices.SortFunc(lastScanned, func(a, b LastScanned) int {
+		if a.Service != b.Service {
+			return strings.Compare(a.Service, b.Service)
+		}
+		return strings.Compare(a.Region, b.Region)
+	})
+}
+
+func formatLastScanned(scanned time.Time) string {
+	if scanned.IsZero() {
+		return "unknown"
+	}
+	scanAgo := time.Since(scanned).Truncate(time.Minute)
+	switch {
+	case scanAgo.Hours() >= 48:
+		return fmt.Sprintf("%d days ago", int(scanAgo.Hours()/24))
+	case scanAgo.Hours() > 1:
+		return fmt.Sprintf("%d hours ago", int(scanAgo.Hours()))
+	case scanAgo.Minutes() > 1:
+		return fmt.Sprintf("%d minutes ago", int(scanAgo.Minutes()))
+	default:
+		return "just now"
+	}
+}
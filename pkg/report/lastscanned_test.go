@@ -0,0 +1,51 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AddLastScanned(t *testing.T) {
+	rep := New("AWS", "1234567890", "us-east-1", nil, []string{"s3", "ec2"})
+
+	scanned := time.Date(2021, 8, 24, 12, 0, 0, 0, time.UTC)
+	rep.AddLastScanned(map[string]time.Time{
+		"s3": scanned,
+		// services which are not part of the report are ignored
+		"iam": scanned,
+	})
+
+	assert.Equal(t, scanned, rep.Results["s3"].CreationTime)
+	assert.True(t, rep.Results["ec2"].CreationTime.IsZero())
+	assert.NotContains(t, rep.Results, "iam")
+	assert.Empty(t, rep.Results["s3"].Results)
+
+	assert.Equal(t, []LastScanned{
+		{
+			Service:     "s3",
+			Region:      "us-east-1",
+			LastScanned: scanned,
+		},
+	}, rep.LastScanned)
+}
+
+func Test_formatLastScanned(t *testing.T) {
+	tests := []struct {
+		name    string
+		scanned time.Time
+		want    string
+	}{
+		{name: "unknown", want: "unknown"},
+		{name: "just now", scanned: time.Now(), want: "just now"},
+		{name: "minutes", scanned: time.Now().Add(-5*time.Minute - time.Second), want: "5 minutes ago"},
+		{name: "hours", scanned: time.Now().Add(-23*time.Hour - time.Minute), want: "23 hours ago"},
+		{name: "days", scanned: time.Now().Add(-72*time.Hour - time.Minute), want: "3 days ago"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, formatLastScanned(tt.scanned))
+		})
+	}
+}
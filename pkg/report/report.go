@@ -2,16 +2,23 @@ package report
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"io"
 	"os"
+	"slices"
 	"sort"
+	"strings"
 	"time"
 
 	"golang.org/x/xerrors"
 
 	"github.com/aquasecurity/tml"
+	"github.com/aquasecurity/trivy-aws/pkg/errs"
+	"github.com/aquasecurity/trivy-aws/pkg/stats"
 	"github.com/aquasecurity/trivy/pkg/clock"
 	cr "github.com/aquasecurity/trivy/pkg/compliance/report"
+	"github.com/aquasecurity/trivy/pkg/extension"
 	ftypes "github.com/aquasecurity/trivy/pkg/fanal/types"
 	"github.com/aquasecurity/trivy/pkg/flag"
 	"github.com/aquasecurity/trivy/pkg/iac/scan"
@@ -22,6 +29,7 @@ import (
 
 const (
 	tableFormat = "table"
+	jsonFormat  = "json"
 )
 
 // Report represents an AWS scan report
@@ -29,12 +37,41 @@ type Report struct {
 	Provider        string
 	AccountID       string
 	Region          string
+	Regions         []string
 	Results         map[string]ResultsAtTime
 	ServicesInScope []string
+
+	// Resources holds the ARNs of all evaluated resources, including those without failures
+	Resources []string
+
+	// Gaps holds the errors which left resources not fully checked, so that a clean report can be trusted
+	Gaps []errs.ScanError
+
+	// Stats holds the statistics of the services adapted by the scan, rather than loaded from the cache
+	Stats []stats.Service
+
+	// LastScanned holds the time each service was adapted from AWS
+	LastScanned []LastScanned
+
+	// Tags holds the tags of the resources of the results, keyed by ARN
+	Tags map[string]map[string]string
+
+	// Accounts holds the per-account reports of a report combining several accounts
+	Accounts []*Report
+}
+
+// jsonReport is the JSON output of a report, which adds the details of the scan to the report of trivy
+type jsonReport struct {
+	types.Report
+	Gaps        []errs.ScanError             `json:",omitempty"`
+	Stats       []stats.Service              `json:",omitempty"`
+	LastScanned []LastScanned                `json:",omitempty"`
+	Tags        map[string]map[string]string `json:",omitempty"`
 }
 
 type ResultsAtTime struct {
-	Results      types.Results
+	Results types.Results
+	// CreationTime is the time the state of the service was adapted from AWS, which is zero if unknown
 	CreationTime time.Time
 }
 
@@ -45,7 +82,98 @@ func New(provider, accountID, region string, defsecResults scan.Results, scopedS
 		Results:         ConvertResults(defsecResults, provider, scopedServices),
 		ServicesInScope: scopedServices,
 		Region:          region,
+		Regions:         []string{region},
+	}
+}
+
+// AddResources records the resources of the results, which are all the resources evaluated by the scan
+func (r *Report) AddResources(results scan.Results) {
+	for _, res := range results {
+		r.Resources = append(r.Resources, res.Flatten().Resource)
+	}
+	slices.Sort(r.Resources)
+	r.Resources = slices.Compact(r.Resources)
+}
+
+// Merge combines reports of the same account produced for different regions into a single report
+func Merge(reports ...*Report) *Report {
+	if len(reports) == 1 {
+		return reports[0]
+	}
+
+	merged := &Report{
+		Results: make(map[string]ResultsAtTime),
 	}
+
+	for _, rep := range reports {
+		merged.Provider = rep.Provider
+		merged.AccountID = rep.AccountID
+		for _, region := range rep.Regions {
+			if !slices.Contains(merged.Regions, region) {
+				merged.Regions = append(merged.Regions, region)
+			}
+		}
+
+		for service, resultsAtTime := range rep.Results {
+			existing, ok := merged.Results[service]
+			if !ok {
+				merged.Results[service] = resultsAtTime
+				continue
+			}
+			existing.Results = append(existing.Results, resultsAtTime.Results...)
+			// report the oldest scan time so stale results are not hidden
+			if resultsAtTime.CreationTime.Before(existing.CreationTime) {
+				existing.CreationTime = resultsAtTime.CreationTime
+			}
+			merged.Results[service] = existing
+		}
+
+		for _, service := range rep.ServicesInScope {
+			if !slices.Contains(merged.ServicesInScope, service) {
+				merged.ServicesInScope = append(merged.ServicesInScope, service)
+			}
+		}
+
+		merged.Resources = append(merged.Resources, rep.Resources...)
+		merged.Gaps = append(merged.Gaps, rep.Gaps...)
+		merged.Stats = append(merged.Stats, rep.Stats...)
+		merged.LastScanned = append(merged.LastScanned, rep.LastScanned...)
+		for arn, tags := range rep.Tags {
+			if merged.Tags == nil {
+				merged.Tags = make(map[string]map[string]string)
+			}
+			merged.Tags[arn] = tags
+		}
+	}
+
+	sort.Strings(merged.ServicesInScope)
+	slices.Sort(merged.Resources)
+	merged.Resources = slices.Compact(merged.Resources)
+	errs.SortScanErrors(merged.Gaps)
+	stats.Sort(merged.Stats)
+	sortLastScanned(merged.LastScanned)
+	merged.Region = strings.Join(merged.Regions, ",")
+
+	return merged
+}
+
+// Combine combines reports produced for different accounts into a single report.
+// The per-account reports are kept so that the output can be broken down by account.
+func Combine(reports ...*Report) *Report {
+	if len(reports) == 1 {
+		return reports[0]
+	}
+
+	combined := Merge(reports...)
+
+	var accountIDs []string
+	for _, rep := range reports {
+		accountIDs = append(accountIDs, rep.AccountID)
+	}
+	combined.AccountID = strings.Join(accountIDs, ",")
+	combined.Accounts = reports
+
+	return combined
 }
 
 // Failed returns whether the aws report includes any "failed" results
@@ -58,8 +186,9 @@ func (r *Report) Failed() bool {
 	return false
 }
 
-// Write writes the results in the give format
-func Write(ctx context.Context, rep *Report, opt flag.Options, fromCache bool) error {
+// Write writes the results in the give format, followed by the statistics of the scan if showStats is set
+// and the format is a table
+func Write(ctx context.Context, rep *Report, opt flag.Options, fromCache, showStats bool) error {
 	output, cleanup, err := opt.OutputWriter(ctx)
 	if err != nil {
 		return xerrors.Errorf("failed to create output file: %w", err)
@@ -116,14 +245,37 @@ func Write(ctx context.Context, rep *Report, opt flag.Options, fromCache bool) e
 			if err := writeResourceTable(rep, filtered, output, opt.Services[0]); err != nil {
 				return err
 			}
+			writeGaps(rep, output, opt.Services[0])
+			if showStats {
+				writeStats(rep, output)
+			}
 		case len(opt.Services) == 1 && opt.ARN != "":
 			if err := writeResultsForARN(rep, filtered, output, opt.Services[0], opt.ARN, opt.Severities); err != nil {
 				return err
 			}
+			writeGaps(rep, output, opt.Services[0])
+		case len(rep.Accounts) > 1:
+			for _, accountReport := range rep.Accounts {
+				accountResults, err := filterResults(ctx, accountReport, opt, ignoreConf, nil)
+				if err != nil {
+					return err
+				}
+				if err := writeServiceTable(accountReport, combineResults(accountResults), output); err != nil {
+					return err
+				}
+				writeGaps(accountReport, output, "")
+				if showStats {
+					writeStats(accountReport, output)
+				}
+			}
 		default:
 			if err := writeServiceTable(rep, filtered, output); err != nil {
 				return err
 			}
+			writeGaps(rep, output, "")
+			if showStats {
+				writeStats(rep, output)
+			}
 		}
 
 		// render cache info
@@ -132,27 +284,77 @@ func Write(ctx context.Context, rep *Report, opt flag.Options, fromCache bool) e
 		}
 
 		return nil
+	case jsonFormat:
+		return writeJSON(ctx, rep, base, opt, output)
 	default:
 		return pkgReport.Write(ctx, base, opt)
 	}
 }
 
+// writeJSON writes the report of trivy as its JSON writer does, along with the details of the scan
+func writeJSON(ctx context.Context, rep *Report, base types.Report, opt flag.Options, output io.Writer) error {
+	if err := extension.PreReport(ctx, &base, opt); err != nil {
+		return xerrors.Errorf("pre report error: %w", err)
+	}
+
+	for i := range base.Results {
+		if !opt.ListAllPkgs {
+			base.Results[i].Packages = nil
+		}
+		if !opt.ShowSuppressed {
+			base.Results[i].ModifiedFindings = nil
+		}
+	}
+	base.Results = slices.DeleteFunc(base.Results, func(r types.Result) bool {
+		return r.Target == "" && r.IsEmpty()
+	})
+
+	encoded, err := json.MarshalIndent(jsonReport{
+		Report:      base,
+		Gaps:        rep.Gaps,
+		Stats:       rep.Stats,
+		LastScanned: rep.LastScanned,
+		Tags:        rep.Tags,
+	}, "", "  ")
+	if err != nil {
+		return xerrors.Errorf("failed to marshal json: %w", err)
+	}
+	if _, err := fmt.Fprintln(output, string(encoded)); err != nil {
+		return xerrors.Errorf("failed to write json: %w", err)
+	}
+
+	if err := extension.PostReport(ctx, &base, opt); err != nil {
+		return xerrors.Errorf("post report error: %w", err)
+	}
+	return nil
+}
+
 func filterResults(ctx context.Context, rep *Report, opt flag.Options, ignoreConf result.IgnoreConfig, filtered []types.Result) ([]types.Result, error) {
 	for _, resultsAtTime := range rep.Results {
-		for _, res := range resultsAtTime.Results {
-			resCopy := res
-			if err := result.FilterResult(ctx, &resCopy, ignoreConf, opt.FilterOpts()); err != nil {
-				return nil, err
-			}
-			sort.Slice(resCopy.Misconfigurations, func(i, j int) bool {
-				return resCopy.Misconfigurations[i].CauseMetadata.Resource < resCopy.Misconfigurations[j].CauseMetadata.Resource
-			})
-			filtered = append(filtered, resCopy)
+		var err error
+		if filtered, err = filterResultList(ctx, resultsAtTime.Results, opt, ignoreConf, filtered); err != nil {
+			return nil, err
 		}
 	}
 	return filtered, nil
 }
 
+// filterResultList appends the results to filtered, leaving out the findings which are ignored or below
+// the requested severity
+func filterResultList(ctx context.Context, results []types.Result, opt flag.Options, ignoreConf result.IgnoreConfig, filtered []types.Result) ([]types.Result, error) {
+	for _, res := range results {
+		resCopy := res
+		if err := result.FilterResult(ctx, &resCopy, ignoreConf, opt.FilterOpts()); err != nil {
+			return nil, err
+		}
+		sort.Slice(resCopy.Misconfigurations, func(i, j int) bool {
+			return resCopy.Misconfigurations[i].CauseMetadata.Resource < resCopy.Misconfigurations[j].CauseMetadata.Resource
+		})
+		filtered = append(filtered, resCopy)
+	}
+	return filtered, nil
+}
+
 func combineResults(results []types.Result) []types.Result {
 	var noTarget types.Result
 	var withTarget []types.Result
@@ -0,0 +1,138 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/trivy-aws/pkg/errs"
+	"github.com/aquasecurity/trivy-aws/pkg/stats"
+	"github.com/aquasecurity/trivy/pkg/flag"
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+func Test_Merge(t *testing.T) {
+	older := time.Date(2021, 8, 24, 12, 0, 0, 0, time.UTC)
+	newer := time.Date(2021, 8, 25, 12, 0, 0, 0, time.UTC)
+
+	east := &Report{
+		Provider:  "AWS",
+		AccountID: "1234567890",
+		Region:    "us-east-1",
+		Regions:   []string{"us-east-1"},
+		Results: map[string]ResultsAtTime{
+			"ec2": {
+				Results:      types.Results{{Target: "arn:aws:ec2:us-east-1:1234567890:instance1"}},
+				CreationTime: newer,
+			},
+			"iam": {
+				Results:      types.Results{{Target: "arn:aws:iam::1234567890:user/admin"}},
+				CreationTime: newer,
+			},
+		},
+		ServicesInScope: []string{"ec2", "iam"},
+		LastScanned: []LastScanned{
+			{Service: "iam", Region: "us-east-1", LastScanned: newer},
+			{Service: "ec2", Region: "us-east-1", LastScanned: newer},
+		},
+		Tags: map[string]map[string]string{
+			"arn:aws:ec2:us-east-1:1234567890:instance1": {"team": "payments"},
+		},
+	}
+
+	west := &Report{
+		Provider:  "AWS",
+		AccountID: "1234567890",
+		Region:    "us-west-2",
+		Regions:   []string{"us-west-2"},
+		Results: map[string]ResultsAtTime{
+			"ec2": {
+				Results:      types.Results{{Target: "arn:aws:ec2:us-west-2:1234567890:instance2"}},
+				CreationTime: older,
+			},
+		},
+		ServicesInScope: []string{"ec2"},
+		LastScanned: []LastScanned{
+			{Service: "ec2", Region: "us-west-2", LastScanned: older},
+		},
+	}
+
+	merged := Merge(east, west)
+	require.NotNil(t, merged)
+
+	assert.Equal(t, "AWS", merged.Provider)
+	assert.Equal(t, "1234567890", merged.AccountID)
+	assert.Equal(t, []string{"us-east-1", "us-west-2"}, merged.Regions)
+	assert.Equal(t, "us-east-1,us-west-2", merged.Region)
+	assert.Equal(t, []string{"ec2", "iam"}, merged.ServicesInScope)
+
+	require.Len(t, merged.Results, 2)
+	assert.Equal(t, types.Results{
+		{Target: "arn:aws:ec2:us-east-1:1234567890:instance1"},
+		{Target: "arn:aws:ec2:us-west-2:1234567890:instance2"},
+	}, merged.Results["ec2"].Results)
+	assert.Equal(t, older, merged.Results["ec2"].CreationTime)
+	assert.Equal(t, newer, merged.Results["iam"].CreationTime)
+	assert.Equal(t, []LastScanned{
+		{Service: "ec2", Region: "us-east-1", LastScanned: newer},
+		{Service: "ec2", Region: "us-west-2", LastScanned: older},
+		{Service: "iam", Region: "us-east-1", LastScanned: newer},
+	}, merged.LastScanned)
+	assert.Equal(t, map[string]map[string]string{
+		"arn:aws:ec2:us-east-1:1234567890:instance1": {"team": "payments"},
+	}, merged.Tags)
+
+	assert.Same(t, east, Merge(east))
+}
+
+func Test_Combine(t *testing.T) {
+	first := New("AWS", "1111111111", "us-east-1", createTestResults(), []string{"ec2", "s3"})
+	second := New("AWS", "2222222222", "us-east-1", nil, []string{"ec2", "s3"})
+
+	combined := Combine(first, second)
+	require.NotNil(t, combined)
+
+	assert.Equal(t, "1111111111,2222222222", combined.AccountID)
+	assert.Equal(t, []string{"us-east-1"}, combined.Regions)
+	assert.Equal(t, []*Report{first, second}, combined.Accounts)
+	assert.True(t, combined.Failed())
+
+	assert.Same(t, first, Combine(first))
+}
+
+func Test_writeJSON(t *testing.T) {
+	scanned := time.Date(2021, 8, 24, 12, 0, 0, 0, time.UTC)
+
+	rep := New("AWS", "1234567890", "us-east-1", nil, []string{"s3"})
+	rep.Results["s3"] = ResultsAtTime{
+		Results: types.Results{{Target: "arn:aws:s3:::payments"}},
+	}
+	rep.AddGaps([]errs.ScanError{
+		{Service: "s3", Region: "us-east-1", Operation: "GetBucketLogging", Class: errs.ClassAccessDenied, Count: 1},
+	})
+	rep.AddStats([]stats.Service{{Service: "s3", Region: "us-east-1", Discovered: 1, Processed: 1, Adapted: 1}})
+	rep.AddLastScanned(map[string]time.Time{"s3": scanned})
+	rep.AddTags(map[string]map[string]string{"arn:aws:s3:::payments": {"team": "payments"}})
+
+	var buf bytes.Buffer
+	require.NoError(t, writeJSON(context.Background(), rep, types.Report{
+		ArtifactName: rep.AccountID,
+		Results:      rep.Results["s3"].Results,
+	}, flag.Options{}, &buf))
+
+	var got map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+
+	// the details of the scan are written once, rather than as results of their own
+	assert.JSONEq(t, `[{"Target": "arn:aws:s3:::payments"}]`, string(got["Results"]))
+	assert.JSONEq(t, `[{"Service": "s3", "Region": "us-east-1", "Operation": "GetBucketLogging", "Class": "AccessDenied", "Count": 1}]`, string(got["Gaps"]))
+	assert.JSONEq(t, `[{"Service": "s3", "Region": "us-east-1", "Duration": 0, "ResourcesDiscovered": 1, "ResourcesProcessed": 1, "ResourcesAdapted": 1, "Retries": 0, "Throttles": 0}]`, string(got["Stats"]))
+	assert.JSONEq(t, `[{"Service": "s3", "Region": "us-east-1", "LastScanned": "2021-08-24T12:00:00Z"}]`, string(got["LastScanned"]))
+	assert.JSONEq(t, `{"arn:aws:s3:::payments": {"team": "payments"}}`, string(got["Tags"]))
+	assert.JSONEq(t, `"1234567890"`, string(got["ArtifactName"]))
+}
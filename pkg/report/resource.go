@@ -80,6 +80,8 @@ func writeResourceTable(report *Report, results types.Results, output io.Writer,
 	// render table
 	if len(sortable) > 0 {
 		t.Render()
+	} else if len(report.gaps(service)) > 0 {
+		_, _ = fmt.Fprint(output, "\nNo problems detected in the resources which could be checked.\n")
 	} else {
 		_, _ = fmt.Fprint(output, "\nNo problems detected.\n")
 	}
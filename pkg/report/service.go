@@ -1,11 +1,10 @@
 package report
 
 import (
-	"fmt"
 	"io"
 	"sort"
 	"strconv"
-	"time"
+	"strings"
 
 	"github.com/aquasecurity/table"
 	"github.com/aquasecurity/tml"
@@ -51,19 +50,6 @@ func writeServiceTable(report *Report, results types.Results, output io.Writer)
 	}
 	sort.Slice(sortable, func(i, j int) bool { return sortable[i].name < sortable[j].name })
 	for _, row := range sortable {
-		var lastScanned string
-		scanAgo := time.Since(report.Results[row.name].CreationTime).Truncate(time.Minute)
-		switch {
-		case scanAgo.Hours() >= 48:
-			lastScanned = fmt.Sprintf("%d days ago", int(scanAgo.Hours()/24))
-		case scanAgo.Hours() > 1:
-			lastScanned = fmt.Sprintf("%d hours ago", int(scanAgo.Hours()))
-		case scanAgo.Minutes() > 1:
-			lastScanned = fmt.Sprintf("%d minutes ago", int(scanAgo.Minutes()))
-		default:
-			lastScanned = "just now"
-		}
-
 		t.AddRow(
 			row.name,
 			pkgReport.ColorizeSeverity(strconv.Itoa(row.counts["CRITICAL"]), "CRITICAL"),
@@ -71,12 +57,16 @@ func writeServiceTable(report *Report, results types.Results, output io.Writer)
 			pkgReport.ColorizeSeverity(strconv.Itoa(row.counts["MEDIUM"]), "MEDIUM"),
 			pkgReport.ColorizeSeverity(strconv.Itoa(row.counts["LOW"]), "LOW"),
 			pkgReport.ColorizeSeverity(strconv.Itoa(row.counts["UNKNOWN"]), "UNKNOWN"),
-			lastScanned,
+			formatLastScanned(report.Results[row.name].CreationTime),
 		)
 	}
 
 	// render scan title
-	_ = tml.Fprintf(output, "\n<bold>Scan Overview for %s Account %s</bold>\n", report.Provider, report.AccountID)
+	if len(report.Regions) > 1 {
+		_ = tml.Fprintf(output, "\n<bold>Scan Overview for %s Account %s (Regions: %s)</bold>\n", report.Provider, report.AccountID, strings.Join(report.Regions, ", "))
+	} else {
+		_ = tml.Fprintf(output, "\n<bold>Scan Overview for %s Account %s</bold>\n", report.Provider, report.AccountID)
+	}
 
 	// render table
 	t.Render()
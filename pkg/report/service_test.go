@@ -19,10 +19,11 @@ import (
 
 func Test_ServiceReport(t *testing.T) {
 	tests := []struct {
-		name      string
-		options   flag.Options
-		fromCache bool
-		expected  string
+		name        string
+		options     flag.Options
+		fromCache   bool
+		lastScanned time.Time
+		expected    string
 	}{
 		{
 			name: "simple table output",
@@ -37,7 +38,8 @@ func Test_ServiceReport(t *testing.T) {
 					},
 				},
 			},
-			fromCache: false,
+			fromCache:   false,
+			lastScanned: time.Now(),
 			expected: `
 Scan Overview for AWS Account 
 ┌─────────┬──────────────────────────────────────────────────┬──────────────┐
@@ -63,7 +65,8 @@ Scan Overview for AWS Account
 					},
 				},
 			},
-			fromCache: true,
+			fromCache:   true,
+			lastScanned: time.Now().Add(-23 * time.Hour),
 			expected: `
 Scan Overview for AWS Account 
 ┌─────────┬──────────────────────────────────────────────────┬──────────────┐
@@ -71,8 +74,8 @@ Scan Overview for AWS Account
 │         ├──────────┬──────────────┬────────┬─────┬─────────┤              │
 │ Service │ Critical │     High     │ Medium │ Low │ Unknown │ Last Scanned │
 ├─────────┼──────────┼──────────────┼────────┼─────┼─────────┼──────────────┤
-│ ec2     │        0 │            1 │      0 │   0 │       0 │ just now     │
-│ s3      │        0 │            3 │      0 │   0 │       0 │ just now     │
+│ ec2     │        0 │            1 │      0 │   0 │       0 │ 23 hours ago │
+│ s3      │        0 │            3 │      0 │   0 │       0 │ 23 hours ago │
 └─────────┴──────────┴──────────────┴────────┴─────┴─────────┴──────────────┘
 
 This scan report was loaded from cached results. If you'd like to run a fresh scan, use --update-cache.
@@ -94,7 +97,8 @@ This scan report was loaded from cached results. If you'd like to run a fresh sc
 					},
 				},
 			},
-			fromCache: false,
+			fromCache:   false,
+			lastScanned: time.Now(),
 			expected: `
 Scan Overview for AWS Account 
 ┌─────────┬──────────────────────────────────────────────────┬──────────────┐
@@ -127,7 +131,8 @@ Scan Overview for AWS Account
 					},
 				},
 			},
-			fromCache: false,
+			fromCache:   false,
+			lastScanned: time.Now(),
 			expected: `
 Scan Overview for AWS Account 
 ┌─────────┬──────────────────────────────────────────────────┬──────────────┐
@@ -327,10 +332,17 @@ Scan Overview for AWS Account
 				createTestResults(),
 				tt.options.AWSOptions.Services,
 			)
+			if !tt.lastScanned.IsZero() {
+				updated := make(map[string]time.Time)
+				for service := range report.Results {
+					updated[service] = tt.lastScanned
+				}
+				report.AddLastScanned(updated)
+			}
 
 			output := bytes.NewBuffer(nil)
 			tt.options.SetOutputWriter(output)
-			require.NoError(t, Write(ctx, report, tt.options, tt.fromCache))
+			require.NoError(t, Write(ctx, report, tt.options, tt.fromCache, false))
 
 			assert.Equal(t, "AWS", report.Provider)
 			assert.Equal(t, tt.options.AWSOptions.Account, report.AccountID)
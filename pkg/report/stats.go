@@ -0,0 +1,52 @@
+package report
+
+import (
+	"io"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/aquasecurity/table"
+	"github.com/aquasecurity/tml"
+	"github.com/aquasecurity/trivy-aws/pkg/stats"
+)
+
+// AddStats records the statistics of the adapted services in scope
+func (r *Report) AddStats(serviceStats []stats.Service) {
+	for _, s := range serviceStats {
+		if !slices.Contains(r.ServicesInScope, s.Service) {
+			continue
+		}
+		r.Stats = append(r.Stats, s)
+	}
+	stats.Sort(r.Stats)
+}
+
+func writeStats(report *Report, output io.Writer) {
+	if len(report.Stats) == 0 {
+		_ = tml.Fprintf(output, "\n<blue>No statistics were collected as every service was loaded from the cache.</blue>\n")
+		return
+	}
+
+	_ = tml.Fprintf(output, "\n<bold>Scan Statistics for %s Account %s</bold>\n", report.Provider, report.AccountID)
+
+	t := table.New(output)
+	t.SetHeaders("Service", "Region", "Duration", "Discovered", "Processed", "Adapted", "API Calls", "Retries", "Throttles")
+	t.SetRowLines(false)
+	t.SetAlignment(table.AlignLeft, table.AlignLeft, table.AlignRight, table.AlignRight, table.AlignRight, table.AlignRight, table.AlignRight, table.AlignRight, table.AlignRight)
+	for _, s := range report.Stats {
+		duration := time.Duration(s.Duration * float64(time.Second)).Round(time.Millisecond)
+		t.AddRow(
+			s.Service,
+			s.Region,
+			duration.String(),
+			strconv.Itoa(s.Discovered),
+			strconv.Itoa(s.Processed),
+			strconv.Itoa(s.Adapted),
+			strconv.Itoa(s.TotalCalls()),
+			strconv.Itoa(s.Retries),
+			strconv.Itoa(s.Throttles),
+		)
+	}
+	t.Render()
+}
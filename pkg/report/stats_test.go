@@ -0,0 +1,66 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aquasecurity/tml"
+	"github.com/aquasecurity/trivy-aws/pkg/stats"
+)
+
+func Test_AddStats(t *testing.T) {
+	rep := New("AWS", "1234567890", "us-east-1", nil, []string{"s3"})
+
+	s3Stats := stats.Service{Service: "s3", Region: "us-east-1", Duration: 1.5, Discovered: 2, Processed: 2, Calls: map[string]int{"ListBuckets": 1}}
+	rep.AddStats([]stats.Service{
+		s3Stats,
+		// services which are out of scope are not reported
+		{Service: "iam", Region: "us-east-1"},
+	})
+
+	assert.Equal(t, []stats.Service{s3Stats}, rep.Stats)
+	assert.Empty(t, rep.Results["s3"].Results)
+}
+
+func Test_writeStats(t *testing.T) {
+	tml.DisableFormatting()
+
+	rep := New("AWS", "1234567890", "us-east-1", nil, []string{"ec2", "s3"})
+	rep.AddStats([]stats.Service{
+		{
+			Service:    "s3",
+			Region:     "us-east-1",
+			Duration:   1.5,
+			Discovered: 2,
+			Processed:  2,
+			Adapted:    2,
+			Calls:      map[string]int{"ListBuckets": 1, "GetBucketPolicy": 2},
+		},
+		{
+			Service:    "ec2",
+			Region:     "us-east-1",
+			Duration:   62.25,
+			Discovered: 40,
+			Processed:  40,
+			Adapted:    39,
+			Calls:      map[string]int{"DescribeInstances": 3},
+			Retries:    2,
+			Throttles:  2,
+		},
+	})
+
+	var buf bytes.Buffer
+	writeStats(rep, &buf)
+
+	assert.Equal(t, `
+Scan Statistics for AWS Account 1234567890
+┌─────────┬───────────┬──────────┬────────────┬───────────┬─────────┬───────────┬─────────┬───────────┐
+│ Service │  Region   │ Duration │ Discovered │ Processed │ Adapted │ API Calls │ Retries │ Throttles │
+├─────────┼───────────┼──────────┼────────────┼───────────┼─────────┼───────────┼─────────┼───────────┤
+│ ec2     │ us-east-1 │  1m2.25s │         40 │        40 │      39 │         3 │       2 │         2 │
+│ s3      │ us-east-1 │     1.5s │          2 │         2 │       2 │         3 │       0 │         0 │
+└─────────┴───────────┴──────────┴────────────┴───────────┴─────────┴───────────┴─────────┴───────────┘
+`, buf.String())
+}
@@ -0,0 +1,17 @@
+package report
+
+// AddTags records the tags of the resources of the report, keyed by ARN
+func (r *Report) AddTags(tags map[string]map[string]string) {
+	for _, resultsAtTime := range r.Results {
+		for _, result := range resultsAtTime.Results {
+			resourceTags, ok := tags[result.Target]
+			if !ok {
+				continue
+			}
+			if r.Tags == nil {
+				r.Tags = make(map[string]map[string]string)
+			}
+			r.Tags[result.Target] = resourceTags
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aquasecurity/trivy/pkg/types"
+)
+
+func Test_AddTags(t *testing.T) {
+	rep := &Report{
+		Results: map[string]ResultsAtTime{
+			"s3": {
+				Results: types.Results{
+					{Target: "arn:aws:s3:::payments"},
+					{Target: "arn:aws:s3:::untagged"},
+				},
+			},
+		},
+	}
+
+	rep.AddTags(map[string]map[string]string{
+		"arn:aws:s3:::payments": {"team": "payments"},
+		// resources without results are not reported
+		"arn:aws:s3:::other": {"team": "other"},
+	})
+
+	assert.Equal(t, map[string]map[string]string{
+		"arn:aws:s3:::payments": {"team": "payments"},
+	}, rep.Tags)
+	assert.Empty(t, rep.Results["s3"].Results[0].CustomResources)
+}
@@ -6,10 +6,18 @@ import (
 	"io/fs"
 	"os"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"golang.org/x/xerrors"
 
+	cloudOptions "github.com/aquasecurity/trivy-aws/internal/adapters/cloud/options"
+	"github.com/aquasecurity/trivy-aws/pkg/audit"
 	"github.com/aquasecurity/trivy-aws/pkg/cache"
+	"github.com/aquasecurity/trivy-aws/pkg/concurrency"
+	"github.com/aquasecurity/trivy-aws/pkg/errs"
 	"github.com/aquasecurity/trivy-aws/pkg/flag"
+	"github.com/aquasecurity/trivy-aws/pkg/progress"
+	"github.com/aquasecurity/trivy-aws/pkg/statefile"
+	"github.com/aquasecurity/trivy-aws/pkg/stats"
 	"github.com/aquasecurity/trivy/pkg/commands/operation"
 	"github.com/aquasecurity/trivy/pkg/iac/framework"
 	"github.com/aquasecurity/trivy/pkg/iac/rego"
@@ -22,34 +30,133 @@ import (
 )
 
 type AWSScanner struct {
+	credentials aws.CredentialsProvider
+	stateFile   *statefile.File
+	stats       []stats.Service
+	// rateLimiter limits the calls to the AWS APIs of the account, across the scans of its regions
+	rateLimiter *cloudOptions.APIRateLimiter
+}
+
+// Stats returns the statistics of the services adapted by the last scan, which is empty if
+// every service was loaded from the cache
+func (s *AWSScanner) Stats() []stats.Service {
+	return s.stats
 }
 
 func NewScanner() *AWSScanner {
 	return &AWSScanner{}
 }
 
-func (s *AWSScanner) Scan(ctx context.Context, option flag.Options) (scan.Results, bool, error) {
+// NewScannerWithCredentials creates a scanner which authenticates with the given credentials
+// instead of the default credential chain, e.g. to scan an account through an assumed role.
+func NewScannerWithCredentials(credentials aws.CredentialsProvider) *AWSScanner {
+	return &AWSScanner{
+		credentials: credentials,
+	}
+}
+
+// NewScannerWithStateFile creates a scanner which evaluates the checks against a previously
+// exported state rather than the live account, so no access to AWS is required.
+func NewScannerWithStateFile(f *statefile.File) *AWSScanner {
+	return &AWSScanner{
+		stateFile: f,
+	}
+}
+
+// Scan scans the region of the given options and returns the results along with the scanned state,
+// which carries the tags of the resources and the errors of the adaptation, and whether any of the services
+// were loaded from the cache.
+func (s *AWSScanner) Scan(ctx context.Context, option flag.Options) (scan.Results, *statefile.File, bool, error) {
+
+	includeTags, err := ParseTagFilters(option.IncludeTags)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	excludeTags, err := ParseTagFilters(option.ExcludeTags)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	f, cached := s.stateFile, false
+	if f == nil {
+		f, cached, err = s.LoadState(ctx, option)
+		if err != nil {
+			return nil, nil, false, err
+		}
+	}
+
+	if f.State == nil {
+		return nil, nil, false, fmt.Errorf("no resultant state found")
+	}
+
+	// the cache keeps every resource so that it can be reused with different tag filters
+	if err := filterResourcesByTags(f.State, f.Tags, includeTags, excludeTags); err != nil {
+		return nil, nil, false, err
+	}
+
+	regoOpts, err := regoOptions(option)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	defsecResults, err := New(regoOpts...).Scan(ctx, f.State)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	return defsecResults, f, cached, nil
+}
+
+// LoadState returns the state of the region of the given options, adapting the services which are
+// not cached, and whether any of the services were loaded from the cache.
+func (s *AWSScanner) LoadState(ctx context.Context, option flag.Options) (*statefile.File, bool, error) {
 
-	awsCache := cache.New(option.CacheDir, option.MaxCacheAge, option.Account, option.Region)
-	included, missing := awsCache.ListServices(option.Services)
+	s.stats = nil
+
+	backend, err := cache.NewBackend(ctx, option.CacheBackend, option.CacheDir, cache.S3Options{
+		Region:   option.CacheS3Region,
+		Endpoint: option.CacheS3Endpoint,
+		AuditLog: audit.FromContext(ctx),
+	})
+	if err != nil {
+		return nil, false, xerrors.Errorf("failed to create cache backend: %w", err)
+	}
+	awsCache := cache.New(backend, option.MaxCacheAge, option.Partition, option.Account, option.Region)
+	encryption, err := cache.LoadEncryption(option.CacheKeyFile)
+	if err != nil {
+		return nil, false, xerrors.Errorf("failed to load cache key: %w", err)
+	}
+	awsCache.SetEncryption(encryption)
+	included, missing := awsCache.ListServices(ctx, option.Services)
 
 	var scannerOpts []options.ScannerOption
 
 	noProgress := option.Quiet || option.NoProgress
-	if !noProgress {
+	switch {
+	case option.ProgressEvents != "":
+		w, closeEvents, err := openProgressEvents(option.ProgressEvents)
+		if err != nil {
+			return nil, false, err
+		}
+		defer closeEvents()
+		scannerOpts = append(scannerOpts, ScannerWithProgressTracker(progress.NewEventTracker(w, option.Account, option.Region)))
+	case !noProgress:
 		tracker := newProgressTracker(os.Stdout)
 		defer tracker.Finish()
 		scannerOpts = append(scannerOpts, ScannerWithProgressTracker(tracker))
 	}
 
-	if len(missing) > 0 {
+	switch {
+	case len(option.ARNs) > 0:
+		// only the requested resources are fetched, everything else comes from the cache
+		scannerOpts = append(scannerOpts,
+			ScannerWithAWSServices(option.Services...),
+			ScannerWithAWSARNs(option.ARNs...),
+		)
+	case len(missing) > 0:
 		scannerOpts = append(scannerOpts, ScannerWithAWSServices(missing...))
 	}
 
-	if option.Trace {
-		scannerOpts = append(scannerOpts, rego.WithPerResultTracing(true))
-	}
-
 	if option.Region != "" {
 		scannerOpts = append(
 			scannerOpts,
@@ -64,10 +171,148 @@ func (s *AWSScanner) Scan(ctx context.Context, option flag.Options) (scan.Result
 		)
 	}
 
+	if option.ServiceParallelism > 1 {
+		scannerOpts = append(scannerOpts, ScannerWithServiceParallelism(option.ServiceParallelism))
+	}
+
+	if option.Parallel > 0 {
+		scannerOpts = append(scannerOpts, ScannerWithConcurrencyStrategy(concurrency.FixedStrategy(option.Parallel)))
+	}
+
+	if len(option.ParallelLimits) > 0 {
+		strategies, err := ParseParallelLimits(option.ParallelLimits)
+		if err != nil {
+			return nil, false, err
+		}
+		scannerOpts = append(scannerOpts, ScannerWithServiceStrategies(strategies))
+	}
+
+	if option.APIRateLimit > 0 {
+		if s.rateLimiter == nil {
+			s.rateLimiter = cloudOptions.NewAPIRateLimiter(option.APIRateLimit)
+		}
+		scannerOpts = append(scannerOpts, ScannerWithAPIRateLimiter(s.rateLimiter))
+	}
+
+	if auditLog := audit.FromContext(ctx); auditLog != nil {
+		scannerOpts = append(scannerOpts, ScannerWithAuditLog(auditLog))
+	}
+
+	if option.ServiceTimeout > 0 {
+		scannerOpts = append(scannerOpts, ScannerWithServiceTimeout(option.ServiceTimeout))
+	}
+
+	if option.ResourceTimeout > 0 {
+		scannerOpts = append(scannerOpts, ScannerWithResourceTimeout(option.ResourceTimeout))
+	}
+
+	if s.credentials != nil {
+		scannerOpts = append(scannerOpts, ScannerWithAWSCredentials(s.credentials))
+	}
+
+	scanner := New(scannerOpts...)
+
+	var freshState *state.State
+	if len(option.ARNs) > 0 || len(missing) > 0 || option.CloudOptions.UpdateCache {
+		var err error
+		freshState, err = scanner.CreateState(ctx)
+		if err != nil {
+			return nil, false, err
+		}
+		s.stats = scanner.Stats()
+	}
+
+	var fullState *state.State
+	if len(option.ARNs) > 0 {
+		fullState = updateResources(ctx, freshState, awsCache, option.ARNs, scanner.NotFound())
+		// the services were not adapted in full, so their cache entries must not be refreshed
+		missing = nil
+	} else {
+		fullState, err = createState(ctx, freshState, awsCache)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	if fullState == nil {
+		return nil, false, fmt.Errorf("no resultant state found")
+	}
+
+	tags, err := loadTags(ctx, scanner, awsCache, freshState != nil)
+	if err != nil {
+		if len(option.IncludeTags) > 0 || len(option.ExcludeTags) > 0 {
+			return nil, false, xerrors.Errorf("unable to filter resources by tags: %w", err)
+		}
+		log.WarnContext(ctx, "Unable to get resource tags, falling back to the cached tags", log.Err(err))
+		tags, _ = loadTags(ctx, scanner, awsCache, false)
+	}
+
+	scanErrors, _ := awsCache.LoadScanErrors(ctx)
+	if freshState != nil {
+		scanErrors = errs.MergeScanErrors(scanErrors, scanner.ScanErrors(), refreshedServices(option, missing))
+	}
+
+	switch {
+	case len(option.ARNs) > 0:
+		// only some resources of the service were refreshed, so the service is not marked as updated
+		err = awsCache.UpdateServices(ctx, fullState, tags, scanErrors, option.Services)
+	case freshState != nil:
+		err = awsCache.AddServices(ctx, fullState, tags, scanErrors, refreshedServices(option, missing))
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	// the cache holds the time every service was adapted, whether by this scan or by a previous one
+	updated, _ := awsCache.LoadUpdated(ctx)
+
+	return &statefile.File{
+		AccountID:  option.Account,
+		Region:     option.Region,
+		Partition:  option.Partition,
+		Tags:       tags,
+		ScanErrors: scanErrors,
+		Updated:    updated,
+		State:      fullState,
+	}, len(included) > 0, nil
+}
+
+// refreshedServices returns the services which were adapted in full, whose previous errors no longer apply
+func refreshedServices(option flag.Options, missing []string) []string {
+	switch {
+	case len(option.ARNs) > 0:
+		return nil
+	case len(missing) > 0:
+		return missing
+	default:
+		return option.Services
+	}
+}
+
+// loadTags fetches the resource tags when the state was refreshed, and otherwise uses the cached tags
+func loadTags(ctx context.Context, scanner *Scanner, awsCache *cache.Cache, refreshed bool) (map[string]map[string]string, error) {
+	if refreshed {
+		return scanner.ResourceTags(ctx)
+	}
+	tags, err := awsCache.LoadTags(ctx)
+	if err != nil {
+		// no cache record, so there is nothing to be tagged either
+		return nil, nil
+	}
+	return tags, nil
+}
+
+// regoOptions returns the options to load the checks and data used to evaluate the state
+func regoOptions(option flag.Options) ([]options.ScannerOption, error) {
+	var scannerOpts []options.ScannerOption
+
+	if option.Trace {
+		scannerOpts = append(scannerOpts, rego.WithPerResultTracing(true))
+	}
+
 	var (
 		disableEmbedded bool
 		policyPaths     []string
-		err             error
 	)
 
 	c, _ := policy.NewClient(option.CacheDir, option.Quiet, option.MisconfOptions.ChecksBundleRepository)
@@ -90,7 +335,7 @@ func (s *AWSScanner) Scan(ctx context.Context, option flag.Options) (scan.Result
 	var policyFS fs.FS
 	policyFS, policyPaths, err = misconf.CreatePolicyFS(append(policyPaths, option.RegoOptions.CheckPaths...))
 	if err != nil {
-		return nil, false, xerrors.Errorf("unable to create policyfs: %w", err)
+		return nil, xerrors.Errorf("unable to create policyfs: %w", err)
 	}
 
 	scannerOpts = append(scannerOpts,
@@ -116,41 +361,12 @@ func (s *AWSScanner) Scan(ctx context.Context, option flag.Options) (scan.Result
 		)
 	}
 
-	scanner := New(scannerOpts...)
-
-	var freshState *state.State
-	if len(missing) > 0 || option.CloudOptions.UpdateCache {
-		var err error
-		freshState, err = scanner.CreateState(ctx)
-		if err != nil {
-			return nil, false, err
-		}
-	}
-
-	fullState, err := createState(freshState, awsCache)
-	if err != nil {
-		return nil, false, err
-	}
-
-	if fullState == nil {
-		return nil, false, fmt.Errorf("no resultant state found")
-	}
-
-	if err := awsCache.AddServices(fullState, missing); err != nil {
-		return nil, false, err
-	}
-
-	defsecResults, err := scanner.Scan(ctx, fullState)
-	if err != nil {
-		return nil, false, err
-	}
-
-	return defsecResults, len(included) > 0, nil
+	return scannerOpts, nil
 }
 
-func createState(freshState *state.State, awsCache *cache.Cache) (*state.State, error) {
+func createState(ctx context.Context, freshState *state.State, awsCache *cache.Cache) (*state.State, error) {
 	var fullState *state.State
-	if previousState, err := awsCache.LoadState(); err == nil {
+	if previousState, err := awsCache.LoadState(ctx); err == nil {
 		if freshState != nil {
 			fullState, err = previousState.Merge(freshState)
 			if err != nil {
@@ -165,6 +381,15 @@ func createState(freshState *state.State, awsCache *cache.Cache) (*state.State,
 	return fullState, nil
 }
 
+func updateResources(ctx context.Context, freshState *state.State, awsCache *cache.Cache, arns, notFound []string) *state.State {
+	previousState, err := awsCache.LoadState(ctx)
+	if err != nil {
+		return freshState
+	}
+	replaceResources(previousState, freshState, arns, notFound)
+	return previousState
+}
+
 func addPolicyNamespaces(namespaces []string, scannerOpts []options.ScannerOption) []options.ScannerOption {
 	if len(namespaces) > 0 {
 		scannerOpts = append(
@@ -1,6 +1,12 @@
 package scanner
 
 import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	cloudOptions "github.com/aquasecurity/trivy-aws/internal/adapters/cloud/options"
+	"github.com/aquasecurity/trivy-aws/pkg/audit"
 	"github.com/aquasecurity/trivy-aws/pkg/concurrency"
 	"github.com/aquasecurity/trivy-aws/pkg/progress"
 	"github.com/aquasecurity/trivy/pkg/iac/scanners/options"
@@ -12,7 +18,15 @@ type ConfigurableAWSScanner interface {
 	SetAWSRegion(region string)
 	SetAWSEndpoint(endpoint string)
 	SetAWSServices(services []string)
+	SetAWSARNs(arns []string)
 	SetConcurrencyStrategy(strategy concurrency.Strategy)
+	SetAWSCredentials(credentials aws.CredentialsProvider)
+	SetServiceParallelism(parallelism int)
+	SetServiceStrategies(strategies map[string]concurrency.Strategy)
+	SetAPIRateLimiter(limiter *cloudOptions.APIRateLimiter)
+	SetAuditLog(log *audit.Log)
+	SetServiceTimeout(timeout time.Duration)
+	SetResourceTimeout(timeout time.Duration)
 }
 
 func ScannerWithProgressTracker(t progress.Tracker) options.ScannerOption {
@@ -47,6 +61,14 @@ func ScannerWithAWSServices(services ...string) options.ScannerOption {
 	}
 }
 
+func ScannerWithAWSARNs(arns ...string) options.ScannerOption {
+	return func(s options.ConfigurableScanner) {
+		if aws, ok := s.(ConfigurableAWSScanner); ok {
+			aws.SetAWSARNs(arns)
+		}
+	}
+}
+
 func ScannerWithConcurrencyStrategy(strategy concurrency.Strategy) options.ScannerOption {
 	return func(s options.ConfigurableScanner) {
 		if aws, ok := s.(ConfigurableAWSScanner); ok {
@@ -54,3 +76,59 @@ func ScannerWithConcurrencyStrategy(strategy concurrency.Strategy) options.Scann
 		}
 	}
 }
+
+func ScannerWithAWSCredentials(credentials aws.CredentialsProvider) options.ScannerOption {
+	return func(s options.ConfigurableScanner) {
+		if aws, ok := s.(ConfigurableAWSScanner); ok {
+			aws.SetAWSCredentials(credentials)
+		}
+	}
+}
+
+func ScannerWithServiceParallelism(parallelism int) options.ScannerOption {
+	return func(s options.ConfigurableScanner) {
+		if aws, ok := s.(ConfigurableAWSScanner); ok {
+			aws.SetServiceParallelism(parallelism)
+		}
+	}
+}
+
+func ScannerWithServiceStrategies(strategies map[string]concurrency.Strategy) options.ScannerOption {
+	return func(s options.ConfigurableScanner) {
+		if aws, ok := s.(ConfigurableAWSScanner); ok {
+			aws.SetServiceStrategies(strategies)
+		}
+	}
+}
+
+func ScannerWithAPIRateLimiter(limiter *cloudOptions.APIRateLimiter) options.ScannerOption {
+	return func(s options.ConfigurableScanner) {
+		if aws, ok := s.(ConfigurableAWSScanner); ok {
+			aws.SetAPIRateLimiter(limiter)
+		}
+	}
+}
+
+func ScannerWithAuditLog(log *audit.Log) options.ScannerOption {
+	return func(s options.ConfigurableScanner) {
+		if aws, ok := s.(ConfigurableAWSScanner); ok {
+			aws.SetAuditLog(log)
+		}
+	}
+}
+
+func ScannerWithServiceTimeout(timeout time.Duration) options.ScannerOption {
+	return func(s options.ConfigurableScanner) {
+		if aws, ok := s.(ConfigurableAWSScanner); ok {
+			aws.SetServiceTimeout(timeout)
+		}
+	}
+}
+
+func ScannerWithResourceTimeout(timeout time.Duration) options.ScannerOption {
+	return func(s options.ConfigurableScanner) {
+		if aws, ok := s.(ConfigurableAWSScanner); ok {
+			aws.SetResourceTimeout(timeout)
+		}
+	}
+}
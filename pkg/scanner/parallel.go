@@ -0,0 +1,29 @@
+package scanner
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy-aws/pkg/concurrency"
+)
+
+// ParseParallelLimits parses per-service limits in the form of service=N into the concurrency strategy of each service
+func ParseParallelLimits(limits []string) (map[string]concurrency.Strategy, error) {
+	strategies := make(map[string]concurrency.Strategy)
+	for _, limit := range limits {
+		service, value, _ := strings.Cut(limit, "=")
+		processes, err := strconv.Atoi(value)
+		if service == "" || err != nil || processes < 1 {
+			return nil, xerrors.Errorf("invalid parallel limit %q, expected service=N with N at least 1", limit)
+		}
+		if supported := AllSupportedServices(); !slices.Contains(supported, service) {
+			return nil, xerrors.Errorf("invalid parallel limit %q, service '%s' is not currently supported - supported services are: %s",
+				limit, service, strings.Join(supported, ", "))
+		}
+		strategies[service] = concurrency.FixedStrategy(processes)
+	}
+	return strategies, nil
+}
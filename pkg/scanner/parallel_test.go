@@ -0,0 +1,27 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/trivy-aws/pkg/concurrency"
+)
+
+func Test_ParseParallelLimits(t *testing.T) {
+	strategies, err := ParseParallelLimits([]string{"iam=2", "ec2=5"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]concurrency.Strategy{
+		"iam": concurrency.FixedStrategy(2),
+		"ec2": concurrency.FixedStrategy(5),
+	}, strategies)
+
+	for _, limit := range []string{"iam", "iam=0", "=2", "iam=many", "ec3=2"} {
+		_, err = ParseParallelLimits([]string{limit})
+		require.ErrorContains(t, err, "invalid parallel limit", limit)
+	}
+
+	_, err = ParseParallelLimits([]string{"ec3=2"})
+	require.ErrorContains(t, err, "service 'ec3' is not currently supported")
+}
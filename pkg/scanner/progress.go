@@ -4,16 +4,46 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
 
 	"github.com/aquasecurity/loading/pkg/bar"
+	"github.com/aquasecurity/trivy-aws/pkg/progress"
 )
 
+// statusInterval is how often the status is printed when the bar cannot be rendered
+const statusInterval = 30 * time.Second
+
+// progressTracker renders a bar when stdout is a terminal. Otherwise, such as in CI logs, it prints
+// a plain-text status line periodically instead.
 type progressTracker struct {
-	serviceBar     *bar.Bar
-	serviceTotal   int
-	serviceCurrent int
-	isTTY          bool
-	debugWriter    io.Writer
+	mu              sync.Mutex
+	serviceBar      *bar.Bar
+	serviceTotal    int
+	serviceCurrent  int
+	serviceFinished int
+	inProgress      []*serviceProgress
+	isTTY           bool
+	debugWriter     io.Writer
+	statusWriter    io.Writer
+	statusInterval  time.Duration
+	stopStatus      chan struct{}
+	stopOnce        sync.Once
+}
+
+// serviceProgress tracks the resources of a single service, several of which
+// can be in progress at the same time and share the same bar.
+type serviceProgress struct {
+	parent  *progressTracker
+	name    string
+	label   string
+	total   int
+	current int
+	errors  int
 }
 
 func newProgressTracker(w io.Writer) *progressTracker {
@@ -21,63 +51,186 @@ func newProgressTracker(w io.Writer) *progressTracker {
 	if stat, err := os.Stdout.Stat(); err == nil {
 		isTTY = stat.Mode()&os.ModeCharDevice == os.ModeCharDevice
 	}
-	return &progressTracker{
-		isTTY:       isTTY,
-		debugWriter: w,
+	m := &progressTracker{
+		isTTY:          isTTY,
+		debugWriter:    w,
+		statusWriter:   os.Stderr,
+		statusInterval: statusInterval,
+		stopStatus:     make(chan struct{}),
 	}
+	if !isTTY {
+		go m.printStatusPeriodically()
+	}
+	return m
 }
 
 func (m *progressTracker) Finish() {
+	m.stopOnce.Do(func() {
+		close(m.stopStatus)
+	})
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if !m.isTTY || m.serviceBar == nil {
 		return
 	}
 	m.serviceBar.Finish()
 }
 
-func (m *progressTracker) IncrementResource() {
-	if !m.isTTY {
-		return
+func (m *progressTracker) printStatusPeriodically() {
+	ticker := time.NewTicker(m.statusInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			m.printStatus()
+		case <-m.stopStatus:
+			return
+		}
 	}
-	m.serviceBar.Increment()
 }
 
-func (m *progressTracker) SetTotalResources(i int) {
-	if !m.isTTY {
+// printStatus prints how far the scan has got, such as
+// "Status: 3/20 services finished, in progress: ec2 Instances 12/40 (1 error)"
+func (m *progressTracker) printStatus() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.inProgress) == 0 {
 		return
 	}
-	m.serviceBar.SetTotal(i)
+
+	services := make([]string, 0, len(m.inProgress))
+	for _, s := range m.inProgress {
+		status := fmt.Sprintf("%s %s %d/%d", s.name, s.label, s.current, s.total)
+		if s.errors == 1 {
+			status += " (1 error)"
+		} else if s.errors > 1 {
+			status += fmt.Sprintf(" (%d errors)", s.errors)
+		}
+		services = append(services, status)
+	}
+	_, _ = fmt.Fprintf(m.statusWriter, "Status: %d/%d services finished, in progress: %s\n",
+		m.serviceFinished, m.serviceTotal, strings.Join(services, ", "))
 }
 
 func (m *progressTracker) SetTotalServices(i int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.serviceTotal = i
 }
 
-func (m *progressTracker) SetServiceLabel(label string) {
+func (m *progressTracker) StartService(name string) progress.ServiceTracker {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.serviceCurrent++
+
+	service := &serviceProgress{
+		parent: m,
+		name:   name,
+		label:  "Initializing...",
+	}
+	m.inProgress = append(m.inProgress, service)
+
 	if !m.isTTY {
-		return
+		_, _ = fmt.Fprintf(m.statusWriter, "[%d/%d] Scanning %s...\n", m.serviceCurrent, m.serviceTotal, name)
+		return service
 	}
-	m.serviceBar.SetLabel("└╴" + label)
-	m.serviceBar.SetCurrent(0)
+
+	// the bar is drawn on the last line, so it has to be cleared before writing above it
+	if m.serviceBar != nil {
+		m.serviceBar.Finish()
+	}
+	_, _ = fmt.Fprintf(m.debugWriter, "[%d/%d] Scanning %s...\n", m.serviceCurrent, m.serviceTotal, name)
+	m.serviceBar = bar.New(
+		bar.OptionHideOnFinish(true),
+		bar.OptionWithAutoComplete(false),
+		bar.OptionWithRenderFunc(bar.RenderColoured(0xff, 0x66, 0x00)),
+	)
+	m.render()
+	return service
 }
 
-func (m *progressTracker) FinishService() {
+func (m *progressTracker) FinishService(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.serviceFinished++
+	m.inProgress = slices.DeleteFunc(m.inProgress, func(s *serviceProgress) bool {
+		return s.name == name
+	})
 	if !m.isTTY {
 		return
 	}
-	m.serviceCurrent++
-	m.serviceBar.Finish()
+	if len(m.inProgress) == 0 {
+		m.serviceBar.Finish()
+		return
+	}
+	m.render()
 }
 
-func (m *progressTracker) StartService(name string) {
-	if !m.isTTY {
+// render combines the progress of all services in progress into the bar, must be called with the lock held
+func (m *progressTracker) render() {
+	if m.serviceBar == nil || len(m.inProgress) == 0 {
 		return
 	}
 
-	fmt.Fprintf(m.debugWriter, "[%d/%d] Scanning %s...\n", m.serviceCurrent+1, m.serviceTotal, name)
-	m.serviceBar = bar.New(
-		bar.OptionHideOnFinish(true),
-		bar.OptionWithAutoComplete(false),
-		bar.OptionWithRenderFunc(bar.RenderColoured(0xff, 0x66, 0x00)),
-	)
-	m.SetServiceLabel("Initializing...")
+	var total, current int
+	labels := make([]string, 0, len(m.inProgress))
+	for _, s := range m.inProgress {
+		total += s.total
+		current += s.current
+		labels = append(labels, s.name+": "+s.label)
+	}
+
+	label := m.inProgress[0].label
+	if len(m.inProgress) > 1 {
+		label = strings.Join(labels, ", ")
+	}
+
+	m.serviceBar.SetLabel("└╴" + label)
+	m.serviceBar.SetTotal(total)
+	m.serviceBar.SetCurrent(current)
+}
+
+func (s *serviceProgress) SetServiceLabel(label string) {
+	s.parent.mu.Lock()
+	defer s.parent.mu.Unlock()
+	s.label = label
+	s.current = 0
+	s.parent.render()
+}
+
+func (s *serviceProgress) SetTotalResources(i int) {
+	s.parent.mu.Lock()
+	defer s.parent.mu.Unlock()
+	s.total = i
+	s.parent.render()
+}
+
+func (s *serviceProgress) IncrementResource() {
+	s.parent.mu.Lock()
+	defer s.parent.mu.Unlock()
+	s.current++
+	s.parent.render()
+}
+
+func (s *serviceProgress) ReportError(_ string, _ error) {
+	s.parent.mu.Lock()
+	defer s.parent.mu.Unlock()
+	s.errors++
+}
+
+// openProgressEvents opens the destination of the progress events, which is either stderr or a file
+// which is appended to, as several regions or accounts may be scanned in one run
+func openProgressEvents(dest string) (io.Writer, func(), error) {
+	if dest == "stderr" {
+		return os.Stderr, func() {}, nil
+	}
+	f, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, nil, xerrors.Errorf("failed to open progress events file: %w", err)
+	}
+	return f, func() { _ = f.Close() }, nil
 }
@@ -0,0 +1,35 @@
+package scanner
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_progressTrackerStatus(t *testing.T) {
+	var buf bytes.Buffer
+	tracker := &progressTracker{
+		statusWriter:   &buf,
+		statusInterval: time.Hour,
+		stopStatus:     make(chan struct{}),
+	}
+	defer tracker.Finish()
+
+	tracker.SetTotalServices(3)
+	s3 := tracker.StartService("s3")
+	s3.SetServiceLabel("Scanning buckets...")
+	s3.SetTotalResources(4)
+	s3.IncrementResource()
+	s3.ReportError("examplebucket", errors.New("access denied"))
+	tracker.StartService("iam")
+	tracker.FinishService("iam")
+	tracker.printStatus()
+
+	assert.Equal(t, `[1/3] Scanning s3...
+[2/3] Scanning iam...
+Status: 1/3 services finished, in progress: s3 Scanning buckets... 1/4 (1 error)
+`, buf.String())
+}
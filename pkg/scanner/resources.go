@@ -0,0 +1,100 @@
+package scanner
+
+import (
+	"reflect"
+	"slices"
+
+	"github.com/aquasecurity/trivy/pkg/iac/state"
+	iacTypes "github.com/aquasecurity/trivy/pkg/iac/types"
+)
+
+var metadataType = reflect.TypeOf(iacTypes.Metadata{})
+
+// replaceResources replaces the resources with the given ARNs in the target state with their fresh
+// counterparts, leaving every other resource untouched. Requested resources which are missing from
+// the fresh state are only removed if they were confirmed not to exist, as they may as well have failed
+// to be fetched, e.g. because the call was throttled or denied.
+func replaceResources(target, fresh *state.State, arns, notFound []string) {
+	if fresh == nil {
+		fresh = &state.State{}
+	}
+
+	targetVal := reflect.ValueOf(&target.AWS).Elem()
+	freshVal := reflect.ValueOf(&fresh.AWS).Elem()
+
+	for i := 0; i < targetVal.NumField(); i++ {
+		targetService := targetVal.Field(i)
+		if targetService.Kind() != reflect.Struct || !targetService.CanSet() {
+			continue
+		}
+		freshService := freshVal.Field(i)
+		for j := 0; j < targetService.NumField(); j++ {
+			field := targetService.Field(j)
+			if !isResourceSlice(field) || !field.CanSet() {
+				continue
+			}
+			field.Set(replaceInSlice(field, freshService.Field(j), arns, notFound))
+		}
+	}
+}
+
+// filterResources removes the resources from the target state for which keep returns false
+func filterResources(target *state.State, keep func(arn string) bool) {
+	targetVal := reflect.ValueOf(&target.AWS).Elem()
+
+	for i := 0; i < targetVal.NumField(); i++ {
+		service := targetVal.Field(i)
+		if service.Kind() != reflect.Struct || !service.CanSet() {
+			continue
+		}
+		for j := 0; j < service.NumField(); j++ {
+			field := service.Field(j)
+			if !isResourceSlice(field) || !field.CanSet() {
+				continue
+			}
+			result := reflect.MakeSlice(field.Type(), 0, field.Len())
+			for k := 0; k < field.Len(); k++ {
+				if keep(resourceARN(field.Index(k))) {
+					result = reflect.Append(result, field.Index(k))
+				}
+			}
+			field.Set(result)
+		}
+	}
+}
+
+func isResourceSlice(v reflect.Value) bool {
+	if v.Kind() != reflect.Slice || v.Type().Elem().Kind() != reflect.Struct {
+		return false
+	}
+	metadata, ok := v.Type().Elem().FieldByName("Metadata")
+	return ok && metadata.Type == metadataType
+}
+
+func replaceInSlice(target, fresh reflect.Value, arns, notFound []string) reflect.Value {
+	refreshed := make(map[string]bool)
+	for i := 0; i < fresh.Len(); i++ {
+		if arn := resourceARN(fresh.Index(i)); slices.Contains(arns, arn) {
+			refreshed[arn] = true
+		}
+	}
+
+	result := reflect.MakeSlice(target.Type(), 0, target.Len())
+	for i := 0; i < target.Len(); i++ {
+		arn := resourceARN(target.Index(i))
+		if !refreshed[arn] && !slices.Contains(notFound, arn) {
+			result = reflect.Append(result, target.Index(i))
+		}
+	}
+	for i := 0; i < fresh.Len(); i++ {
+		if refreshed[resourceARN(fresh.Index(i))] {
+			result = reflect.Append(result, fresh.Index(i))
+		}
+	}
+	return result
+}
+
+func resourceARN(resource reflect.Value) string {
+	metadata := resource.FieldByName("Metadata").Interface().(iacTypes.Metadata)
+	return metadata.Reference()
+}
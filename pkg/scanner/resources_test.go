@@ -0,0 +1,75 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aquasecurity/trivy/pkg/iac/providers/aws/s3"
+	"github.com/aquasecurity/trivy/pkg/iac/providers/aws/sqs"
+	"github.com/aquasecurity/trivy/pkg/iac/state"
+	iacTypes "github.com/aquasecurity/trivy/pkg/iac/types"
+)
+
+func Test_replaceResources(t *testing.T) {
+	bucket := func(name string, versioning bool) s3.Bucket {
+		metadata := iacTypes.NewRemoteMetadata("arn:aws:s3:::" + name)
+		return s3.Bucket{
+			Metadata: metadata,
+			Name:     iacTypes.String(name, metadata),
+			Versioning: s3.Versioning{
+				Metadata: metadata,
+				Enabled:  iacTypes.Bool(versioning, metadata),
+			},
+		}
+	}
+
+	queueMetadata := iacTypes.NewRemoteMetadata("arn:aws:sqs:us-east-1:123456789012:queue")
+
+	var cached state.State
+	cached.AWS.S3.Buckets = []s3.Bucket{
+		bucket("untouched", false),
+		bucket("updated", false),
+		bucket("deleted", false),
+		bucket("failed", false),
+	}
+	cached.AWS.SQS.Queues = []sqs.Queue{{Metadata: queueMetadata}}
+
+	var fresh state.State
+	fresh.AWS.S3.Buckets = []s3.Bucket{
+		bucket("updated", true),
+		bucket("not-requested", true),
+	}
+
+	// the failed bucket could not be fetched, so only the deleted one is confirmed not to exist
+	replaceResources(&cached, &fresh, []string{
+		"arn:aws:s3:::updated",
+		"arn:aws:s3:::deleted",
+		"arn:aws:s3:::failed",
+	}, []string{"arn:aws:s3:::deleted"})
+
+	var names []string
+	for _, b := range cached.AWS.S3.Buckets {
+		names = append(names, b.Name.Value())
+	}
+	assert.Equal(t, []string{"untouched", "failed", "updated"}, names)
+	assert.False(t, cached.AWS.S3.Buckets[0].Versioning.Enabled.IsTrue())
+	assert.False(t, cached.AWS.S3.Buckets[1].Versioning.Enabled.IsTrue())
+	assert.True(t, cached.AWS.S3.Buckets[2].Versioning.Enabled.IsTrue())
+	assert.Len(t, cached.AWS.SQS.Queues, 1)
+}
+
+func Test_replaceResourcesFetchFailed(t *testing.T) {
+	queueMetadata := iacTypes.NewRemoteMetadata("arn:aws:sqs:us-east-1:123456789012:queue")
+
+	var cached state.State
+	cached.AWS.SQS.Queues = []sqs.Queue{{Metadata: queueMetadata}}
+
+	// nothing was fetched and nothing was confirmed not to exist, e.g. as the calls were throttled
+	replaceResources(&cached, nil, []string{"arn:aws:sqs:us-east-1:123456789012:queue"}, nil)
+	assert.Len(t, cached.AWS.SQS.Queues, 1)
+
+	replaceResources(&cached, nil, []string{"arn:aws:sqs:us-east-1:123456789012:queue"},
+		[]string{"arn:aws:sqs:us-east-1:123456789012:queue"})
+	assert.Empty(t, cached.AWS.SQS.Queues)
+}
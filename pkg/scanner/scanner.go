@@ -7,13 +7,19 @@ import (
 	"os"
 	"runtime"
 	"sync"
+	"time"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
 
 	adapter "github.com/aquasecurity/trivy-aws/internal/adapters/cloud"
 	"github.com/aquasecurity/trivy-aws/internal/adapters/cloud/aws"
 	"github.com/aquasecurity/trivy-aws/internal/adapters/cloud/options"
+	"github.com/aquasecurity/trivy-aws/pkg/audit"
 	"github.com/aquasecurity/trivy-aws/pkg/concurrency"
 	"github.com/aquasecurity/trivy-aws/pkg/errs"
 	"github.com/aquasecurity/trivy-aws/pkg/progress"
+	"github.com/aquasecurity/trivy-aws/pkg/stats"
+	"github.com/aquasecurity/trivy-aws/pkg/tracing"
 	"github.com/aquasecurity/trivy/pkg/iac/framework"
 	"github.com/aquasecurity/trivy/pkg/iac/rego"
 	"github.com/aquasecurity/trivy/pkg/iac/rules"
@@ -36,10 +42,21 @@ type Scanner struct {
 	region              string
 	endpoint            string
 	services            []string
+	arns                []string
 	frameworks          []framework.Framework
 	spec                string
 	concurrencyStrategy concurrency.Strategy
+	serviceStrategies   map[string]concurrency.Strategy
+	apiRateLimiter      *options.APIRateLimiter
+	auditLog            *audit.Log
+	credentials         awssdk.CredentialsProvider
+	serviceParallelism  int
+	serviceTimeout      time.Duration
+	resourceTimeout     time.Duration
 	regoOnly            bool
+	scanErrors          []errs.ScanError
+	notFound            []string
+	stats               []stats.Service
 }
 
 func (s *Scanner) SetIncludeDeprecatedChecks(bool) {}
@@ -68,6 +85,31 @@ func AllSupportedServices() []string {
 	return aws.AllServices()
 }
 
+// ResourceServices returns the supported services which can scan individual resources identified by their ARNs
+func ResourceServices() []string {
+	return aws.ResourceServices()
+}
+
+// GlobalServices returns the supported services which are not bound to a region
+func GlobalServices() []string {
+	return aws.GlobalServices()
+}
+
+// RequiredPermissions returns the IAM actions required to scan the given services
+func RequiredPermissions(services []string) []string {
+	return aws.Permissions(services)
+}
+
+// FeaturePermissions returns the IAM actions required by the given features of the commands, which call
+// AWS on top of the scanned services
+func FeaturePermissions(features []string) ([]string, error) {
+	var awsFeatures []aws.Feature
+	for _, feature := range features {
+		awsFeatures = append(awsFeatures, aws.Feature(feature))
+	}
+	return aws.FeaturePermissions(awsFeatures)
+}
+
 func (s *Scanner) SetAWSRegion(region string) {
 	s.region = region
 }
@@ -80,10 +122,42 @@ func (s *Scanner) SetAWSServices(services []string) {
 	s.services = services
 }
 
+func (s *Scanner) SetAWSARNs(arns []string) {
+	s.arns = arns
+}
+
 func (s *Scanner) SetConcurrencyStrategy(strategy concurrency.Strategy) {
 	s.concurrencyStrategy = strategy
 }
 
+func (s *Scanner) SetAWSCredentials(credentials awssdk.CredentialsProvider) {
+	s.credentials = credentials
+}
+
+func (s *Scanner) SetServiceParallelism(parallelism int) {
+	s.serviceParallelism = parallelism
+}
+
+func (s *Scanner) SetServiceStrategies(strategies map[string]concurrency.Strategy) {
+	s.serviceStrategies = strategies
+}
+
+func (s *Scanner) SetAPIRateLimiter(limiter *options.APIRateLimiter) {
+	s.apiRateLimiter = limiter
+}
+
+func (s *Scanner) SetAuditLog(log *audit.Log) {
+	s.auditLog = log
+}
+
+func (s *Scanner) SetServiceTimeout(timeout time.Duration) {
+	s.serviceTimeout = timeout
+}
+
+func (s *Scanner) SetResourceTimeout(timeout time.Duration) {
+	s.resourceTimeout = timeout
+}
+
 func New(opts ...iacOptions.ScannerOption) *Scanner {
 
 	s := &Scanner{
@@ -99,14 +173,50 @@ func New(opts ...iacOptions.ScannerOption) *Scanner {
 	return s
 }
 
+// ScanErrors returns the errors which left resources not fully checked while creating the state
+func (s *Scanner) ScanErrors() []errs.ScanError {
+	return s.scanErrors
+}
+
+// NotFound returns the requested ARNs of the resources which were confirmed not to exist while creating
+// the state
+func (s *Scanner) NotFound() []string {
+	return s.notFound
+}
+
+// Stats returns the statistics of the services adapted while creating the state
+func (s *Scanner) Stats() []stats.Service {
+	return s.stats
+}
+
 func (s *Scanner) CreateState(ctx context.Context) (*state.State, error) {
+	scanErrors := errs.NewCollector()
+	notFound := options.NewResourceSet()
+	serviceStats := stats.NewCollector(s.progressTracker, s.region)
 	cloudState, err := adapter.Adapt(ctx, options.Options{
-		ProgressTracker:     s.progressTracker,
+		ProgressTracker:     serviceStats,
 		Region:              s.region,
 		Endpoint:            s.endpoint,
 		Services:            s.services,
+		ARNs:                s.arns,
 		ConcurrencyStrategy: s.concurrencyStrategy,
+		ServiceStrategies:   s.serviceStrategies,
+		APIRateLimiter:      s.apiRateLimiter,
+		AuditLog:            s.auditLog,
+		Credentials:         s.credentials,
+		ServiceParallelism:  s.serviceParallelism,
+		ServiceTimeout:      s.serviceTimeout,
+		ResourceTimeout:     s.resourceTimeout,
+		Errors:              scanErrors,
+		NotFound:            notFound,
+		Stats:               serviceStats,
 	})
+	s.scanErrors = scanErrors.List()
+	s.notFound = notFound.List()
+	s.stats = serviceStats.List()
+	if len(s.scanErrors) > 0 {
+		s.logger.Warn("Some resources could not be fully checked, see the scan gaps of the report", log.Int("errors", len(s.scanErrors)))
+	}
 	if err != nil {
 		var adaptionError errs.AdapterError
 		if errors.As(err, &adaptionError) {
@@ -118,6 +228,28 @@ func (s *Scanner) CreateState(ctx context.Context) (*state.State, error) {
 	return cloudState, nil
 }
 
+// ResourceTags returns the tags of the resources in the scanned region, and of the scanned global services, keyed by ARN
+func (s *Scanner) ResourceTags(ctx context.Context) (map[string]map[string]string, error) {
+	return adapter.ResourceTags(ctx, options.Options{
+		Region:      s.region,
+		Endpoint:    s.endpoint,
+		Services:    s.services,
+		Credentials: s.credentials,
+		AuditLog:    s.auditLog,
+	})
+}
+
+// CheckPermissions evaluates the IAM actions required to scan the services against the policies of the caller
+func (s *Scanner) CheckPermissions(ctx context.Context) ([]aws.PermissionCheck, error) {
+	return adapter.CheckPermissions(ctx, options.Options{
+		Region:      s.region,
+		Endpoint:    s.endpoint,
+		Services:    s.services,
+		Credentials: s.credentials,
+		AuditLog:    s.auditLog,
+	})
+}
+
 func (s *Scanner) ScanWithStateRefresh(ctx context.Context) (results scan.Results, err error) {
 	cloudState, err := s.CreateState(ctx)
 	if err != nil {
@@ -127,6 +259,8 @@ func (s *Scanner) ScanWithStateRefresh(ctx context.Context) (results scan.Result
 }
 
 func (s *Scanner) Scan(ctx context.Context, cloudState *state.State) (results scan.Results, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "evaluate checks")
+	defer func() { tracing.End(span, err) }()
 
 	if cloudState == nil {
 		return nil, fmt.Errorf("cloud state is nil")
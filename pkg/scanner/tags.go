@@ -0,0 +1,103 @@
+package scanner
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/iac/state"
+)
+
+// untaggedServices are the services the resources of which are not covered by the Resource Groups
+// Tagging API, so their tags are unknown. The tags of other global services, such as CloudFront, are
+// listed from the region serving them.
+var untaggedServices = []string{"iam"}
+
+// TagFilter matches resources carrying the tag key with the given value,
+// or with any value if AnyValue is set
+type TagFilter struct {
+	Key      string
+	Value    string
+	AnyValue bool
+}
+
+// ParseTagFilters parses tag filters in the form of key=value, or key to match any value of the tag.
+// key= matches the tag with an empty value.
+func ParseTagFilters(filters []string) ([]TagFilter, error) {
+	var parsed []TagFilter
+	for _, filter := range filters {
+		key, value, found := strings.Cut(filter, "=")
+		if key == "" {
+			return nil, xerrors.Errorf("invalid tag filter %q, expected key=value or key", filter)
+		}
+		parsed = append(parsed, TagFilter{
+			Key:      key,
+			Value:    value,
+			AnyValue: !found,
+		})
+	}
+	return parsed, nil
+}
+
+func (f TagFilter) Match(tags map[string]string) bool {
+	value, ok := tags[f.Key]
+	if !ok {
+		return false
+	}
+	return f.AnyValue || f.Value == value
+}
+
+func matchAnyTag(filters []TagFilter, tags map[string]string) bool {
+	for _, filter := range filters {
+		if filter.Match(tags) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterResourcesByTags keeps the resources matching any of the include filters, if there are any,
+// and removes the resources matching any of the exclude filters. Resources without tags never match.
+// It fails if the state has resources the tags of which cannot be known, rather than silently
+// dropping them or never excluding them.
+func filterResourcesByTags(s *state.State, tags map[string]map[string]string, include, exclude []TagFilter) error {
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil
+	}
+
+	var unmatched []string
+	filterResources(s, func(reference string) bool {
+		if service := untaggedService(reference); service != "" {
+			if !slices.Contains(unmatched, service) {
+				unmatched = append(unmatched, service)
+			}
+			return true
+		}
+		resourceTags := tags[reference]
+		if len(include) > 0 && !matchAnyTag(include, resourceTags) {
+			return false
+		}
+		return !matchAnyTag(exclude, resourceTags)
+	})
+	if len(unmatched) > 0 {
+		slices.Sort(unmatched)
+		return xerrors.Errorf("cannot filter resources by tags, as the tags of %s are not available from the Resource Groups Tagging API, skip the service with --skip-service",
+			strings.Join(unmatched, ", "))
+	}
+	return nil
+}
+
+// untaggedService returns the service of the resource if the reference cannot be matched against the
+// ARNs of the Resource Groups Tagging API, either because it is not an ARN or the service is not covered
+func untaggedService(reference string) string {
+	parsed, err := arn.Parse(reference)
+	if err != nil {
+		return reference
+	}
+	if slices.Contains(untaggedServices, parsed.Service) {
+		return parsed.Service
+	}
+	return ""
+}
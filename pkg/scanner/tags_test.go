@@ -0,0 +1,128 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/trivy/pkg/iac/providers/aws/cloudfront"
+	"github.com/aquasecurity/trivy/pkg/iac/providers/aws/iam"
+	"github.com/aquasecurity/trivy/pkg/iac/providers/aws/s3"
+	"github.com/aquasecurity/trivy/pkg/iac/state"
+	iacTypes "github.com/aquasecurity/trivy/pkg/iac/types"
+)
+
+func Test_ParseTagFilters(t *testing.T) {
+	filters, err := ParseTagFilters([]string{"team=payments", "owner", "empty="})
+	require.NoError(t, err)
+	assert.Equal(t, []TagFilter{
+		{Key: "team", Value: "payments"},
+		{Key: "owner", AnyValue: true},
+		{Key: "empty"},
+	}, filters)
+
+	_, err = ParseTagFilters([]string{"=payments"})
+	require.ErrorContains(t, err, "invalid tag filter")
+}
+
+func Test_filterResourcesByTags(t *testing.T) {
+	tags := map[string]map[string]string{
+		"arn:aws:s3:::payments":         {"team": "payments", "env": "prod"},
+		"arn:aws:s3:::payments-sandbox": {"team": "payments", "env": "sandbox"},
+		"arn:aws:s3:::search":           {"team": "search", "env": "prod"},
+		"arn:aws:s3:::untagged":         {"team": ""},
+	}
+
+	tests := []struct {
+		name    string
+		include []TagFilter
+		exclude []TagFilter
+		want    []string
+	}{
+		{
+			name: "no filters",
+			want: []string{"payments", "payments-sandbox", "search", "untagged"},
+		},
+		{
+			name:    "include",
+			include: []TagFilter{{Key: "team", Value: "payments"}},
+			want:    []string{"payments", "payments-sandbox"},
+		},
+		{
+			name:    "exclude",
+			exclude: []TagFilter{{Key: "env", Value: "sandbox"}},
+			want:    []string{"payments", "search", "untagged"},
+		},
+		{
+			name:    "include and exclude",
+			include: []TagFilter{{Key: "team", Value: "payments"}},
+			exclude: []TagFilter{{Key: "env", Value: "sandbox"}},
+			want:    []string{"payments"},
+		},
+		{
+			name:    "include any value",
+			include: []TagFilter{{Key: "team", AnyValue: true}},
+			want:    []string{"payments", "payments-sandbox", "search", "untagged"},
+		},
+		{
+			name:    "include empty value",
+			include: []TagFilter{{Key: "team"}},
+			want:    []string{"untagged"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s state.State
+			for _, name := range []string{"payments", "payments-sandbox", "search", "untagged"} {
+				metadata := iacTypes.NewRemoteMetadata("arn:aws:s3:::" + name)
+				s.AWS.S3.Buckets = append(s.AWS.S3.Buckets, s3.Bucket{
+					Metadata: metadata,
+					Name:     iacTypes.String(name, metadata),
+				})
+			}
+
+			require.NoError(t, filterResourcesByTags(&s, tags, tt.include, tt.exclude))
+
+			var names []string
+			for _, b := range s.AWS.S3.Buckets {
+				names = append(names, b.Name.Value())
+			}
+			assert.Equal(t, tt.want, names)
+		})
+	}
+}
+
+func Test_filterResourcesByTagsUntagged(t *testing.T) {
+	metadata := iacTypes.NewRemoteMetadata("arn:aws:iam::123456789012:role/deploy")
+	var s state.State
+	s.AWS.IAM.Roles = append(s.AWS.IAM.Roles, iam.Role{
+		Metadata: metadata,
+		Name:     iacTypes.String("deploy", metadata),
+	})
+
+	// the tags of IAM resources are not known, so they can neither be included nor excluded
+	err := filterResourcesByTags(&s, nil, []TagFilter{{Key: "team", Value: "payments"}}, nil)
+	require.ErrorContains(t, err, "tags of iam are not available")
+
+	require.NoError(t, filterResourcesByTags(&s, nil, nil, nil))
+}
+
+func Test_filterResourcesByTagsGlobalService(t *testing.T) {
+	tags := map[string]map[string]string{
+		"arn:aws:cloudfront::123456789012:distribution/E1": {"team": "payments"},
+	}
+
+	var s state.State
+	for _, id := range []string{"E1", "E2"} {
+		s.AWS.Cloudfront.Distributions = append(s.AWS.Cloudfront.Distributions, cloudfront.Distribution{
+			Metadata: iacTypes.NewRemoteMetadata("arn:aws:cloudfront::123456789012:distribution/" + id),
+		})
+	}
+
+	// the tags of global services are listed from the region serving them, so their resources can be filtered
+	require.NoError(t, filterResourcesByTags(&s, tags, []TagFilter{{Key: "team", Value: "payments"}}, nil))
+	require.Len(t, s.AWS.Cloudfront.Distributions, 1)
+	assert.Equal(t, "arn:aws:cloudfront::123456789012:distribution/E1", s.AWS.Cloudfront.Distributions[0].Metadata.Reference())
+}
@@ -0,0 +1,42 @@
+package statefile
+
+import (
+	"github.com/aquasecurity/trivy/pkg/iac/state"
+	iacTypes "github.com/aquasecurity/trivy/pkg/iac/types"
+)
+
+const redacted = "REDACTED"
+
+// Redact replaces sensitive values of the state in place, so that it can be shared safely.
+// The replaced values are ECS container environment variables and EC2 user data.
+func Redact(s *state.State) {
+	if s == nil {
+		return
+	}
+
+	for i := range s.AWS.ECS.TaskDefinitions {
+		definitions := s.AWS.ECS.TaskDefinitions[i].ContainerDefinitions
+		for j := range definitions {
+			for k := range definitions[j].Environment {
+				definitions[j].Environment[k].Value = redactString(definitions[j].Environment[k].Value)
+			}
+		}
+	}
+
+	for i := range s.AWS.EC2.Instances {
+		s.AWS.EC2.Instances[i].UserData = redactString(s.AWS.EC2.Instances[i].UserData)
+	}
+	for i := range s.AWS.EC2.LaunchConfigurations {
+		s.AWS.EC2.LaunchConfigurations[i].UserData = redactString(s.AWS.EC2.LaunchConfigurations[i].UserData)
+	}
+	for i := range s.AWS.EC2.LaunchTemplates {
+		s.AWS.EC2.LaunchTemplates[i].UserData = redactString(s.AWS.EC2.LaunchTemplates[i].UserData)
+	}
+}
+
+func redactString(v iacTypes.StringValue) iacTypes.StringValue {
+	if v.Value() == "" {
+		return v
+	}
+	return iacTypes.String(redacted, v.GetMetadata())
+}
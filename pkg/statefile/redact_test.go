@@ -0,0 +1,52 @@
+package statefile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aquasecurity/trivy/pkg/iac/providers/aws/ec2"
+	"github.com/aquasecurity/trivy/pkg/iac/providers/aws/ecs"
+	"github.com/aquasecurity/trivy/pkg/iac/state"
+	iacTypes "github.com/aquasecurity/trivy/pkg/iac/types"
+)
+
+func TestRedact(t *testing.T) {
+	metadata := iacTypes.NewTestMetadata()
+
+	var s state.State
+	s.AWS.ECS.TaskDefinitions = []ecs.TaskDefinition{
+		{
+			Metadata: metadata,
+			ContainerDefinitions: []ecs.ContainerDefinition{
+				{
+					Metadata: metadata,
+					Environment: []ecs.EnvVar{
+						{
+							Name:  iacTypes.String("DB_PASSWORD", metadata),
+							Value: iacTypes.String("hunter2", metadata),
+						},
+					},
+				},
+			},
+		},
+	}
+	s.AWS.EC2.Instances = []ec2.Instance{
+		{
+			Metadata: metadata,
+			UserData: iacTypes.String("export TOKEN=secret", metadata),
+		},
+		{
+			Metadata: metadata,
+			UserData: iacTypes.String("", metadata),
+		},
+	}
+
+	Redact(&s)
+
+	env := s.AWS.ECS.TaskDefinitions[0].ContainerDefinitions[0].Environment[0]
+	assert.Equal(t, "DB_PASSWORD", env.Name.Value())
+	assert.Equal(t, redacted, env.Value.Value())
+	assert.Equal(t, redacted, s.AWS.EC2.Instances[0].UserData.Value())
+	assert.Empty(t, s.AWS.EC2.Instances[1].UserData.Value())
+}
@@ -0,0 +1,119 @@
+package statefile
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy-aws/pkg/errs"
+	"github.com/aquasecurity/trivy/pkg/iac/state"
+)
+
+// File is a snapshot of the state of an AWS account and region, which can be scanned without access to AWS.
+// The account, region, tags, errors and times are optional, so a bare state can be loaded as well. A cache
+// directory cannot be loaded, as the cache is sharded into a metadata file and a file per service, although
+// the unencrypted file of a single service can be.
+type File struct {
+	AccountID  string                       `json:"account_id,omitempty"`
+	Region     string                       `json:"region,omitempty"`
+	Partition  string                       `json:"partition,omitempty"`
+	Tags       map[string]map[string]string `json:"tags,omitempty"`
+	ScanErrors []errs.ScanError             `json:"scan_errors,omitempty"`
+	// Updated holds the time each service was adapted from AWS, keyed by service
+	Updated map[string]time.Time `json:"updated,omitempty"`
+	State   *state.State         `json:"state"`
+}
+
+// sealedPrefix marks the files of an encrypted cache, which cannot be decoded without the key of the cache
+var sealedPrefix = []byte("trivy-aws-gcm-v1:")
+
+// cacheHeader holds the fields which tell the files of the cache apart from a state file
+type cacheHeader struct {
+	SchemaVersion   *int            `json:"schema_version"`
+	Name            string          `json:"name"`
+	ServiceMetadata json.RawMessage `json:"service_metadata"`
+}
+
+// Load reads a state file from the given path
+func Load(path string) (*File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, xerrors.Errorf("unable to open state file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	return Decode(f)
+}
+
+// Decode reads a state file, falling back to a bare state if the state is not wrapped. The file of a cached
+// service is read as a state file without the account and region, while the metadata of the cache and the
+// files of an encrypted cache are rejected.
+func Decode(r io.Reader) (*File, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, xerrors.Errorf("unable to read state file: %w", err)
+	}
+	if bytes.HasPrefix(data, sealedPrefix) {
+		return nil, xerrors.New("unable to decode state file: the file is from an encrypted cache")
+	}
+
+	var header cacheHeader
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, xerrors.Errorf("unable to decode state file: %w", err)
+	}
+	if header.SchemaVersion != nil {
+		if header.ServiceMetadata != nil || header.Name == "" {
+			return nil, xerrors.New("unable to decode state file: the file is the metadata of a cache, load the file of a cached service instead")
+		}
+		var service struct {
+			State *state.State `json:"state"`
+		}
+		if err := json.Unmarshal(data, &service); err != nil {
+			return nil, xerrors.Errorf("unable to decode cached service %q: %w", header.Name, err)
+		}
+		if service.State == nil {
+			return nil, xerrors.Errorf("unable to decode cached service %q: the file holds no state", header.Name)
+		}
+		return &File{State: service.State}, nil
+	}
+
+	var f File
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, xerrors.Errorf("unable to decode state file: %w", err)
+	}
+	if f.State != nil {
+		return &f, nil
+	}
+
+	var s state.State
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, xerrors.Errorf("unable to decode state file: %w", err)
+	}
+	f.State = &s
+	return &f, nil
+}
+
+// Write writes the state file as indented JSON
+func Write(w io.Writer, f *File) error {
+	return writeJSON(w, f)
+}
+
+// WriteRego writes the state in the shape of the input of the rego checks
+func WriteRego(w io.Writer, f *File) error {
+	if f.State == nil {
+		return xerrors.Errorf("no state to write")
+	}
+	return writeJSON(w, f.State.ToRego())
+}
+
+func writeJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return xerrors.Errorf("unable to encode state: %w", err)
+	}
+	return nil
+}
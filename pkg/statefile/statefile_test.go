@@ -0,0 +1,65 @@
+package statefile
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		wantAccountID string
+		wantErr       string
+	}{
+		{
+			name:          "wrapped state",
+			input:         `{"account_id":"123456789012","region":"us-east-1","state":{"AWS":{}}}`,
+			wantAccountID: "123456789012",
+		},
+		{
+			name:  "bare state",
+			input: `{"AWS":{}}`,
+		},
+		{
+			name:  "cached service",
+			input: `{"schema_version":5,"name":"s3","state":{"AWS":{}}}`,
+		},
+		{
+			name:    "cached service without state",
+			input:   `{"schema_version":5,"name":"s3"}`,
+			wantErr: `unable to decode cached service "s3": the file holds no state`,
+		},
+		{
+			name:    "cache metadata",
+			input:   `{"schema_version":5,"service_metadata":{"s3":{"name":"s3","digest":"abc"}},"updated":"2024-01-01T00:00:00Z"}`,
+			wantErr: "the file is the metadata of a cache",
+		},
+		{
+			name:    "encrypted cached service",
+			input:   "trivy-aws-gcm-v1:c2VhbGVk",
+			wantErr: "the file is from an encrypted cache",
+		},
+		{
+			name:    "invalid json",
+			input:   `{`,
+			wantErr: "unable to decode state file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := Decode(strings.NewReader(tt.input))
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, f.State)
+			assert.Equal(t, tt.wantAccountID, f.AccountID)
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package stats
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aquasecurity/trivy-aws/pkg/progress"
+)
+
+// Service holds the statistics of adapting a service in a region
+type Service struct {
+	Service string
+	Region  string `json:",omitempty"`
+	// Duration is the number of seconds the service took to adapt
+	Duration   float64
+	Discovered int `json:"ResourcesDiscovered"`
+	// Processed is the number of resources the adapter finished with, whether or not they were adapted
+	Processed int `json:"ResourcesProcessed"`
+	// Adapted is the number of resources adapted without error
+	Adapted int `json:"ResourcesAdapted"`
+	// Calls holds the number of API calls by operation, retries not included
+	Calls     map[string]int `json:"APICalls,omitempty"`
+	Retries   int
+	Throttles int
+}
+
+// TotalCalls returns the number of API calls of every operation
+func (s Service) TotalCalls() int {
+	var total int
+	for _, calls := range s.Calls {
+		total += calls
+	}
+	return total
+}
+
+// Collector collects the statistics of the adapted services. It wraps the progress tracker of the scan,
+// so that the resources are counted through the same hooks the adapters report their progress with.
+// It is safe for concurrent use.
+type Collector struct {
+	tracker progress.Tracker
+	region  string
+	now     func() time.Time
+
+	mu       sync.Mutex
+	services map[string]*Service
+	started  map[string]time.Time
+}
+
+func NewCollector(tracker progress.Tracker, region string) *Collector {
+	return &Collector{
+		tracker:  tracker,
+		region:   region,
+		now:      time.Now,
+		services: make(map[string]*Service),
+		started:  make(map[string]time.Time),
+	}
+}
+
+func (c *Collector) SetTotalServices(i int) {
+	c.tracker.SetTotalServices(i)
+}
+
+func (c *Collector) StartService(name string) progress.ServiceTracker {
+	c.mu.Lock()
+	c.service(name)
+	c.started[name] = c.now()
+	c.mu.Unlock()
+
+	return &serviceCollector{
+		ServiceTracker: c.tracker.StartService(name),
+		parent:         c,
+		name:           name,
+	}
+}
+
+func (c *Collector) FinishService(name string) {
+	c.mu.Lock()
+	c.service(name).Duration = c.now().Sub(c.started[name]).Seconds()
+	c.mu.Unlock()
+
+	c.tracker.FinishService(name)
+}
+
+// RecordCall records a call of the operation, which took the given attempts of which some were throttled
+func (c *Collector) RecordCall(service, operation string, attempts, throttles int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := c.service(service)
+	if s.Calls == nil {
+		s.Calls = make(map[string]int)
+	}
+	s.Calls[operation]++
+	s.Retries += max(attempts-1, 0)
+	s.Throttles += throttles
+}
+
+// List returns the statistics of every service sorted by name
+func (c *Collector) List() []Service {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var services []Service
+	for _, s := range c.services {
+		service := *s
+		service.Calls = maps.Clone(s.Calls)
+		services = append(services, service)
+	}
+	Sort(services)
+	return services
+}
+
+// service returns the statistics of the service, must be called with the lock held
+func (c *Collector) service(name string) *Service {
+	s, ok := c.services[name]
+	if !ok {
+		s = &Service{Service: name, Region: c.region}
+		c.services[name] = s
+	}
+	return s
+}
+
+// Sort sorts the statistics by service and region
+func Sort(services []Service) {
+	slices.SortFunc(services, func(a, b Service) int {
+		if a.Service != b.Service {
+			return strings.Compare(a.Service, b.Service)
+		}
+		return strings.Compare(a.Region, b.Region)
+	})
+}
+
+// serviceCollector counts the resources of a service. Adapters report the total of each kind of resource
+// under a label of its own, and may update it while paging through the resources, so the last total of
+// each label counts as discovered.
+type serviceCollector struct {
+	progress.ServiceTracker
+	parent *Collector
+	name   string
+
+	phaseTotal int
+}
+
+func (s *serviceCollector) SetServiceLabel(label string) {
+	s.parent.mu.Lock()
+	s.phaseTotal = 0
+	s.parent.mu.Unlock()
+
+	s.ServiceTracker.SetServiceLabel(label)
+}
+
+func (s *serviceCollector) SetTotalResources(i int) {
+	s.parent.mu.Lock()
+	s.parent.service(s.name).Discovered += i - s.phaseTotal
+	s.phaseTotal = i
+	s.parent.mu.Unlock()
+
+	s.ServiceTracker.SetTotalResources(i)
+}
+
+func (s *serviceCollector) IncrementResource() {
+	s.parent.mu.Lock()
+	s.parent.service(s.name).Processed++
+	s.parent.mu.Unlock()
+
+	s.ServiceTracker.IncrementResource()
+}
+
+func (s *serviceCollector) ResourceAdapted() {
+	s.parent.mu.Lock()
+	s.parent.service(s.name).Adapted++
+	s.parent.mu.Unlock()
+}
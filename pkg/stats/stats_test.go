@@ -0,0 +1,90 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aquasecurity/trivy-aws/pkg/concurrency"
+	"github.com/aquasecurity/trivy-aws/pkg/progress"
+)
+
+func Test_Collector(t *testing.T) {
+	collector := NewCollector(progress.NoProgress, "us-east-1")
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	collector.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+
+	tracker := collector.StartService("iam")
+	tracker.SetServiceLabel("Discovering users...")
+	// the total grows while paging through the users
+	tracker.SetTotalResources(2)
+	tracker.SetTotalResources(3)
+	tracker.SetServiceLabel("Adapting users...")
+	tracker.IncrementResource()
+	progress.ResourceAdapted(tracker)
+	// the second user failed to adapt
+	tracker.IncrementResource()
+	tracker.SetServiceLabel("Discovering roles...")
+	tracker.SetTotalResources(1)
+	tracker.IncrementResource()
+	progress.ResourceAdapted(tracker)
+
+	collector.RecordCall("iam", "ListUsers", 1, 0)
+	collector.RecordCall("iam", "ListUsers", 3, 2)
+	collector.RecordCall("iam", "ListRoles", 1, 0)
+	collector.FinishService("iam")
+
+	assert.Equal(t, []Service{
+		{
+			Service:    "iam",
+			Region:     "us-east-1",
+			Duration:   1,
+			Discovered: 4,
+			Processed:  3,
+			Adapted:    2,
+			Calls:      map[string]int{"ListUsers": 2, "ListRoles": 1},
+			Retries:    2,
+			Throttles:  2,
+		},
+	}, collector.List())
+	assert.Equal(t, 3, collector.List()[0].TotalCalls())
+}
+
+// adaptContext adapts the resources of a service with the tracker of the collector
+type adaptContext struct {
+	tracker progress.ServiceTracker
+}
+
+func (c *adaptContext) Context() context.Context { return context.Background() }
+func (c *adaptContext) ConcurrencyStrategy() concurrency.Strategy {
+	return concurrency.OneAtATimeStrategy
+}
+func (c *adaptContext) ResourceTimeout() time.Duration   { return 0 }
+func (c *adaptContext) Tracker() progress.ServiceTracker { return c.tracker }
+func (c *adaptContext) RecordError(_ string, _ error)    {}
+
+func Test_CollectorFailedResource(t *testing.T) {
+	collector := NewCollector(progress.NoProgress, "us-east-1")
+
+	tracker := collector.StartService("s3")
+	tracker.SetTotalResources(3)
+	concurrency.Adapt([]string{"a", "b", "c"}, &adaptContext{tracker: tracker}, func(_ context.Context, item string) (*string, error) {
+		if item == "b" {
+			return nil, errors.New("access denied")
+		}
+		return &item, nil
+	})
+	collector.FinishService("s3")
+
+	services := collector.List()
+	assert.Len(t, services, 1)
+	assert.Equal(t, 3, services[0].Discovered)
+	assert.Equal(t, 3, services[0].Processed)
+	assert.Equal(t, 2, services[0].Adapted)
+}
@@ -0,0 +1,93 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/xerrors"
+)
+
+const instrumentationName = "github.com/aquasecurity/trivy-aws"
+
+// Tracer returns the tracer of the plugin. Spans are only exported once Setup has been called with an
+// exporter, otherwise they are discarded.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Setup exports the spans via OTLP over HTTP to the endpoint, such as http://localhost:4318 for a local
+// collector, and/or as JSON to the file for offline analysis. Nothing is exported if neither is given.
+// The returned function flushes the remaining spans and must be called before exiting.
+func Setup(ctx context.Context, endpoint, file string) (func(context.Context) error, error) {
+	var opts []sdktrace.TracerProviderOption
+	var closers []func() error
+
+	if endpoint != "" {
+		exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpoint))
+		if err != nil {
+			return nil, xerrors.Errorf("failed to create OTLP trace exporter: %w", err)
+		}
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+
+	if file != "" {
+		f, err := os.Create(file)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to create trace file: %w", err)
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			_ = f.Close()
+			return nil, xerrors.Errorf("failed to create file trace exporter: %w", err)
+		}
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+		closers = append(closers, f.Close)
+	}
+
+	if len(opts) == 0 {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	opts = append(opts, sdktrace.WithResource(resource.NewSchemaless(
+		semconv.ServiceName("trivy-aws"),
+	)))
+	provider := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		for _, closer := range closers {
+			err = errors.Join(err, closer())
+		}
+		return err
+	}, nil
+}
+
+// End ends the span, recording the error if there is one
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Service returns the attribute of the AWS service a span relates to
+func Service(name string) attribute.KeyValue {
+	return attribute.String("aws.service", name)
+}
+
+// Region returns the attribute of the AWS region a span relates to
+func Region(name string) attribute.KeyValue {
+	return attribute.String("aws.region", name)
+}
@@ -0,0 +1,46 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+)
+
+func Test_SetupWithFile(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(previous)
+
+	file := filepath.Join(t.TempDir(), "traces.json")
+	shutdown, err := Setup(context.Background(), "", file)
+	require.NoError(t, err)
+
+	ctx, scan := Tracer().Start(context.Background(), "scan")
+	_, adapt := Tracer().Start(ctx, "adapt s3")
+	End(adapt, errors.New("access denied"))
+	End(scan, nil)
+
+	require.NoError(t, shutdown(context.Background()))
+
+	traces, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Contains(t, string(traces), `"Name":"scan"`)
+	assert.Contains(t, string(traces), `"Name":"adapt s3"`)
+	assert.Contains(t, string(traces), `"Description":"access denied"`)
+}
+
+func Test_SetupWithoutExporter(t *testing.T) {
+	previous := otel.GetTracerProvider()
+
+	shutdown, err := Setup(context.Background(), "", "")
+	require.NoError(t, err)
+	require.NoError(t, shutdown(context.Background()))
+
+	// the spans are discarded
+	assert.Equal(t, previous, otel.GetTracerProvider())
+}